@@ -27,6 +27,12 @@ const (
 	footnote6          = "[6] grub settings are mostly covered by other settings. See man page saptune-note(5) for details"
 	footnote7          = "[7] parameter value is untouched by default"
 	footnote8          = "[8] cannot set Perf Bias because SecureBoot is enabled"
+	footnote9          = "[9] setting will be active after the next reboot or module reload"
+	footnote10         = "[10] prerequisite of the SAP Note is only checked, it can NOT be changed by saptune"
+	footnote11         = "[11] systemd resource limits differ from the pam limits in /etc/security/limits.d"
+	footnote12         = "[12] limit is configured, but the running processes need a restart to use it"
+	footnote13         = "[13] mount point is not a separate file system"
+	footnote14         = "[14] mount point not found in /etc/fstab"
 )
 
 // PackageArea is the package area with all notes and solutions shiped by
@@ -97,6 +103,8 @@ func SelectAction(stApp *app.App, saptuneVers string) {
 		RevertAction(os.Stdout, system.CliArg(2), stApp)
 	case "staging":
 		StagingAction(system.CliArg(2), system.CliArgs(3), stApp)
+	case "check":
+		CheckAction(os.Stdout, stApp)
 	default:
 		PrintHelpAndExit(os.Stdout, 1)
 	}
@@ -134,6 +142,8 @@ func VerifyAllParameters(writer io.Writer, tuneApp *app.App) {
 		}
 		PrintNoteFields(writer, "NONE", comparisons, true)
 		tuneApp.PrintNoteApplyOrder(writer)
+		printParameterConflicts(writer, nil, tuneApp)
+		printStaleNotes(writer, nil, tuneApp)
 		if len(unsatisfiedNotes) == 0 {
 			fmt.Fprintf(writer, "The running system is currently well-tuned according to all of the enabled notes.\n")
 		} else {
@@ -142,6 +152,75 @@ func VerifyAllParameters(writer io.Writer, tuneApp *app.App) {
 	}
 }
 
+// printStaleNotes prints the applied notes, whose definition changed since
+// they were applied, together with the changed parameters.
+// If noteIDs is not empty, only these notes are checked
+func printStaleNotes(writer io.Writer, noteIDs []string, tuneApp *app.App) {
+	stale := []app.StaleNote{}
+	for _, staleNote := range tuneApp.GetStaleNotes(OverrideTuningSheets) {
+		if len(noteIDs) == 0 || system.IsStringInList(staleNote.NoteID, noteIDs) {
+			stale = append(stale, staleNote)
+		}
+	}
+	if len(stale) == 0 {
+		return
+	}
+	fmt.Fprintf(writer, "\nATTENTION: the definition of the following notes changed since they were applied:\n")
+	for _, staleNote := range stale {
+		fmt.Fprintf(writer, "    note %s (applied: version %s from %s, current: version %s from %s)\n", staleNote.NoteID, staleNote.Applied.Version, staleNote.Applied.Date, staleNote.Current.Version, staleNote.Current.Date)
+		for _, param := range staleNote.Applied.ChangedParams(staleNote.Current) {
+			fmt.Fprintf(writer, "        %s\n", param)
+		}
+	}
+	fmt.Fprintf(writer, "To get the changes take effect, please use 'saptune note refresh NoteID'.\n\n")
+}
+
+// printParameterConflicts prints the parameters, which are set to different
+// values by the enabled notes, together with the note which wins by the
+// apply order and the possibilities to resolve the conflict explicitly.
+// If noteIDs is not empty, only conflicts concerning these notes are printed
+func printParameterConflicts(writer io.Writer, noteIDs []string, tuneApp *app.App) {
+	conflicts := []note.ParameterConflict{}
+	for _, conflict := range tuneApp.GetParameterConflicts(OverrideTuningSheets) {
+		for _, noteID := range conflict.Notes {
+			if len(noteIDs) == 0 || system.IsStringInList(noteID, noteIDs) {
+				conflicts = append(conflicts, conflict)
+				break
+			}
+		}
+	}
+	if len(conflicts) == 0 {
+		return
+	}
+	fmt.Fprintf(writer, "\nATTENTION: the following parameters are set to different values by the enabled notes:\n")
+	for _, conflict := range conflicts {
+		fmt.Fprintf(writer, "    [%s] %s\n", conflict.Section, conflict.Key)
+		for i, noteID := range conflict.Notes {
+			if i == len(conflict.Notes)-1 {
+				fmt.Fprintf(writer, "        note %s: %s  <- applied last, this value is set\n", noteID, conflict.Values[i])
+			} else {
+				fmt.Fprintf(writer, "        note %s: %s\n", noteID, conflict.Values[i])
+			}
+		}
+	}
+	fmt.Fprintf(writer, "To resolve a conflict explicitly, either change the order of the enabled notes, so that the note, which value should be set, is applied last:\n")
+	fmt.Fprintf(writer, "    saptune note reorder NoteID --after OtherNoteID\n")
+	fmt.Fprintf(writer, "or set the value in an override file of the other notes, e.g.\n")
+	for _, conflict := range conflicts {
+		for _, noteID := range conflict.Notes {
+			if noteID == conflict.Winner() {
+				continue
+			}
+			setVal := fmt.Sprintf("%s.%s=%s", conflict.Section, conflict.Key, conflict.WinnerValue())
+			if strings.Contains(setVal, " ") {
+				setVal = "\"" + setVal + "\""
+			}
+			fmt.Fprintf(writer, "    saptune note customise %s --set %s\n", noteID, setVal)
+		}
+	}
+	fmt.Fprintf(writer, "\n")
+}
+
 // getFileName returns the corresponding filename of a given noteID
 // additional it returns a boolean value which is pointing out that
 // the Note is a custom Note (extraNote = true) or an internal one
@@ -203,10 +282,20 @@ func readYesNo(s string, in io.Reader, out io.Writer) bool {
 }
 
 // renameNote will rename a Note to an new name
-func renameNote(fileName, newFileName, ovFileName, newovFileName string, overrideNote, extraNote bool) {
+// the override file and the override drop-in directory are renamed too
+func renameNote(fileName, newFileName, noteID, newNoteID, ovTuningSheets string, overrideNote, extraNote bool) {
 	if overrideNote {
-		if err := os.Rename(ovFileName, newovFileName); err != nil {
-			system.ErrorExit("Failed to rename file '%s' to '%s' - %v", ovFileName, newovFileName, err)
+		renames := map[string]string{
+			fmt.Sprintf("%s%s", ovTuningSheets, noteID):    fmt.Sprintf("%s%s", ovTuningSheets, newNoteID),
+			note.OverrideDropInDir(ovTuningSheets, noteID): note.OverrideDropInDir(ovTuningSheets, newNoteID),
+		}
+		for src, dest := range renames {
+			if _, err := os.Stat(src); os.IsNotExist(err) {
+				continue
+			}
+			if err := os.Rename(src, dest); err != nil {
+				system.ErrorExit("Failed to rename file '%s' to '%s' - %v", src, dest, err)
+			}
 		}
 	}
 	if extraNote {
@@ -217,11 +306,18 @@ func renameNote(fileName, newFileName, ovFileName, newovFileName string, overrid
 }
 
 // deleteNote will delete a Note
-func deleteNote(fileName, ovFileName string, overrideNote, extraNote bool) {
+// the override file and the override drop-in directory are removed too,
+// so that a later Note with the same name does not inherit them
+func deleteNote(fileName, noteID, ovTuningSheets string, overrideNote, extraNote bool) {
 	if overrideNote {
-		if err := os.Remove(ovFileName); err != nil {
+		ovFileName := fmt.Sprintf("%s%s", ovTuningSheets, noteID)
+		if err := os.Remove(ovFileName); err != nil && !os.IsNotExist(err) {
 			system.ErrorExit("Failed to remove file '%s' - %v", ovFileName, err)
 		}
+		dropInDir := note.OverrideDropInDir(ovTuningSheets, noteID)
+		if err := os.RemoveAll(dropInDir); err != nil {
+			system.ErrorExit("Failed to remove directory '%s' - %v", dropInDir, err)
+		}
 	}
 	if extraNote {
 		if err := os.Remove(fileName); err != nil {
@@ -240,14 +336,30 @@ Tune system according to SAP and SUSE notes:
   saptune note [ list | verify | enabled ]
   saptune note [ apply | simulate | verify | customise | create | revert | show | delete ] NoteID
   saptune note rename NoteID newNoteID
+  saptune note verify --runtime [NoteID]
+  saptune note explain NoteID [parameter]
+  saptune note show --effective NoteID
+  saptune note customise NoteID --set section.key=value --unset section.key
+  saptune note create NoteID --from [ FILE | - ]
+  saptune note create NoteID --from-system section.key[,section.key...]
+  saptune note diff NoteID [NoteID2] [--format json]
+  saptune note refresh NoteID
+  saptune note reorder NoteID [ --before | --after ] NoteID2
+  saptune note reorder --order NoteID[,NoteID...]
 Tune system for all notes applicable to your SAP solution:
   saptune solution [ list | verify | enabled ]
   saptune solution [ apply | simulate | verify | revert ] SolutionName
+  saptune solution verify --runtime [SolutionName]
+  saptune solution change SolutionName
+  saptune solution migrate
+  saptune solution suggest [--apply]
 Staging control:
    saptune staging [ status | enable | disable | is-enabled | list | diff ]
    saptune staging [ analysis | diff | release ] [ NoteID | solutions | all ]
 Revert all parameters tuned by the SAP notes or solutions:
   saptune revert all
+Check the environment saptune is running in:
+  saptune check
 Remove the pending lock file from a former saptune call
   saptune lock remove
 Print current saptune version:
@@ -8,6 +8,7 @@ import (
 	"github.com/SUSE/saptune/sap/solution"
 	"github.com/SUSE/saptune/system"
 	"io"
+	"io/ioutil"
 	"os"
 	"path"
 	"strings"
@@ -101,14 +102,30 @@ Tune system according to SAP and SUSE notes:
   saptune note [ list | verify | enabled ]
   saptune note [ apply | simulate | verify | customise | create | revert | show | delete ] NoteID
   saptune note rename NoteID newNoteID
+  saptune note verify --runtime [NoteID]
+  saptune note explain NoteID [parameter]
+  saptune note show --effective NoteID
+  saptune note customise NoteID --set section.key=value --unset section.key
+  saptune note create NoteID --from [ FILE | - ]
+  saptune note create NoteID --from-system section.key[,section.key...]
+  saptune note diff NoteID [NoteID2] [--format json]
+  saptune note refresh NoteID
+  saptune note reorder NoteID [ --before | --after ] NoteID2
+  saptune note reorder --order NoteID[,NoteID...]
 Tune system for all notes applicable to your SAP solution:
   saptune solution [ list | verify | enabled ]
   saptune solution [ apply | simulate | verify | revert ] SolutionName
+  saptune solution verify --runtime [SolutionName]
+  saptune solution change SolutionName
+  saptune solution migrate
+  saptune solution suggest [--apply]
 Staging control:
    saptune staging [ status | enable | disable | is-enabled | list | diff ]
    saptune staging [ analysis | diff | release ] [ NoteID | solutions | all ]
 Revert all parameters tuned by the SAP notes or solutions:
   saptune revert all
+Check the environment saptune is running in:
+  saptune check
 Remove the pending lock file from a former saptune call
   saptune lock remove
 Print current saptune version:
@@ -214,14 +231,30 @@ Tune system according to SAP and SUSE notes:
   saptune note [ list | verify | enabled ]
   saptune note [ apply | simulate | verify | customise | create | revert | show | delete ] NoteID
   saptune note rename NoteID newNoteID
+  saptune note verify --runtime [NoteID]
+  saptune note explain NoteID [parameter]
+  saptune note show --effective NoteID
+  saptune note customise NoteID --set section.key=value --unset section.key
+  saptune note create NoteID --from [ FILE | - ]
+  saptune note create NoteID --from-system section.key[,section.key...]
+  saptune note diff NoteID [NoteID2] [--format json]
+  saptune note refresh NoteID
+  saptune note reorder NoteID [ --before | --after ] NoteID2
+  saptune note reorder --order NoteID[,NoteID...]
 Tune system for all notes applicable to your SAP solution:
   saptune solution [ list | verify | enabled ]
   saptune solution [ apply | simulate | verify | revert ] SolutionName
+  saptune solution verify --runtime [SolutionName]
+  saptune solution change SolutionName
+  saptune solution migrate
+  saptune solution suggest [--apply]
 Staging control:
    saptune staging [ status | enable | disable | is-enabled | list | diff ]
    saptune staging [ analysis | diff | release ] [ NoteID | solutions | all ]
 Revert all parameters tuned by the SAP notes or solutions:
   saptune revert all
+Check the environment saptune is running in:
+  saptune check
 Remove the pending lock file from a former saptune call
   saptune lock remove
 Print current saptune version:
@@ -243,3 +276,44 @@ Print this message:
 		t.Errorf("wrong text returned by ErrorExit: '%v' instead of ''\n", errExOut)
 	}
 }
+
+func TestPrintParameterConflicts(t *testing.T) {
+	ovDir, err := ioutil.TempDir("", "saptune-conflict-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(ovDir)
+	oldOverrideTuningSheets := OverrideTuningSheets
+	defer func() { OverrideTuningSheets = oldOverrideTuningSheets }()
+	OverrideTuningSheets = ovDir + "/"
+
+	cApp := app.InitialiseApp(TstFilesInGOPATH, "", tuningOpts, AllTestSolutions)
+	cApp.NoteApplyOrder = []string{"simpleNote", "extraNote"}
+
+	buffer := bytes.Buffer{}
+	printParameterConflicts(&buffer, nil, cApp)
+	checkOut(t, buffer.String(), "")
+
+	if err := ioutil.WriteFile(path.Join(ovDir, "extraNote"), []byte("[sysctl]\nnet.ipv4.ip_local_port_range = 1024 65000\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	conflictMatchText := `
+ATTENTION: the following parameters are set to different values by the enabled notes:
+    [sysctl] net.ipv4.ip_local_port_range
+        note simpleNote: = 31768 61999
+        note extraNote: = 1024 65000  <- applied last, this value is set
+To resolve a conflict explicitly, either change the order of the enabled notes, so that the note, which value should be set, is applied last:
+    saptune note reorder NoteID --after OtherNoteID
+or set the value in an override file of the other notes, e.g.
+    saptune note customise simpleNote --set "sysctl.net.ipv4.ip_local_port_range=1024 65000"
+
+`
+	buffer.Reset()
+	printParameterConflicts(&buffer, []string{"simpleNote"}, cApp)
+	checkOut(t, buffer.String(), conflictMatchText)
+
+	// conflicts not concerning the given notes are not printed
+	buffer.Reset()
+	printParameterConflicts(&buffer, []string{"otherNote"}, cApp)
+	checkOut(t, buffer.String(), "")
+}
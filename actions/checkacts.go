@@ -0,0 +1,333 @@
+package actions
+
+import (
+	"fmt"
+	"github.com/SUSE/saptune/app"
+	"github.com/SUSE/saptune/system"
+	"io"
+	"strings"
+)
+
+// results of the environment checks
+const (
+	checkPass = "PASS"
+	checkWarn = "WARN"
+	checkFail = "FAIL"
+)
+
+// exit codes of 'saptune check', suitable for monitoring tools
+const (
+	exitCheckPass = 0
+	exitCheckWarn = 1
+	exitCheckFail = 2
+	// ExitCheckUnknown is used, if 'saptune check' itself fails
+	ExitCheckUnknown = 3
+)
+
+// sapconfActiveFiles exist, if sapconf has tuned the system
+// /var/lib/sapconf/act_profile in sle12
+// /var/run/sapconf/active in sle15
+var sapconfActiveFiles = []string{"/var/lib/sapconf/act_profile", "/var/run/sapconf/active"}
+
+// sapconfLeftOvers are the tuned profiles of older sapconf versions, which
+// may remain on the system after sapconf was removed
+var sapconfLeftOvers = []string{"/etc/tuned/sapconf", "/etc/tuned/sap-hana", "/etc/tuned/sap-netweaver", "/etc/tuned/sap-ase", "/etc/tuned/sap-bobj"}
+
+// v1TunedConf is created during the package update from saptune version 1
+// to saptune version 2
+var v1TunedConf = "/etc/tuned/saptune/tuned.conf"
+
+// checkResult is the result of a single environment check
+type checkResult struct {
+	Check  string // name of the check
+	Result string // checkPass, checkWarn or checkFail
+	Info   string // the state found on the system
+	Hint   string // how to fix the problem, empty for checkPass
+}
+
+// environmentChecks are the checks run by 'saptune check' in this order
+var environmentChecks = []func(*app.App) checkResult{
+	checkSaptuneService,
+	checkSystemState,
+	checkTunedService,
+	checkSapconf,
+	checkSapconfLeftOvers,
+	checkUpdateLeftOvers,
+	checkNoteDefinitions,
+	checkHelperCmds,
+	checkProcSys,
+}
+
+// CheckAction runs all environment checks, prints the results and exits
+// with 0 (all checks passed), 1 (warnings) or 2 (failures). Errors of
+// 'saptune check' itself exit with 3 (ExitCheckUnknown)
+func CheckAction(writer io.Writer, tuneApp *app.App) {
+	results := []checkResult{}
+	for _, check := range environmentChecks {
+		results = append(results, check(tuneApp))
+	}
+	system.ErrorExit("", printCheckResults(writer, results))
+}
+
+// printCheckResults prints the results of the environment checks together
+// with the remediation hints and returns the matching exit code
+func printCheckResults(writer io.Writer, results []checkResult) int {
+	exitCode := exitCheckPass
+	warnings := 0
+	failures := 0
+	for _, res := range results {
+		colorText := ""
+		resetText := ""
+		switch res.Result {
+		case checkPass:
+			colorText = setGreenText
+		case checkWarn:
+			warnings++
+			if exitCode < exitCheckWarn {
+				exitCode = exitCheckWarn
+			}
+		case checkFail:
+			failures++
+			colorText = setRedText
+			exitCode = exitCheckFail
+		}
+		if colorText != "" {
+			resetText = resetTextColor
+		}
+		fmt.Fprintf(writer, "%s[%s]%s %s: %s\n", colorText, res.Result, resetText, res.Check, res.Info)
+		if res.Hint != "" {
+			fmt.Fprintf(writer, "       -> %s\n", res.Hint)
+		}
+	}
+	fmt.Fprintf(writer, "\n%d checks: %d passed, %d warnings, %d failures\n", len(results), len(results)-warnings-failures, warnings, failures)
+	return exitCode
+}
+
+// checkSaptuneService checks, if saptune.service is enabled and running
+func checkSaptuneService(tuneApp *app.App) checkResult {
+	res := checkResult{Check: SaptuneService}
+	enabled := system.SystemctlIsEnabled(SaptuneService)
+	running := system.SystemctlIsRunning(SaptuneService)
+	switch {
+	case system.SystemctlIsFailed(SaptuneService):
+		res.Result = checkFail
+		res.Info = "service is in a failed state"
+		res.Hint = "check 'journalctl -u saptune.service' and restart the service with 'saptune service restart'"
+	case enabled && running:
+		res.Result = checkPass
+		res.Info = "service is enabled and running"
+	case !enabled && !running:
+		res.Result = checkWarn
+		res.Info = "service is disabled and not running, the tuning is not active and not applied after a reboot"
+		res.Hint = "enable and start the service with 'saptune service enablestart'"
+	case !enabled:
+		res.Result = checkWarn
+		res.Info = "service is disabled, the tuning is not applied after a reboot"
+		res.Hint = "enable the service with 'saptune service enable'"
+	default:
+		res.Result = checkWarn
+		res.Info = "service is enabled, but not running, the tuning is not active"
+		res.Hint = "start the service with 'saptune service start'"
+	}
+	return res
+}
+
+// checkSystemState checks the overall state of systemd
+func checkSystemState(tuneApp *app.App) checkResult {
+	res := checkResult{Check: "systemd"}
+	state := system.GetSystemState()
+	switch state {
+	case "running":
+		res.Result = checkPass
+		res.Info = "system is running"
+	case "degraded":
+		res.Result = checkWarn
+		res.Info = fmt.Sprintf("system is degraded, failed units: %s", strings.Join(system.GetFailedUnits(), " "))
+		res.Hint = "check the failed units with 'systemctl --failed'"
+	case "initializing", "starting":
+		res.Result = checkWarn
+		res.Info = fmt.Sprintf("system is still %s", state)
+		res.Hint = "repeat the check after the system has finished booting"
+	default:
+		if state == "" {
+			state = "unknown"
+		}
+		res.Result = checkFail
+		res.Info = fmt.Sprintf("system state is '%s'", state)
+		res.Hint = "check the system state with 'systemctl status'"
+	}
+	return res
+}
+
+// checkTunedService checks for an enabled or running tuned and its profile,
+// which may set conflicting values
+func checkTunedService(tuneApp *app.App) checkResult {
+	res := checkResult{Check: TunedService}
+	if !TunedIsActive() {
+		res.Result = checkPass
+		res.Info = "service is disabled and not running"
+		return res
+	}
+	profile := ""
+	if system.SystemctlIsRunning(TunedService) {
+		profile = system.GetTunedAdmProfile()
+	}
+	switch profile {
+	case "":
+		res.Result = checkWarn
+		res.Info = "service is enabled/active, so we may encounter conflicting tuning values"
+		res.Hint = "disable and stop the service with 'systemctl disable --now tuned.service'"
+	case "saptune":
+		res.Result = checkFail
+		res.Info = "service is active with the profile 'saptune' of saptune version 1, which conflicts with the tuning of the enabled notes"
+		res.Hint = "switch off the profile with 'tuned-adm off' and disable the service with 'systemctl disable --now tuned.service'"
+	default:
+		res.Result = checkWarn
+		res.Info = fmt.Sprintf("service is active with the profile '%s', which may set conflicting tuning values", profile)
+		res.Hint = "switch off the profile with 'tuned-adm off' and disable the service with 'systemctl disable --now tuned.service'"
+	}
+	return res
+}
+
+// checkSapconf checks for an active sapconf, which makes saptune refuse
+// to tune the system
+func checkSapconf(tuneApp *app.App) checkResult {
+	res := checkResult{Check: SapconfService}
+	if sapconfIsActive() {
+		res.Result = checkFail
+		res.Info = "found an active sapconf, so saptune refuses to tune the system"
+		res.Hint = fmt.Sprintf("disable and stop the service with 'systemctl disable --now sapconf.service' and remove %s, if still present", strings.Join(sapconfActiveFiles, " or "))
+		return res
+	}
+	res.Result = checkPass
+	res.Info = "no active sapconf found"
+	return res
+}
+
+// checkSapconfLeftOvers checks for tuned profiles of older sapconf versions
+func checkSapconfLeftOvers(tuneApp *app.App) checkResult {
+	res := checkResult{Check: "sapconf leftovers"}
+	found := []string{}
+	for _, leftOver := range sapconfLeftOvers {
+		if system.CmdIsAvailable(leftOver) {
+			found = append(found, leftOver)
+		}
+	}
+	if len(found) != 0 {
+		res.Result = checkWarn
+		res.Info = fmt.Sprintf("found tuned profiles of sapconf: %s", strings.Join(found, " "))
+		res.Hint = "remove the profiles, if sapconf is no longer used"
+		return res
+	}
+	res.Result = checkPass
+	res.Info = "no tuned profiles of sapconf found"
+	return res
+}
+
+// checkUpdateLeftOvers checks for left over files and settings from the
+// migration of saptune version 1 to saptune version 2
+func checkUpdateLeftOvers(tuneApp *app.App) checkResult {
+	res := checkResult{Check: "saptune version 1 leftovers"}
+	if V1ConfigLeftOver(tuneApp) {
+		res.Result = checkFail
+		res.Info = "there are 'old' solutions or notes defined in file '/etc/sysconfig/saptune'"
+		res.Hint = "finish the migration from saptune version 1 to version 2 as described in saptune-migrate(7)"
+		return res
+	}
+	if V1TunedConfLeftOver() {
+		res.Result = checkWarn
+		res.Info = fmt.Sprintf("found file '%s' left over from the migration of saptune version 1 to saptune version 2", v1TunedConf)
+		res.Hint = "check and remove this file as it may work against the settings of some SAP Notes, see saptune-migrate(7)"
+		return res
+	}
+	res.Result = checkPass
+	res.Info = "no leftovers found"
+	return res
+}
+
+// checkNoteDefinitions checks, if a Note definition file exists for all
+// notes of the apply order list. Other saptune commands remove such notes
+// from the apply order list (NoteSanityCheck), but 'saptune check' only
+// reports them
+func checkNoteDefinitions(tuneApp *app.App) checkResult {
+	res := checkResult{Check: "note definitions"}
+	if notes := tuneApp.NotesWithoutDefinition(); len(notes) != 0 {
+		res.Result = checkWarn
+		res.Info = fmt.Sprintf("no Note definition file found for the notes '%s' listed in the apply order list, may be the files were removed or renamed without reverting the notes before", strings.Join(notes, " "))
+		res.Hint = "run any other saptune command (e.g. 'saptune note enabled') to remove the notes from the apply order list"
+		return res
+	}
+	res.Result = checkPass
+	res.Info = "all enabled notes have a Note definition file"
+	return res
+}
+
+// checkHelperCmds checks for the external commands used by saptune
+func checkHelperCmds(tuneApp *app.App) checkResult {
+	res := checkResult{Check: "helper commands", Result: checkPass}
+	missing := []string{}
+	for _, cmd := range system.GetHelperCmds() {
+		if system.CmdIsAvailable(cmd.Path) {
+			continue
+		}
+		missing = append(missing, fmt.Sprintf("%s (%s)", cmd.Path, cmd.Purpose))
+		if cmd.Required {
+			res.Result = checkFail
+		} else if res.Result == checkPass {
+			res.Result = checkWarn
+		}
+	}
+	if len(missing) != 0 {
+		res.Info = fmt.Sprintf("missing %s", strings.Join(missing, ", "))
+		res.Hint = "install the packages providing the missing commands, the affected settings can not be handled otherwise"
+		return res
+	}
+	res.Info = "all commands available"
+	return res
+}
+
+// checkProcSys checks, if the kernel parameters can be changed
+func checkProcSys(tuneApp *app.App) checkResult {
+	res := checkResult{Check: "/proc/sys"}
+	if system.ProcSysIsReadOnly() {
+		res.Result = checkFail
+		res.Info = "mounted read-only, the kernel parameters of section [sysctl] can not be changed"
+		res.Hint = "mount /proc/sys read-write (e.g. check the options of the container runtime)"
+		return res
+	}
+	res.Result = checkPass
+	res.Info = "writable"
+	return res
+}
+
+// sapconfIsActive returns true, if sapconf.service is enabled or has exited
+// but its 'active' file is still available
+func sapconfIsActive() bool {
+	if system.SystemctlIsEnabled(SapconfService) {
+		return true
+	}
+	for _, file := range sapconfActiveFiles {
+		if system.CmdIsAvailable(file) {
+			return true
+		}
+	}
+	return false
+}
+
+// TunedIsActive returns true, if tuned.service is enabled or running
+func TunedIsActive() bool {
+	return system.SystemctlIsEnabled(TunedService) || system.SystemctlIsRunning(TunedService)
+}
+
+// V1TunedConfLeftOver returns true, if the tuned configuration created during
+// the package update from saptune version 1 to saptune version 2 still exists
+func V1TunedConfLeftOver() bool {
+	return system.CheckForPattern(v1TunedConf, "#stv1tov2#")
+}
+
+// V1ConfigLeftOver returns true, if 'old' solutions or notes are defined in
+// /etc/sysconfig/saptune, but the migration from saptune version 1 to
+// saptune version 2 was not finished
+func V1ConfigLeftOver(tuneApp *app.App) bool {
+	return tuneApp != nil && len(tuneApp.NoteApplyOrder) == 0 && (len(tuneApp.TuneForNotes) != 0 || len(tuneApp.TuneForSolutions) != 0)
+}
@@ -0,0 +1,135 @@
+package actions
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/SUSE/saptune/app"
+	"github.com/SUSE/saptune/system"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestPrintCheckResults(t *testing.T) {
+	oldGreen, oldRed, oldReset := setGreenText, setRedText, resetTextColor
+	defer func() { setGreenText, setRedText, resetTextColor = oldGreen, oldRed, oldReset }()
+	setGreenText, setRedText, resetTextColor = "", "", ""
+
+	results := []checkResult{
+		{Check: "first", Result: checkPass, Info: "all fine"},
+		{Check: "second", Result: checkWarn, Info: "not so fine", Hint: "fix it"},
+	}
+	buffer := bytes.Buffer{}
+	if exitCode := printCheckResults(&buffer, results); exitCode != exitCheckWarn {
+		t.Errorf("wrong exit code '%d'", exitCode)
+	}
+	expected := `[PASS] first: all fine
+[WARN] second: not so fine
+       -> fix it
+
+2 checks: 1 passed, 1 warnings, 0 failures
+`
+	checkOut(t, buffer.String(), expected)
+
+	results = append(results, checkResult{Check: "third", Result: checkFail, Info: "broken", Hint: "repair it"})
+	buffer.Reset()
+	if exitCode := printCheckResults(&buffer, results); exitCode != exitCheckFail {
+		t.Errorf("wrong exit code '%d'", exitCode)
+	}
+	if !strings.Contains(buffer.String(), "3 checks: 1 passed, 1 warnings, 1 failures") {
+		t.Errorf("wrong summary in '%s'", buffer.String())
+	}
+	buffer.Reset()
+	if exitCode := printCheckResults(&buffer, results[:1]); exitCode != exitCheckPass {
+		t.Errorf("wrong exit code '%d'", exitCode)
+	}
+}
+
+func TestCheckLeftOvers(t *testing.T) {
+	oldLeftOvers := sapconfLeftOvers
+	oldTunedConf := v1TunedConf
+	defer func() {
+		sapconfLeftOvers = oldLeftOvers
+		v1TunedConf = oldTunedConf
+	}()
+	tstDir := path.Join(os.TempDir(), "saptune_check")
+	defer os.RemoveAll(tstDir)
+	if err := os.MkdirAll(path.Join(tstDir, "sap-hana"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	sapconfLeftOvers = []string{path.Join(tstDir, "sapconf"), path.Join(tstDir, "sap-hana")}
+	v1TunedConf = path.Join(tstDir, "tuned.conf")
+	tApp := &app.App{NoteApplyOrder: []string{"2205917"}, TuneForNotes: []string{"2205917"}}
+
+	res := checkSapconfLeftOvers(tApp)
+	if res.Result != checkWarn || !strings.Contains(res.Info, path.Join(tstDir, "sap-hana")) || strings.Contains(res.Info, path.Join(tstDir, "sapconf")) {
+		t.Errorf("wrong result '%+v'", res)
+	}
+	if res = checkUpdateLeftOvers(tApp); res.Result != checkPass {
+		t.Errorf("wrong result '%+v'", res)
+	}
+
+	os.RemoveAll(path.Join(tstDir, "sap-hana"))
+	if err := ioutil.WriteFile(v1TunedConf, []byte("#stv1tov2#\n[main]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if res = checkSapconfLeftOvers(tApp); res.Result != checkPass {
+		t.Errorf("wrong result '%+v'", res)
+	}
+	if res = checkUpdateLeftOvers(tApp); res.Result != checkWarn || res.Hint == "" {
+		t.Errorf("wrong result '%+v'", res)
+	}
+	tApp.NoteApplyOrder = []string{}
+	if res = checkUpdateLeftOvers(tApp); res.Result != checkFail {
+		t.Errorf("wrong result '%+v'", res)
+	}
+}
+
+func TestCheckNoteDefinitions(t *testing.T) {
+	tstApp := &app.App{NoteApplyOrder: []string{"8932147"}}
+	res := checkNoteDefinitions(tstApp)
+	if res.Result != checkWarn || !strings.Contains(res.Info, "'8932147'") || res.Hint == "" {
+		t.Errorf("wrong result '%+v'", res)
+	}
+	// the apply order list is only reported, but not changed
+	if len(tstApp.NoteApplyOrder) != 1 {
+		t.Errorf("apply order list changed '%+v'", tstApp.NoteApplyOrder)
+	}
+	tstApp.NoteApplyOrder = []string{}
+	if res = checkNoteDefinitions(tstApp); res.Result != checkPass {
+		t.Errorf("wrong result '%+v'", res)
+	}
+}
+
+func TestCheckAction(t *testing.T) {
+	oldOSExit := system.OSExit
+	defer func() { system.OSExit = oldOSExit }()
+	system.OSExit = tstosExit
+	tstRetErrorExit = -1
+
+	buffer := bytes.Buffer{}
+	CheckAction(&buffer, tApp)
+	txt := buffer.String()
+	if !strings.Contains(txt, fmt.Sprintf("%d checks: ", len(environmentChecks))) {
+		t.Errorf("missing summary in '%s'", txt)
+	}
+	exitCode := exitCheckPass
+	if strings.Contains(txt, "[FAIL]") {
+		exitCode = exitCheckFail
+	} else if strings.Contains(txt, "[WARN]") {
+		exitCode = exitCheckWarn
+	}
+	if tstRetErrorExit != exitCode {
+		t.Errorf("wrong exit code '%d', expected '%d'", tstRetErrorExit, exitCode)
+	}
+
+	// errors of 'saptune check' itself are distinct from the results
+	system.SetErrorExitCode(ExitCheckUnknown)
+	defer system.SetErrorExitCode(1)
+	system.ErrorExit("")
+	if tstRetErrorExit != ExitCheckUnknown {
+		t.Errorf("wrong exit code '%d', expected '%d'", tstRetErrorExit, ExitCheckUnknown)
+	}
+}
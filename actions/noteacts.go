@@ -1,16 +1,20 @@
 package actions
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/SUSE/saptune/app"
 	"github.com/SUSE/saptune/sap/note"
 	"github.com/SUSE/saptune/system"
+	"github.com/SUSE/saptune/txtparser"
 	"io"
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"path"
 	"sort"
 	"strings"
+	"time"
 )
 
 var templateFile = "/usr/share/saptune/NoteTemplate.conf"
@@ -24,21 +28,45 @@ func NoteAction(actionName, noteID, newNoteID string, tuneApp *app.App) {
 	case "list":
 		NoteActionList(os.Stdout, tuneApp, tuningOptions)
 	case "verify":
+		// compare the limits with the ones of the running processes
+		note.RuntimeLimitsCheck = system.IsFlagSet("runtime")
 		NoteActionVerify(os.Stdout, noteID, tuneApp)
 	case "simulate":
 		NoteActionSimulate(os.Stdout, noteID, tuneApp)
 	case "customise", "customize":
-		NoteActionCustomise(noteID, tuneApp)
+		if system.IsFlagSet("set") || system.IsFlagSet("unset") {
+			NoteActionCustomiseSet(os.Stdout, noteID, system.GetFlagVals("set"), system.GetFlagVals("unset"), NoteTuningSheets, ExtraTuningSheets, OverrideTuningSheets, tuneApp)
+		} else {
+			NoteActionCustomise(noteID, tuneApp)
+		}
 	case "create":
-		NoteActionCreate(noteID, tuneApp)
+		if system.IsFlagSet("from-system") {
+			NoteActionCreateFromSystem(os.Stdout, noteID, system.GetFlagVals("from-system"), NoteTuningSheets, ExtraTuningSheets, tuneApp)
+		} else if system.IsFlagSet("from") {
+			NoteActionCreateFrom(os.Stdin, os.Stdout, noteID, system.GetFlagVal("from"), NoteTuningSheets, ExtraTuningSheets, tuneApp)
+		} else {
+			NoteActionCreate(noteID, tuneApp)
+		}
 	case "show":
-		NoteActionShow(os.Stdout, noteID, NoteTuningSheets, ExtraTuningSheets, tuneApp)
+		if system.IsFlagSet("effective") {
+			NoteActionShowEffective(os.Stdout, noteID, NoteTuningSheets, ExtraTuningSheets, OverrideTuningSheets, tuneApp)
+		} else {
+			NoteActionShow(os.Stdout, noteID, NoteTuningSheets, ExtraTuningSheets, tuneApp)
+		}
+	case "explain":
+		NoteActionExplain(os.Stdout, noteID, newNoteID, NoteTuningSheets, ExtraTuningSheets, OverrideTuningSheets, tuneApp)
+	case "diff":
+		NoteActionDiff(os.Stdout, noteID, newNoteID, system.GetFlagVal("format"), NoteTuningSheets, ExtraTuningSheets, OverrideTuningSheets, tuneApp)
 	case "delete":
 		NoteActionDelete(os.Stdin, os.Stdout, noteID, NoteTuningSheets, ExtraTuningSheets, OverrideTuningSheets, tuneApp)
 	case "rename":
 		NoteActionRename(os.Stdin, os.Stdout, noteID, newNoteID, NoteTuningSheets, ExtraTuningSheets, OverrideTuningSheets, tuneApp)
 	case "revert":
 		NoteActionRevert(os.Stdout, noteID, tuneApp)
+	case "refresh":
+		NoteActionRefresh(os.Stdout, noteID, tuneApp)
+	case "reorder":
+		NoteActionReorder(os.Stdout, noteID, system.GetFlagVal("before"), system.GetFlagVal("after"), system.GetFlagVal("order"), tuneApp)
 	case "enabled":
 		NoteActionEnabled(os.Stdout, tuneApp)
 	default:
@@ -66,6 +94,7 @@ func NoteActionApply(writer io.Writer, noteID string, tuneApp *app.App) {
 		system.ErrorExit("Failed to tune for note %s: %v", noteID, err)
 	}
 	fmt.Fprintf(writer, "The note has been applied successfully.\n")
+	printParameterConflicts(writer, []string{noteID}, tuneApp)
 	rememberMessage(writer)
 }
 
@@ -79,7 +108,7 @@ func NoteActionList(writer io.Writer, tuneApp *app.App, tOptions note.TuningOpti
 		if len(noteID) >= 8 {
 			format = "\t%s\t%s\n"
 		}
-		if _, err := os.Stat(fmt.Sprintf("%s%s", OverrideTuningSheets, noteID)); err == nil {
+		if len(note.GetOverrideFiles(OverrideTuningSheets, noteID)) != 0 {
 			format = " O" + format
 		}
 		if i := sort.SearchStrings(solutionNoteIDs, noteID); i < len(solutionNoteIDs) && solutionNoteIDs[i] == noteID {
@@ -113,6 +142,8 @@ func NoteActionVerify(writer io.Writer, noteID string, tuneApp *app.App) {
 		noteComp[noteID] = comparisons
 		PrintNoteFields(writer, "HEAD", noteComp, true)
 		tuneApp.PrintNoteApplyOrder(writer)
+		printParameterConflicts(writer, []string{noteID}, tuneApp)
+		printStaleNotes(writer, []string{noteID}, tuneApp)
 		if !conforming {
 			system.ErrorExit("The parameters listed above have deviated from the specified note.\n")
 		} else {
@@ -121,6 +152,80 @@ func NoteActionVerify(writer io.Writer, noteID string, tuneApp *app.App) {
 	}
 }
 
+// NoteActionRefresh applies the changes of the definition of an applied
+// Note (e.g. by 'note customise' or a staging release) without reverting
+// the whole Note. Only changed parameters are applied, parameters no longer
+// set by the Note are reverted
+func NoteActionRefresh(writer io.Writer, noteID string, tuneApp *app.App) {
+	if noteID == "" {
+		PrintHelpAndExit(writer, 1)
+	}
+	changed, reverted, err := tuneApp.RefreshNote(noteID)
+	if err != nil {
+		system.ErrorExit("Failed to refresh note %s: %v", noteID, err)
+	}
+	if len(changed) == 0 && len(reverted) == 0 {
+		fmt.Fprintf(writer, "The applied note %s is up to date, nothing to refresh.\n", noteID)
+		return
+	}
+	if len(changed) != 0 {
+		fmt.Fprintf(writer, "The following parameters were changed:\n")
+		for _, param := range changed {
+			fmt.Fprintf(writer, "\t%s\n", param)
+		}
+	}
+	if len(reverted) != 0 {
+		fmt.Fprintf(writer, "The following parameters are no longer set by the note and were reverted:\n")
+		for _, param := range reverted {
+			fmt.Fprintf(writer, "\t%s\n", param)
+		}
+	}
+	fmt.Fprintf(writer, "The note has been refreshed successfully.\n")
+	printParameterConflicts(writer, []string{noteID}, tuneApp)
+}
+
+// NoteActionReorder changes the apply order of the enabled notes.
+// Either noteID is moved before or after another enabled note or the
+// complete order is given as comma separated list of the enabled notes.
+// Only the parameters, whose value changes because of the new order, are
+// re-applied.
+func NoteActionReorder(writer io.Writer, noteID, before, after, order string, tuneApp *app.App) {
+	var newOrder []string
+	var err error
+	switch {
+	case order != "" && noteID == "" && before == "" && after == "":
+		newOrder = strings.Split(strings.Replace(order, " ", "", -1), ",")
+	case noteID != "" && before != "" && after == "" && order == "":
+		newOrder, err = tuneApp.MoveInNoteApplyOrder(noteID, before, true)
+	case noteID != "" && after != "" && before == "" && order == "":
+		newOrder, err = tuneApp.MoveInNoteApplyOrder(noteID, after, false)
+	default:
+		PrintHelpAndExit(writer, 1)
+	}
+	if err != nil {
+		system.ErrorExit("Failed to change the order of the enabled notes: %v", err)
+	}
+	changed, err := tuneApp.ReorderNotes(newOrder)
+	if err != nil {
+		system.ErrorExit("Failed to change the order of the enabled notes: %v", err)
+	}
+	fmt.Fprintf(writer, "The order of the enabled notes has been changed successfully.\n")
+	tuneApp.PrintNoteApplyOrder(writer)
+	if len(changed) == 0 {
+		fmt.Fprintf(writer, "No parameter values changed.\n")
+		return
+	}
+	params := []string{}
+	for param := range changed {
+		params = append(params, param)
+	}
+	sort.Strings(params)
+	fmt.Fprintf(writer, "The following parameters were re-applied:\n")
+	for _, param := range params {
+		fmt.Fprintf(writer, "\t%s\tvalue of note %s\n", param, changed[param])
+	}
+}
+
 // NoteActionSimulate shows all changes that will be applied to the system if
 // the Note will be applied.
 func NoteActionSimulate(writer io.Writer, noteID string, tuneApp *app.App) {
@@ -176,7 +281,7 @@ func NoteActionCustomise(noteID string, tuneApp *app.App) {
 	if _, ok := tuneApp.IsNoteApplied(noteID); !ok {
 		system.InfoLog("Do not forget to apply the just edited Note to get your changes to take effect\n")
 	} else { // noteID already applied
-		system.InfoLog("Your just edited Note is already applied. To get your changes to take effect, please 'revert' the Note and apply again.\n")
+		system.InfoLog("Your just edited Note is already applied. To get your changes to take effect, please use 'saptune note refresh %s'.\n", noteID)
 	}
 	// if syscall.Exec returns 'nil' the execution of the program ends immediately
 	// changed syscall.Exec to exec.Command because of the new 'lock' handling
@@ -187,17 +292,7 @@ func NoteActionCreate(noteID string, tuneApp *app.App) {
 	if noteID == "" {
 		PrintHelpAndExit(os.Stdout, 1)
 	}
-	if _, err := tuneApp.GetNoteByID(noteID); err == nil {
-		system.ErrorExit("Note '%s' already exists. Please use 'saptune note customise %s' instead to create an override file or choose another NoteID.", noteID, noteID)
-	}
-	fileName := fmt.Sprintf("%s%s", NoteTuningSheets, noteID)
-	if _, err := os.Stat(fileName); err == nil {
-		system.ErrorExit("Note '%s' already exists in %s. Please use 'saptune note customise %s' instead to create an override file or choose another NoteID.", noteID, NoteTuningSheets, noteID)
-	}
-	extraFileName := fmt.Sprintf("%s%s.conf", ExtraTuningSheets, noteID)
-	if _, err := os.Stat(extraFileName); err == nil {
-		system.ErrorExit("Note '%s' already exists in %s. Please use 'saptune note customise %s' instead to create an override file or choose another NoteID.", noteID, ExtraTuningSheets, noteID)
-	}
+	extraFileName := chkNewNoteID(noteID, NoteTuningSheets, ExtraTuningSheets, tuneApp)
 	//if _, err := os.Stat(extraFileName); os.IsNotExist(err) {
 	//copy template file
 	err := system.CopyFile(templateFile, extraFileName)
@@ -217,6 +312,244 @@ func NoteActionCreate(noteID string, tuneApp *app.App) {
 	}
 }
 
+// chkNewNoteID checks, if the NoteID is not yet used and returns the name
+// of the new Note definition file in the extra directory
+func chkNewNoteID(noteID, noteTuningSheets, extraTuningSheets string, tuneApp *app.App) string {
+	if _, err := tuneApp.GetNoteByID(noteID); err == nil {
+		system.ErrorExit("Note '%s' already exists. Please use 'saptune note customise %s' instead to create an override file or choose another NoteID.", noteID, noteID)
+	}
+	fileName := fmt.Sprintf("%s%s", noteTuningSheets, noteID)
+	if _, err := os.Stat(fileName); err == nil {
+		system.ErrorExit("Note '%s' already exists in %s. Please use 'saptune note customise %s' instead to create an override file or choose another NoteID.", noteID, noteTuningSheets, noteID)
+	}
+	extraFileName := fmt.Sprintf("%s%s.conf", extraTuningSheets, noteID)
+	if _, err := os.Stat(extraFileName); err == nil {
+		system.ErrorExit("Note '%s' already exists in %s. Please use 'saptune note customise %s' instead to create an override file or choose another NoteID.", noteID, extraTuningSheets, noteID)
+	}
+	return extraFileName
+}
+
+// NoteActionCreateFrom creates a new Note definition file in the extra
+// directory from the content of the given file or from stdin ('-')
+// without starting an editor
+func NoteActionCreateFrom(reader io.Reader, writer io.Writer, noteID, from, noteTuningSheets, extraTuningSheets string, tuneApp *app.App) {
+	if noteID == "" || from == "" {
+		PrintHelpAndExit(writer, 1)
+	}
+	extraFileName := chkNewNoteID(noteID, noteTuningSheets, extraTuningSheets, tuneApp)
+	var content []byte
+	var err error
+	if from == "-" {
+		content, err = ioutil.ReadAll(reader)
+	} else {
+		content, err = ioutil.ReadFile(from)
+	}
+	if err != nil {
+		system.ErrorExit("Failed to read the Note definition from '%s' - %v", from, err)
+	}
+	if err := chkNoteContent(string(content)); err != nil {
+		system.ErrorExit("Invalid Note definition in '%s' - %v", from, err)
+	}
+	if err := os.MkdirAll(extraTuningSheets, 0755); err != nil {
+		system.ErrorExit("Problems while creating directory '%s' - %v", extraTuningSheets, err)
+	}
+	if err := ioutil.WriteFile(extraFileName, content, 0644); err != nil {
+		system.ErrorExit("Problems while writing '%s' - %v", extraFileName, err)
+	}
+	fmt.Fprintf(writer, "Note definition file '%s' created.\nUse 'saptune note apply %s' to get the Note take effect.\n", extraFileName, noteID)
+}
+
+// NoteActionCreateFromSystem creates a new Note definition file in the
+// extra directory with the current system values of the given parameters
+// ('section.key', more than one separated by ',')
+func NoteActionCreateFromSystem(writer io.Writer, noteID string, params []string, noteTuningSheets, extraTuningSheets string, tuneApp *app.App) {
+	if noteID == "" || len(params) == 0 {
+		PrintHelpAndExit(writer, 1)
+	}
+	extraFileName := chkNewNoteID(noteID, noteTuningSheets, extraTuningSheets, tuneApp)
+	paramList := []string{}
+	for _, param := range params {
+		for _, entry := range strings.Split(param, ",") {
+			if entry = strings.TrimSpace(entry); entry != "" {
+				paramList = append(paramList, entry)
+			}
+		}
+	}
+	entries, err := note.CaptureSystemValues(paramList)
+	if err != nil {
+		system.ErrorExit("%v", err)
+	}
+	if len(entries) == 0 {
+		system.ErrorExit("No values found for the parameters '%s'. Note definition file not created.", strings.Join(paramList, ", "))
+	}
+	host, _ := os.Hostname()
+	date := time.Now().Format("02.01.2006")
+	content := note.CapturedNoteContent(noteID, fmt.Sprintf("Parameters captured from %s", host), date, host, entries)
+	if err := chkNoteContent(content); err != nil {
+		system.ErrorExit("Invalid Note definition captured from the system - %v", err)
+	}
+	if err := os.MkdirAll(extraTuningSheets, 0755); err != nil {
+		system.ErrorExit("Problems while creating directory '%s' - %v", extraTuningSheets, err)
+	}
+	if err := ioutil.WriteFile(extraFileName, []byte(content), 0644); err != nil {
+		system.ErrorExit("Problems while writing '%s' - %v", extraFileName, err)
+	}
+	fmt.Fprintf(writer, "Note definition file '%s' created with %d parameters.\nCopy the file to '%s' of other hosts and use 'saptune note apply %s' there.\n", extraFileName, len(entries), extraTuningSheets, noteID)
+}
+
+// chkNoteContent validates the content of a Note definition file
+// the header in the [version] section is needed, all sections need to be
+// supported and at least one parameter needs to be defined
+func chkNoteContent(content string) error {
+	if txtparser.GetINIDescriptiveName(content) == "" {
+		return fmt.Errorf("missing header '# SAP-NOTE=<NoteID> CATEGORY=<category> VERSION=<version> DATE=<date> NAME=\"<description>\"' in section [version]")
+	}
+	ini := txtparser.ParseINI(content)
+	params := 0
+	for section := range ini.KeyValue {
+		if !system.IsStringInList(section, note.INISections) {
+			return fmt.Errorf("unsupported section [%s]", section)
+		}
+	}
+	for _, entry := range ini.AllValues {
+		if entry.Section != note.INISectionVersion && entry.Section != note.INISectionReminder {
+			params = params + 1
+		}
+	}
+	if params == 0 {
+		return fmt.Errorf("no parameters defined")
+	}
+	return nil
+}
+
+// NoteActionCustomiseSet changes the values of Note parameters in the
+// override file without starting an editor
+// 'set' contains entries like 'section.key=value', 'unset' entries like
+// 'section.key'. An empty value marks the parameter as 'untouched', unset
+// removes the parameter from the override file, so the value of the Note
+// definition file is used again.
+func NoteActionCustomiseSet(writer io.Writer, noteID string, set, unset []string, noteTuningSheets, extraTuningSheets, ovTuningSheets string, tuneApp *app.App) {
+	if noteID == "" {
+		PrintHelpAndExit(writer, 1)
+	}
+	if _, err := tuneApp.GetNoteByID(noteID); err != nil {
+		system.ErrorExit("%v", err)
+	}
+	fileName, _ := getFileName(noteID, noteTuningSheets, extraTuningSheets)
+	noteIni, err := txtparser.ParseINIFile(fileName, false)
+	if err != nil {
+		system.ErrorExit("Failed to read file '%s' - %v", fileName, err)
+	}
+	ovFileName, overrideNote := getovFile(noteID, ovTuningSheets)
+	content := ""
+	if overrideNote {
+		cont, err := ioutil.ReadFile(ovFileName)
+		if err != nil {
+			system.ErrorExit("Failed to read file '%s' - %v", ovFileName, err)
+		}
+		content = string(cont)
+	}
+
+	for _, param := range unset {
+		section, key, _, err := splitParamSpec(param, false)
+		if err != nil {
+			system.ErrorExit("%v", err)
+		}
+		found := false
+		if content, found = txtparser.UnsetINIValue(content, section, key); !found {
+			system.ErrorExit("Parameter '%s' not found in section [%s] of the override file of Note %s.", key, section, noteID)
+		}
+	}
+	for _, param := range set {
+		section, key, value, err := splitParamSpec(param, true)
+		if err != nil {
+			system.ErrorExit("%v", err)
+		}
+		if !noteHasParam(noteIni, section, key) {
+			system.ErrorExit("Parameter '%s' is not available in section [%s] of Note %s. Only parameters of the Note can be changed.", key, section, noteID)
+		}
+		lineNo := 0
+		content, lineNo = txtparser.SetINIValue(content, section, key, value)
+		// validate the new setting with the Note definition parser
+		valid := false
+		for _, entry := range txtparser.ParseINI(content).AllValues {
+			if entry.Section == section && entry.Line == lineNo {
+				valid = true
+			}
+		}
+		if !valid {
+			system.ErrorExit("Invalid setting '%s' for section [%s].", param, section)
+		}
+	}
+
+	if overrideNote && len(txtparser.ParseINI(content).AllValues) == 0 {
+		// no customised parameters left
+		if err := os.Remove(ovFileName); err != nil {
+			system.ErrorExit("Problems while removing '%s' - %v", ovFileName, err)
+		}
+		fmt.Fprintf(writer, "Override file '%s' removed, as no customised parameters are left.\n", ovFileName)
+	} else {
+		if err := os.MkdirAll(ovTuningSheets, 0755); err != nil {
+			system.ErrorExit("Problems while creating directory '%s' - %v", ovTuningSheets, err)
+		}
+		if err := ioutil.WriteFile(ovFileName, []byte(content), 0644); err != nil {
+			system.ErrorExit("Problems while writing '%s' - %v", ovFileName, err)
+		}
+		fmt.Fprintf(writer, "Override file '%s' changed.\n", ovFileName)
+	}
+	if _, ok := tuneApp.IsNoteApplied(noteID); ok {
+		fmt.Fprintf(writer, "Note %s is currently applied. Please use 'saptune note refresh %s' to get the changes take effect.\n", noteID, noteID)
+	} else {
+		fmt.Fprintf(writer, "Note %s is not applied. The changes will take effect with the next 'saptune note apply %s'.\n", noteID, noteID)
+	}
+}
+
+// splitParamSpec splits a parameter specification 'section.key=value'
+// (withValue) or 'section.key' into section, key and value
+func splitParamSpec(param string, withValue bool) (string, string, string, error) {
+	value := ""
+	spec := param
+	if withValue {
+		fields := strings.SplitN(param, "=", 2)
+		if len(fields) != 2 {
+			return "", "", "", fmt.Errorf("wrong syntax '%s', expected 'section.key=value'", param)
+		}
+		spec = fields[0]
+		value = strings.TrimSpace(fields[1])
+	}
+	fields := strings.SplitN(strings.TrimSpace(spec), ".", 2)
+	if len(fields) != 2 || fields[0] == "" || fields[1] == "" {
+		if withValue {
+			return "", "", "", fmt.Errorf("wrong syntax '%s', expected 'section.key=value'", param)
+		}
+		return "", "", "", fmt.Errorf("wrong syntax '%s', expected 'section.key'", param)
+	}
+	return fields[0], fields[1], value, nil
+}
+
+// noteHasParam checks, if the parameter is available in the section of the
+// Note definition
+// the keys of the [block] section contain the block device name, the
+// keys of the [service] section the prefix 'systemd:' and the LIMITS
+// definitions of the [limits] section expand to keys for each limit
+func noteHasParam(ini *txtparser.INIFile, section, key string) bool {
+	entries, ok := ini.KeyValue[section]
+	if !ok {
+		return false
+	}
+	if section == note.INISectionLimits && (key == "LIMITS" || key == "SYSTEMD_LIMITS") {
+		// the limits definitions of the Note can be changed as a
+		// whole, the single limits by their LIMIT_* keys
+		return true
+	}
+	for entKey := range entries {
+		if entKey == key || entKey == "systemd:"+key || (section == note.INISectionBlock && strings.HasPrefix(entKey, key+"_")) {
+			return true
+		}
+	}
+	return false
+}
+
 // NoteActionShow shows the content of the Note definition file
 func NoteActionShow(writer io.Writer, noteID, noteTuningSheets, extraTuningSheets string, tuneApp *app.App) {
 	if noteID == "" {
@@ -233,6 +566,260 @@ func NoteActionShow(writer io.Writer, noteID, noteTuningSheets, extraTuningSheet
 	fmt.Fprintf(writer, "\nContent of Note %s:\n%s\n", noteID, string(cont))
 }
 
+// NoteActionShowEffective shows the content of the Note definition file
+// merged with the override file and the override drop-in files together
+// with the file each value comes from
+func NoteActionShowEffective(writer io.Writer, noteID, noteTuningSheets, extraTuningSheets, ovTuningSheets string, tuneApp *app.App) {
+	if noteID == "" {
+		PrintHelpAndExit(writer, 1)
+	}
+	if _, err := tuneApp.GetNoteByID(noteID); err != nil {
+		system.ErrorExit("%v", err)
+	}
+	ini := effectiveNote(noteID, noteTuningSheets, extraTuningSheets, ovTuningSheets)
+
+	fmt.Fprintf(writer, "\nEffective content of Note %s:\n", noteID)
+	section := ""
+	for _, entry := range ini.AllValues {
+		if entry.Section == "reminder" || entry.Section == "version" {
+			continue
+		}
+		if entry.Section != section {
+			section = entry.Section
+			fmt.Fprintf(writer, "\n[%s]\n", section)
+		}
+		value := strings.Replace(entry.Value, "\t", " ", -1)
+		if value == "" {
+			value = "(untouched)"
+		}
+		fmt.Fprintf(writer, "%s %s %s\t# %s\n", entry.Key, entry.Operator, value, entry.File)
+	}
+	fmt.Fprintf(writer, "\n")
+}
+
+// parseNoteDefinition parses the Note definition file of a Note
+func parseNoteDefinition(noteID, noteTuningSheets, extraTuningSheets string) *txtparser.INIFile {
+	fileName, _ := getFileName(noteID, noteTuningSheets, extraTuningSheets)
+	ini, err := txtparser.ParseINIFile(fileName, false)
+	if err != nil {
+		system.ErrorExit("Failed to read file '%s' - %v", fileName, err)
+	}
+	return ini
+}
+
+// effectiveNote returns the Note definition merged with the override file
+// and the override drop-in files of the Note
+func effectiveNote(noteID, noteTuningSheets, extraTuningSheets, ovTuningSheets string) *txtparser.INIFile {
+	fileName, _ := getFileName(noteID, noteTuningSheets, extraTuningSheets)
+	ini, err := note.EffectiveINIFile(fileName, ovTuningSheets, noteID)
+	if err != nil {
+		system.ErrorExit("Failed to read the Note definition or the override files of Note '%s' - %v", noteID, err)
+	}
+	return ini
+}
+
+// noteDiffEntry is a parameter, which differs between two Note definitions,
+// in the JSON output of 'saptune note diff'
+type noteDiffEntry struct {
+	Section   string `json:"section"`
+	Parameter string `json:"parameter"`
+	Left      string `json:"left"`
+	Right     string `json:"right"`
+}
+
+// noteDiff is the JSON output of 'saptune note diff'
+type noteDiff struct {
+	Left        string          `json:"left"`
+	Right       string          `json:"right"`
+	Differences []noteDiffEntry `json:"differences"`
+}
+
+// NoteActionDiff compares the Note definition with the effective settings
+// of the Note (including override file and override drop-in files) or,
+// if a second Note is given, the effective settings of both Notes parameter
+// by parameter. Output format is 'text' (default) or 'json'
+func NoteActionDiff(writer io.Writer, noteID, otherID, format, noteTuningSheets, extraTuningSheets, ovTuningSheets string, tuneApp *app.App) {
+	if noteID == "" {
+		PrintHelpAndExit(writer, 1)
+	}
+	if format != "" && format != "text" && format != "json" {
+		system.ErrorExit("Unsupported output format '%s'. Supported are 'text' and 'json'.", format)
+	}
+	for _, nID := range []string{noteID, otherID} {
+		if nID == "" {
+			continue
+		}
+		if _, err := tuneApp.GetNoteByID(nID); err != nil {
+			system.ErrorExit("%v", err)
+		}
+	}
+	var left, right *txtparser.INIFile
+	leftName := noteID
+	rightName := otherID
+	if otherID == "" {
+		left = parseNoteDefinition(noteID, noteTuningSheets, extraTuningSheets)
+		right = effectiveNote(noteID, noteTuningSheets, extraTuningSheets, ovTuningSheets)
+		leftName = noteID + " (Note definition)"
+		rightName = noteID + " (override)"
+	} else {
+		left = effectiveNote(noteID, noteTuningSheets, extraTuningSheets, ovTuningSheets)
+		right = effectiveNote(otherID, noteTuningSheets, extraTuningSheets, ovTuningSheets)
+	}
+	comparisons := compareNoteDefinitions(left, right, ovTuningSheets)
+
+	if format == "json" {
+		diff := noteDiff{Left: leftName, Right: rightName, Differences: []noteDiffEntry{}}
+		for _, key := range sortStageComparisonsOutput(comparisons) {
+			// key is '[section] parameter'
+			fields := strings.SplitN(strings.TrimPrefix(key, "["), "] ", 2)
+			diff.Differences = append(diff.Differences, noteDiffEntry{Section: fields[0], Parameter: fields[1], Left: comparisons[key].wrkVal, Right: comparisons[key].stgVal})
+		}
+		content, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			system.ErrorExit("Failed to create JSON output - %v", err)
+		}
+		fmt.Fprintf(writer, "%s\n", string(content))
+		return
+	}
+	if len(comparisons) == 0 {
+		fmt.Fprintf(writer, "\nNo differences found between %s and %s.\n\n", leftName, rightName)
+		return
+	}
+	// the header line has to fit into the table too
+	tableRows := map[string]stageComparison{"Parameter": {wrkVal: leftName, stgVal: rightName}}
+	for key, comparison := range comparisons {
+		tableRows[key] = comparison
+	}
+	fmtdash, fmtplus, format := setupStageTableFormat(tableRows)
+	fmt.Fprintf(writer, "\n")
+	fmt.Fprint(writer, fmtdash)
+	fmt.Fprintf(writer, format, "Parameter", leftName, rightName)
+	fmt.Fprint(writer, fmtplus)
+	for _, key := range sortStageComparisonsOutput(comparisons) {
+		fmt.Fprintf(writer, format, key, comparisons[key].wrkVal, comparisons[key].stgVal)
+	}
+	fmt.Fprint(writer, fmtdash)
+	fmt.Fprintf(writer, "\n")
+}
+
+// compareNoteDefinitions compares two Note definitions parameter by
+// parameter and returns the differing parameters with the key
+// '[section] parameter'. The value of the first Note is stored as wrkVal,
+// the value of the second Note as stgVal. Missing parameters are shown as
+// '-', empty values from an override file as 'untouched'
+func compareNoteDefinitions(left, right *txtparser.INIFile, ovTuningSheets string) map[string]stageComparison {
+	comparisons := make(map[string]stageComparison)
+	leftVals := noteDiffValues(left, ovTuningSheets)
+	rightVals := noteDiffValues(right, ovTuningSheets)
+	for key, leftVal := range leftVals {
+		if _, ok := rightVals[key]; !ok {
+			comparisons[key] = stageComparison{FieldName: key, wrkVal: leftVal, stgVal: "-", MatchExpectation: false}
+		}
+	}
+	for key, rightVal := range rightVals {
+		leftVal, ok := leftVals[key]
+		if !ok {
+			comparisons[key] = stageComparison{FieldName: key, wrkVal: "-", stgVal: rightVal, MatchExpectation: false}
+			continue
+		}
+		if _, _, match := note.CompareJSValue(leftVal, rightVal, ""); !match {
+			comparisons[key] = stageComparison{FieldName: key, wrkVal: leftVal, stgVal: rightVal, MatchExpectation: false}
+		}
+	}
+	return comparisons
+}
+
+// noteDiffValues returns the parameter values of a Note definition with
+// the key '[section] parameter' for the comparison of Note definitions
+func noteDiffValues(ini *txtparser.INIFile, ovTuningSheets string) map[string]string {
+	vals := make(map[string]string)
+	for _, entry := range ini.AllValues {
+		if entry.Section == "version" || entry.Section == "reminder" {
+			continue
+		}
+		val := strings.Replace(entry.Value, "\t", " ", -1)
+		if val == "" && ovTuningSheets != "" && strings.HasPrefix(entry.File, ovTuningSheets) {
+			val = "untouched"
+		}
+		vals[fmt.Sprintf("[%s] %s", entry.Section, entry.Key)] = val
+	}
+	return vals
+}
+
+// NoteActionExplain shows the explanations (the comments preceding the
+// parameters) of the Note definition file and of the override file for all
+// parameters or for the given parameter of the Note
+func NoteActionExplain(writer io.Writer, noteID, param, noteTuningSheets, extraTuningSheets, ovTuningSheets string, tuneApp *app.App) {
+	if noteID == "" {
+		PrintHelpAndExit(writer, 1)
+	}
+	if _, err := tuneApp.GetNoteByID(noteID); err != nil {
+		system.ErrorExit("%v", err)
+	}
+	fileName, _ := getFileName(noteID, noteTuningSheets, extraTuningSheets)
+	ini, err := txtparser.ParseINIFile(fileName, false)
+	if err != nil {
+		system.ErrorExit("Failed to read file '%s' - %v", fileName, err)
+	}
+	entries := explainEntries(ini, param)
+	// override file and override drop-in files in the order they are merged
+	for _, ovFileName := range note.GetOverrideFiles(ovTuningSheets, noteID) {
+		ovIni, err := txtparser.ParseINIFile(ovFileName, false)
+		if err != nil {
+			system.ErrorExit("Failed to read file '%s' - %v", ovFileName, err)
+		}
+		origin := " (override)"
+		if path.Dir(ovFileName) == note.OverrideDropInDir(ovTuningSheets, noteID) {
+			origin = fmt.Sprintf(" (override %s)", path.Base(ovFileName))
+		}
+		for _, entry := range explainEntries(ovIni, param) {
+			entry.Section = entry.Section + origin
+			entries = append(entries, entry)
+		}
+	}
+	if param != "" && len(entries) == 0 {
+		system.ErrorExit("Parameter '%s' not found in Note %s.", param, noteID)
+	}
+
+	fmt.Fprintf(writer, "\nExplanation of Note %s:\n", noteID)
+	for _, entry := range entries {
+		fmt.Fprintf(writer, "\n[%s] %s (line %d)\n", entry.Section, entry.Key, entry.Line)
+		if entry.Comment == "" {
+			fmt.Fprintf(writer, "    no explanation available\n")
+			continue
+		}
+		for _, line := range strings.Split(entry.Comment, "\n") {
+			fmt.Fprintf(writer, "    %s\n", line)
+		}
+	}
+	fmt.Fprintf(writer, "\n")
+}
+
+// explainEntries returns the entries of a Note definition file, which match
+// the given parameter (all entries, if parameter is empty)
+// entries originating from the same line of the file (e.g. the block
+// devices of the [block] section or the single limits of the LIMITS
+// parameter) are combined to one entry with the keys separated by ', '
+func explainEntries(ini *txtparser.INIFile, param string) []txtparser.INIEntry {
+	entries := []txtparser.INIEntry{}
+	lines := make(map[int]int)
+	for _, entry := range ini.AllValues {
+		if entry.Section == "reminder" || entry.Section == "version" {
+			continue
+		}
+		if param != "" && entry.Key != param && !(entry.Section == "block" && strings.HasPrefix(entry.Key, param+"_")) {
+			continue
+		}
+		if idx, ok := lines[entry.Line]; ok {
+			entries[idx].Key = entries[idx].Key + ", " + entry.Key
+			continue
+		}
+		lines[entry.Line] = len(entries)
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
 // NoteActionDelete deletes a custom Note definition file and
 // the corresponding override file
 func NoteActionDelete(reader io.Reader, writer io.Writer, noteID, noteTuningSheets, extraTuningSheets, ovTuningSheets string, tuneApp *app.App) {
@@ -245,7 +832,8 @@ func NoteActionDelete(reader io.Reader, writer io.Writer, noteID, noteTuningShee
 
 	txtConfirm := fmt.Sprintf("Do you really want to delete Note (%s)?", noteID)
 	fileName, extraNote := getFileName(noteID, noteTuningSheets, extraTuningSheets)
-	ovFileName, overrideNote := getovFile(noteID, ovTuningSheets)
+	// override file and override drop-in files
+	overrideNote := len(note.GetOverrideFiles(ovTuningSheets, noteID)) != 0
 
 	// check, if note is active - applied
 	if _, ok := tuneApp.IsNoteApplied(noteID); ok {
@@ -271,7 +859,7 @@ func NoteActionDelete(reader io.Reader, writer io.Writer, noteID, noteTuningShee
 	}
 
 	if readYesNo(txtConfirm, reader, writer) {
-		deleteNote(fileName, ovFileName, overrideNote, extraNote)
+		deleteNote(fileName, noteID, ovTuningSheets, overrideNote, extraNote)
 	}
 }
 
@@ -294,8 +882,11 @@ func NoteActionRename(reader io.Reader, writer io.Writer, noteID, newNoteID, not
 	if !extraNote {
 		system.ErrorExit("The Note definition file you want to rename is a saptune internal (shipped) Note and can NOT be renamed. Exiting ...")
 	}
-	ovFileName, overrideNote := getovFile(noteID, ovTuningSheets)
-	newovFileName := fmt.Sprintf("%s%s", ovTuningSheets, newNoteID)
+	// override file and override drop-in files
+	overrideNote := len(note.GetOverrideFiles(ovTuningSheets, noteID)) != 0
+	if len(note.GetOverrideFiles(ovTuningSheets, newNoteID)) != 0 {
+		system.ErrorExit("Override files for the new name '%s' already exist in '%s', can't rename.", newNoteID, ovTuningSheets)
+	}
 
 	// check, if note is active - applied
 	if _, ok := tuneApp.IsNoteApplied(noteID); ok {
@@ -314,7 +905,7 @@ func NoteActionRename(reader io.Reader, writer io.Writer, noteID, newNoteID, not
 	}
 
 	if readYesNo(txtConfirm, reader, writer) {
-		renameNote(fileName, newFileName, ovFileName, newovFileName, overrideNote, extraNote)
+		renameNote(fileName, newFileName, noteID, newNoteID, ovTuningSheets, overrideNote, extraNote)
 	}
 }
 
@@ -336,4 +927,8 @@ func NoteActionEnabled(writer io.Writer, tuneApp *app.App) {
 	if len(tuneApp.NoteApplyOrder) != 0 {
 		fmt.Fprintf(writer, "%s", strings.Join(tuneApp.NoteApplyOrder, " "))
 	}
+	// the output is used by scripts, so report stale notes only as warning
+	for _, staleNote := range tuneApp.GetStaleNotes(OverrideTuningSheets) {
+		system.WarningLog("the definition of note '%s' changed since it was applied. Use 'saptune note verify %s' to show the changed parameters and 'saptune note refresh %s' to get the changes take effect.", staleNote.NoteID, staleNote.NoteID, staleNote.NoteID)
+	}
 }
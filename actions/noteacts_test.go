@@ -6,6 +6,8 @@ import (
 	"github.com/SUSE/saptune/app"
 	"github.com/SUSE/saptune/sap/note"
 	"github.com/SUSE/saptune/system"
+	"github.com/SUSE/saptune/txtparser"
+	"io/ioutil"
 	"os"
 	"path"
 	"strings"
@@ -188,6 +190,120 @@ net.ipv4.ip_local_port_range = 31768 61999
 		checkOut(t, txt, showMatchText)
 	})
 
+	// Test NoteActionShowEffective
+	t.Run("NoteActionShowEffective", func(t *testing.T) {
+		var showMatchText = fmt.Sprintf(`
+Effective content of Note simpleNote:
+
+[sysctl]
+net.ipv4.ip_local_port_range = 31768 61999	# %ssimpleNote.conf
+
+`, ExtraFilesInGOPATH)
+		buffer := bytes.Buffer{}
+		nID := "simpleNote"
+		NoteActionShowEffective(&buffer, nID, "", ExtraFilesInGOPATH, "/not_avail/", tApp)
+		txt := buffer.String()
+		checkOut(t, txt, showMatchText)
+	})
+
+	// Test NoteActionExplain
+	t.Run("NoteActionExplain", func(t *testing.T) {
+		var explainMatchText = `
+Explanation of Note simpleNote:
+
+[sysctl] net.ipv4.ip_local_port_range (line 5)
+    no explanation available
+
+`
+		buffer := bytes.Buffer{}
+		nID := "simpleNote"
+		NoteActionExplain(&buffer, nID, "net.ipv4.ip_local_port_range", "", ExtraFilesInGOPATH, "/not_avail/", tApp)
+		txt := buffer.String()
+		checkOut(t, txt, explainMatchText)
+	})
+
+	// Test NoteActionExplain with override drop-in file
+	t.Run("NoteActionExplainDropIn", func(t *testing.T) {
+		ovDir, err := ioutil.TempDir("", "saptune-explain-")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(ovDir)
+		ovDir = ovDir + "/"
+		nID := "simpleNote"
+		dropInDir := note.OverrideDropInDir(ovDir, nID)
+		if err := os.MkdirAll(dropInDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path.Join(dropInDir, "10-ports.conf"), []byte("[sysctl]\n# more client ports\nnet.ipv4.ip_local_port_range = 9000 65499\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		var explainMatchText = `
+Explanation of Note simpleNote:
+
+[sysctl] net.ipv4.ip_local_port_range (line 5)
+    no explanation available
+
+[sysctl (override 10-ports.conf)] net.ipv4.ip_local_port_range (line 3)
+    more client ports
+
+`
+		buffer := bytes.Buffer{}
+		NoteActionExplain(&buffer, nID, "net.ipv4.ip_local_port_range", "", ExtraFilesInGOPATH, ovDir, tApp)
+		checkOut(t, buffer.String(), explainMatchText)
+	})
+
+	// Test NoteActionDiff
+	t.Run("NoteActionDiff", func(t *testing.T) {
+		ovDir, err := ioutil.TempDir("", "saptune-diff-")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(ovDir)
+		ovDir = ovDir + "/"
+		nID := "simpleNote"
+
+		var noDiffMatchText = `
+No differences found between simpleNote (Note definition) and simpleNote (override).
+
+`
+		buffer := bytes.Buffer{}
+		NoteActionDiff(&buffer, nID, "", "", "", ExtraFilesInGOPATH, ovDir, tApp)
+		checkOut(t, buffer.String(), noDiffMatchText)
+
+		if err := ioutil.WriteFile(ovDir+nID, []byte("[sysctl]\nnet.ipv4.ip_local_port_range = 32768 61999\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		var diffMatchText = `
+---------------------------------------------------------------------------------------------------
+ Parameter                             | simpleNote (Note definition) | simpleNote (override)      
+---------------------------------------+------------------------------+----------------------------
+ [sysctl] net.ipv4.ip_local_port_range | 31768 61999                  | 32768 61999                
+---------------------------------------------------------------------------------------------------
+
+`
+		buffer.Reset()
+		NoteActionDiff(&buffer, nID, "", "text", "", ExtraFilesInGOPATH, ovDir, tApp)
+		checkOut(t, buffer.String(), diffMatchText)
+
+		var jsonMatchText = `{
+  "left": "simpleNote (Note definition)",
+  "right": "simpleNote (override)",
+  "differences": [
+    {
+      "section": "sysctl",
+      "parameter": "net.ipv4.ip_local_port_range",
+      "left": "31768 61999",
+      "right": "32768 61999"
+    }
+  ]
+}
+`
+		buffer.Reset()
+		NoteActionDiff(&buffer, nID, "", "json", "", ExtraFilesInGOPATH, ovDir, tApp)
+		checkOut(t, buffer.String(), jsonMatchText)
+	})
+
 	tearDown(t)
 }
 
@@ -282,6 +398,17 @@ net.ipv4.ip_local_port_range = 31768 61999
 	if err := system.CopyFile(fileName, ovFileName); err != nil {
 		t.Fatalf("copy of %s to %s failed: '%+v'", fileName, ovFileName, err)
 	}
+	// override drop-in file
+	dropInDir := note.OverrideDropInDir(OverTstFilesInGOPATH, nID)
+	newDropInDir := note.OverrideDropInDir(OverTstFilesInGOPATH, newID)
+	if err := os.MkdirAll(dropInDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dropInDir)
+	defer os.RemoveAll(newDropInDir)
+	if err := ioutil.WriteFile(path.Join(dropInDir, "10-ports.conf"), []byte("[sysctl]\nnet.ipv4.ip_local_port_range = 9000 65499\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
 
 	// check note files and show content of test note
 	if _, err := os.Stat(fileName); os.IsNotExist(err) {
@@ -332,6 +459,12 @@ net.ipv4.ip_local_port_range = 31768 61999
 	if _, err := os.Stat(newFileName); os.IsNotExist(err) {
 		t.Errorf("file '%s' does not exist\n", newFileName)
 	}
+	if _, err := os.Stat(dropInDir); !os.IsNotExist(err) {
+		t.Errorf("directory '%s' still exists\n", dropInDir)
+	}
+	if _, err := os.Stat(path.Join(newDropInDir, "10-ports.conf")); err != nil {
+		t.Errorf("drop-in file not renamed - '%v'\n", err)
+	}
 
 	// show content of renamed note
 	// refresh note list (AllNotes) for 'Show'
@@ -365,6 +498,9 @@ net.ipv4.ip_local_port_range = 31768 61999
 	NoteActionDelete(strings.NewReader(input), &deleteBuf, newID, "", ExtraFilesInGOPATH, OverTstFilesInGOPATH, rApp)
 	txt = deleteBuf.String()
 	checkOut(t, txt, deleteMatchText)
+	if _, err := os.Stat(newDropInDir); !os.IsNotExist(err) {
+		t.Errorf("directory '%s' still exists\n", newDropInDir)
+	}
 	if _, err := os.Stat(newFileName); !os.IsNotExist(err) {
 		// as 'note delete' has failed, use system to clean up
 		if err := os.Remove(newFileName); err != nil {
@@ -380,3 +516,172 @@ net.ipv4.ip_local_port_range = 31768 61999
 		t.Errorf("file '%s' still exists\n", newFileName)
 	}
 }
+
+func TestExplainEntries(t *testing.T) {
+	ini := txtparser.ParseINI(`[limits]
+# limits for the SAP users
+LIMITS="@sapsys soft nofile 65536, @sdba soft nofile 65536"
+
+[sysctl]
+vm.swappiness = 10
+`)
+	entries := explainEntries(ini, "")
+	if len(entries) != 2 {
+		t.Fatalf("wrong entries '%+v'", entries)
+	}
+	if entries[0].Key != "LIMIT_@sapsys_soft_nofile, LIMIT_@sdba_soft_nofile" || entries[0].Comment != "limits for the SAP users" || entries[0].Line != 3 {
+		t.Errorf("wrong entry '%+v'", entries[0])
+	}
+	if entries := explainEntries(ini, "vm.swappiness"); len(entries) != 1 || entries[0].Line != 6 {
+		t.Errorf("wrong entries '%+v'", entries)
+	}
+	if entries := explainEntries(ini, "vm.dirty_ratio"); len(entries) != 0 {
+		t.Errorf("wrong entries '%+v'", entries)
+	}
+}
+
+func TestNoteActionCustomiseSet(t *testing.T) {
+	newTuningOpts := note.GetTuningOptions("", ExtraFilesInGOPATH)
+	nApp := app.InitialiseApp(TstFilesInGOPATH, "", newTuningOpts, AllTestSolutions)
+	ovDir := path.Join(os.TempDir(), "saptune_override") + "/"
+	defer os.RemoveAll(ovDir)
+	nID := "simpleNote"
+	ovFile := ovDir + nID
+
+	buffer := bytes.Buffer{}
+	NoteActionCustomiseSet(&buffer, nID, []string{"sysctl.net.ipv4.ip_local_port_range=32768 60999"}, []string{}, "", ExtraFilesInGOPATH, ovDir, nApp)
+	setMatchText := fmt.Sprintf("Override file '%s' changed.\nNote simpleNote is not applied. The changes will take effect with the next 'saptune note apply simpleNote'.\n", ovFile)
+	checkOut(t, buffer.String(), setMatchText)
+	ow, err := note.ParseOverrideFiles(ovDir, nID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val := ow.KeyValue["sysctl"]["net.ipv4.ip_local_port_range"].Value; val != "32768\t60999" {
+		t.Errorf("wrong value '%s'", val)
+	}
+
+	buffer.Reset()
+	NoteActionCustomiseSet(&buffer, nID, []string{}, []string{"sysctl.net.ipv4.ip_local_port_range"}, "", ExtraFilesInGOPATH, ovDir, nApp)
+	unsetMatchText := fmt.Sprintf("Override file '%s' removed, as no customised parameters are left.\nNote simpleNote is not applied. The changes will take effect with the next 'saptune note apply simpleNote'.\n", ovFile)
+	checkOut(t, buffer.String(), unsetMatchText)
+	if _, err := os.Stat(ovFile); !os.IsNotExist(err) {
+		t.Errorf("override file '%s' should not exist", ovFile)
+	}
+}
+
+func TestNoteActionCreateFrom(t *testing.T) {
+	newTuningOpts := note.GetTuningOptions("", ExtraFilesInGOPATH)
+	nApp := app.InitialiseApp(TstFilesInGOPATH, "", newTuningOpts, AllTestSolutions)
+	extraDir := path.Join(os.TempDir(), "saptune_extra") + "/"
+	defer os.RemoveAll(extraDir)
+	nID := "fromStdin"
+	content := `[version]
+# SAP-NOTE=fromStdin CATEGORY=test VERSION=1 DATE=18.10.2026 NAME="Note created from stdin"
+
+[sysctl]
+vm.swappiness = 10
+`
+	buffer := bytes.Buffer{}
+	NoteActionCreateFrom(strings.NewReader(content), &buffer, nID, "-", "", extraDir, nApp)
+	createMatchText := fmt.Sprintf("Note definition file '%sfromStdin.conf' created.\nUse 'saptune note apply fromStdin' to get the Note take effect.\n", extraDir)
+	checkOut(t, buffer.String(), createMatchText)
+	if cont, err := ioutil.ReadFile(extraDir + nID + ".conf"); err != nil || string(cont) != content {
+		t.Errorf("wrong content '%s' - %v", string(cont), err)
+	}
+}
+
+func TestChkNoteContent(t *testing.T) {
+	header := "[version]\n# SAP-NOTE=4711 CATEGORY=test VERSION=1 DATE=18.10.2026 NAME=\"test\"\n"
+	if err := chkNoteContent(header + "[sysctl]\nvm.swappiness = 10\n"); err != nil {
+		t.Error(err)
+	}
+	for _, content := range []string{"[sysctl]\nvm.swappiness = 10\n", header, header + "[unknown]\nkey = value\n"} {
+		if err := chkNoteContent(content); err == nil {
+			t.Errorf("content '%s' should be invalid", content)
+		}
+	}
+}
+
+func TestSplitParamSpec(t *testing.T) {
+	section, key, value, err := splitParamSpec("sysctl.vm.swappiness=10", true)
+	if err != nil || section != "sysctl" || key != "vm.swappiness" || value != "10" {
+		t.Errorf("wrong result '%s', '%s', '%s', '%v'", section, key, value, err)
+	}
+	section, key, value, err = splitParamSpec("vm.THP", false)
+	if err != nil || section != "vm" || key != "THP" || value != "" {
+		t.Errorf("wrong result '%s', '%s', '%s', '%v'", section, key, value, err)
+	}
+	for _, param := range []string{"sysctl=10", "swappiness", ".swappiness=1"} {
+		if _, _, _, err := splitParamSpec(param, true); err == nil {
+			t.Errorf("'%s' should be invalid", param)
+		}
+	}
+}
+
+func TestNoteHasParam(t *testing.T) {
+	ini := txtparser.ParseINI(`[block]
+IO_SCHEDULER = noop
+[service]
+uuidd.socket = start
+[sysctl]
+vm.swappiness = 10
+[limits]
+LIMITS="@sapsys soft nofile 65536"
+`)
+	for _, param := range [][]string{{"sysctl", "vm.swappiness"}, {"service", "uuidd.socket"}, {"block", "IO_SCHEDULER"}, {"limits", "LIMITS"}, {"limits", "SYSTEMD_LIMITS"}, {"limits", "LIMIT_@sapsys_soft_nofile"}} {
+		if !noteHasParam(ini, param[0], param[1]) {
+			t.Errorf("parameter '%v' should be available", param)
+		}
+	}
+	for _, param := range [][]string{{"sysctl", "vm.dirty_ratio"}, {"vm", "THP"}, {"limits", "foo"}, {"limits", "LIMIT"}, {"limits", "LIMIT_@sdba_soft_nofile"}} {
+		if noteHasParam(ini, param[0], param[1]) {
+			t.Errorf("parameter '%v' should not be available", param)
+		}
+	}
+}
+
+func TestNoteActionCreateFromSystem(t *testing.T) {
+	newTuningOpts := note.GetTuningOptions("", ExtraFilesInGOPATH)
+	nApp := app.InitialiseApp(TstFilesInGOPATH, "", newTuningOpts, AllTestSolutions)
+	extraDir := path.Join(os.TempDir(), "saptune_extra") + "/"
+	defer os.RemoveAll(extraDir)
+	nID := "fromSystem"
+	buffer := bytes.Buffer{}
+	NoteActionCreateFromSystem(&buffer, nID, []string{"sysctl.vm.max_map_count, sysctl.vm.max_map_count"}, "", extraDir, nApp)
+	createMatchText := fmt.Sprintf("Note definition file '%sfromSystem.conf' created with 1 parameters.\nCopy the file to '%s' of other hosts and use 'saptune note apply fromSystem' there.\n", extraDir, extraDir)
+	checkOut(t, buffer.String(), createMatchText)
+	ini, err := txtparser.ParseINIFile(extraDir+nID+".conf", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ini.KeyValue["sysctl"]["vm.max_map_count"]; !ok {
+		t.Errorf("missing parameter '%+v'", ini.KeyValue)
+	}
+}
+
+func TestCompareNoteDefinitions(t *testing.T) {
+	left := txtparser.ParseINI("[version]\n# SAP-NOTE=a VERSION=1\n\n[sysctl]\nvm.swappiness = 10\nkernel.shmmni = 4096\nvm.dirty_ratio = 10\n\n[mem]\nVSZ_TMPFS_PERCENT = 75\n")
+	right := txtparser.ParseINI("[version]\n# SAP-NOTE=b VERSION=2\n\n[sysctl]\nvm.swappiness = 10\nkernel.shmmni = 8192\nvm.max_map_count = 2147483647\n\n[mem]\nVSZ_TMPFS_PERCENT =\n")
+	right.AllValues[len(right.AllValues)-1].File = "/etc/saptune/override/b"
+
+	exp := map[string][]string{
+		"[sysctl] kernel.shmmni":    {"4096", "8192"},
+		"[sysctl] vm.dirty_ratio":   {"10", "-"},
+		"[sysctl] vm.max_map_count": {"-", "2147483647"},
+		"[mem] VSZ_TMPFS_PERCENT":   {"75", "untouched"},
+	}
+	comparisons := compareNoteDefinitions(left, right, "/etc/saptune/override/")
+	if len(comparisons) != len(exp) {
+		t.Errorf("expected '%d' differences, got '%d': %+v\n", len(exp), len(comparisons), comparisons)
+	}
+	for key, vals := range exp {
+		comp, ok := comparisons[key]
+		if !ok {
+			t.Errorf("missing difference for '%s'\n", key)
+			continue
+		}
+		if comp.wrkVal != vals[0] || comp.stgVal != vals[1] {
+			t.Errorf("'%s': got '%s'/'%s', expected '%s'/'%s'\n", key, comp.wrkVal, comp.stgVal, vals[0], vals[1])
+		}
+	}
+}
@@ -80,15 +80,21 @@ func ServiceActionStart(enableService bool, tuneApp *app.App) {
 func ServiceActionApply(tuneApp *app.App) {
 	// service should fail, if sapconf.service is enabled or has exited
 	// but 'active' file is available
-	// /var/lib/sapconf/act_profile in sle12
-	// /var/run/sapconf/active in sle15
-	if system.SystemctlIsEnabled(SapconfService) || system.CmdIsAvailable("/var/lib/sapconf/act_profile") || system.CmdIsAvailable("/var/run/sapconf/active") {
+	if sapconfIsActive() {
 		system.ErrorExit("ATTENTION: found an active sapconf, so refuse any action")
 	}
 	system.InfoLog("saptune is now tuning the system...")
 	if err := tuneApp.TuneAll(); err != nil {
 		system.ErrorExit("%v", err)
 	}
+	// offer the migration of a deprecated solution
+	if solName, successor := getDeprecatedEnabledSolution(tuneApp); solName != "" {
+		if successor != "" {
+			system.WarningLog("The enabled solution '%s' is deprecated. Please run 'saptune solution migrate' to move to its successor '%s'.", solName, successor)
+		} else {
+			system.WarningLog("The enabled solution '%s' is deprecated. Please use 'saptune solution change' to switch to another solution.", solName)
+		}
+	}
 }
 
 // ServiceActionEnable enables the saptune service
@@ -149,6 +155,8 @@ func ServiceActionStatus(writer io.Writer, tuneApp *app.App, saptuneVersion stri
 		}
 		// list order of enabled notes
 		tuneApp.PrintNoteApplyOrder(writer)
+		// report notes changed since apply
+		printStaleNotes(writer, nil, tuneApp)
 	} else {
 		system.ErrorLog("Your system has not yet been tuned. Please visit `saptune note` and `saptune solution` to start tuning.")
 		system.ErrorExit("", exitNotTuned)
@@ -184,9 +192,7 @@ func ServiceActionStop(disableService bool) {
 func ServiceActionRevert(tuneApp *app.App) {
 	// service should fail, if sapconf.service is enabled or has exited
 	// but 'active' file is available
-	// /var/lib/sapconf/act_profile in sle12
-	// /var/run/sapconf/active in sle15
-	if system.SystemctlIsEnabled(SapconfService) || system.CmdIsAvailable("/var/lib/sapconf/act_profile") || system.CmdIsAvailable("/var/run/sapconf/active") {
+	if sapconfIsActive() {
 		system.ErrorExit("ATTENTION: found an active sapconf, so refuse any action")
 	}
 	system.InfoLog("saptune is now reverting all settings...")
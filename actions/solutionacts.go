@@ -3,11 +3,13 @@ package actions
 import (
 	"fmt"
 	"github.com/SUSE/saptune/app"
+	"github.com/SUSE/saptune/sap/note"
 	"github.com/SUSE/saptune/sap/solution"
 	"github.com/SUSE/saptune/system"
 	"io"
 	"os"
 	"sort"
+	"strings"
 )
 
 // SolutionAction  Solution actions like apply, revert, verify asm.
@@ -18,11 +20,19 @@ func SolutionAction(actionName, solName string, tuneApp *app.App) {
 	case "list":
 		SolutionActionList(os.Stdout, tuneApp)
 	case "verify":
+		// compare the limits with the ones of the running processes
+		note.RuntimeLimitsCheck = system.IsFlagSet("runtime")
 		SolutionActionVerify(os.Stdout, solName, tuneApp)
 	case "simulate":
 		SolutionActionSimulate(os.Stdout, solName, tuneApp)
 	case "revert":
 		SolutionActionRevert(os.Stdout, solName, tuneApp)
+	case "change":
+		SolutionActionChange(os.Stdout, solName, tuneApp)
+	case "migrate":
+		SolutionActionMigrate(os.Stdout, tuneApp)
+	case "suggest":
+		SolutionActionSuggest(os.Stdout, system.GetSAPComponents(), system.IsFlagSet("apply"), tuneApp)
 	case "enabled":
 		SolutionActionEnabled(os.Stdout, tuneApp)
 	default:
@@ -53,6 +63,108 @@ func SolutionActionApply(writer io.Writer, solName string, tuneApp *app.App) {
 			fmt.Fprintf(writer, "\t%s\t%s\n", noteNumber, tuneApp.AllNotes[noteNumber].Name())
 		}
 	}
+	printParameterConflicts(writer, tuneApp.AllSolutions[solName], tuneApp)
+	rememberMessage(writer)
+}
+
+// SolutionActionChange switches from the enabled solution to another
+// solution. Notes shared by both solutions stay untouched, only the notes
+// leaving the set are reverted and only the new notes are applied
+func SolutionActionChange(writer io.Writer, solName string, tuneApp *app.App) {
+	if solName == "" {
+		PrintHelpAndExit(writer, 1)
+	}
+	oldSolName := strings.Join(tuneApp.TuneForSolutions, " ")
+	kept, reverted, applied, removedAdditionalNotes, err := tuneApp.ChangeSolution(solName)
+	if err != nil {
+		system.ErrorExit("Failed to change the solution to %s: %v", solName, err)
+	}
+	fmt.Fprintf(writer, "The solution has been changed successfully from '%s' to '%s'.\n", oldSolName, solName)
+	printSolutionChange(writer, solName, kept, reverted, applied, removedAdditionalNotes, tuneApp)
+}
+
+// SolutionActionMigrate moves an enabled deprecated solution to the
+// successor named in the deprecated solution definition. Like 'change'
+// only the difference of the note sets is reverted and applied
+func SolutionActionMigrate(writer io.Writer, tuneApp *app.App) {
+	oldSolName, successor := getDeprecatedEnabledSolution(tuneApp)
+	if oldSolName == "" {
+		fmt.Fprintf(writer, "No deprecated solution enabled, nothing to migrate.\n")
+		return
+	}
+	if successor == "" {
+		system.ErrorExit("The enabled solution '%s' is deprecated, but no successor is defined in '%s'. Please use 'saptune solution change' to switch to another solution.", oldSolName, solution.DeprecSolutionSheet)
+	}
+	kept, reverted, applied, removedAdditionalNotes, err := tuneApp.ChangeSolution(successor)
+	if err != nil {
+		system.ErrorExit("Failed to migrate the deprecated solution %s to %s: %v", oldSolName, successor, err)
+	}
+	fmt.Fprintf(writer, "The deprecated solution '%s' has been migrated successfully to '%s'.\n", oldSolName, successor)
+	printSolutionChange(writer, successor, kept, reverted, applied, removedAdditionalNotes, tuneApp)
+}
+
+// SolutionActionSuggest shows the SAP components found on the system and
+// the solution recommended for them. If 'apply' is set, the recommended
+// solution will be applied
+func SolutionActionSuggest(writer io.Writer, comps []system.SAPComponent, apply bool, tuneApp *app.App) {
+	if len(comps) == 0 {
+		fmt.Fprintf(writer, "No SAP components found on the system. Please choose a solution from 'saptune solution list'.\n")
+		return
+	}
+	fmt.Fprintf(writer, "Found SAP components:\n")
+	for _, comp := range comps {
+		fmt.Fprintf(writer, "\t%-10s %s %-8s (%s)\n", comp.Type, comp.SID, comp.Instance, comp.Source)
+	}
+	solName, reasons := solution.SuggestSolution(system.GetSolutionSelector(), comps)
+	fmt.Fprintf(writer, "\n")
+	for _, reason := range reasons {
+		fmt.Fprintf(writer, "%s\n", reason)
+	}
+	if solName == "" {
+		fmt.Fprintf(writer, "\nNo matching solution found. Please choose a solution from 'saptune solution list'.\n")
+		return
+	}
+	fmt.Fprintf(writer, "\nSuggested solution: %s\n", solName)
+	if system.IsStringInList(solName, tuneApp.TuneForSolutions) {
+		fmt.Fprintf(writer, "The suggested solution is already enabled.\n")
+		return
+	}
+	if !apply {
+		fmt.Fprintf(writer, "Run 'saptune solution suggest --apply' or 'saptune solution apply %s' to apply the suggested solution.\n", solName)
+		return
+	}
+	if len(tuneApp.TuneForSolutions) != 0 {
+		system.ErrorExit("There is already one solution applied. Applying another solution is NOT supported. Use 'saptune solution change %s' to switch to the suggested solution.", solName)
+	}
+	fmt.Fprintf(writer, "\n")
+	SolutionActionApply(writer, solName, tuneApp)
+}
+
+// getDeprecatedEnabledSolution returns the first enabled solution, which is
+// deprecated, together with its successor
+func getDeprecatedEnabledSolution(tuneApp *app.App) (string, string) {
+	solutionSelector := system.GetSolutionSelector()
+	for _, solName := range tuneApp.TuneForSolutions {
+		if _, ok := solution.DeprecSolutions[solutionSelector][solName]; ok {
+			return solName, solution.GetDeprecatedSuccessor(solutionSelector, solName)
+		}
+	}
+	return "", ""
+}
+
+// printSolutionChange prints the notes kept, reverted and applied while
+// changing or migrating the solution
+func printSolutionChange(writer io.Writer, solName string, kept, reverted, applied, removedAdditionalNotes []string, tuneApp *app.App) {
+	fmt.Fprintf(writer, "\tnotes kept untouched:\t%s\n", strings.Join(kept, " "))
+	fmt.Fprintf(writer, "\tnotes reverted:\t\t%s\n", strings.Join(reverted, " "))
+	fmt.Fprintf(writer, "\tnotes applied:\t\t%s\n", strings.Join(applied, " "))
+	if len(removedAdditionalNotes) > 0 {
+		fmt.Fprintf(writer, "\nThe following previously-enabled notes are now tuned by the SAP solution:\n")
+		for _, noteNumber := range removedAdditionalNotes {
+			fmt.Fprintf(writer, "\t%s\t%s\n", noteNumber, tuneApp.AllNotes[noteNumber].Name())
+		}
+	}
+	printParameterConflicts(writer, tuneApp.AllSolutions[solName], tuneApp)
 	rememberMessage(writer)
 }
 
@@ -60,7 +172,7 @@ func SolutionActionApply(writer io.Writer, solName string, tuneApp *app.App) {
 func SolutionActionList(writer io.Writer, tuneApp *app.App) {
 	setColor := false
 	solutionSelector := system.GetSolutionSelector()
-	fmt.Fprintf(writer, "\nAll solutions (* denotes enabled solution, O denotes override file exists for solution, D denotes deprecated solutions):\n")
+	fmt.Fprintf(writer, "\nAll solutions (* denotes enabled solution, O denotes override file exists for solution, D denotes deprecated solutions, C denotes custom solutions):\n")
 	for _, solName := range solution.GetSortedSolutionNames(solutionSelector) {
 		format := "\t%-18s -"
 		if i := sort.SearchStrings(tuneApp.TuneForSolutions, solName); i < len(tuneApp.TuneForSolutions) && tuneApp.TuneForSolutions[i] == solName {
@@ -76,9 +188,16 @@ func SolutionActionList(writer io.Writer, tuneApp *app.App) {
 		for _, noteString := range solution.AllSolutions[solutionSelector][solName] {
 			solNotes = solNotes + " " + noteString
 		}
+		for _, noteString := range solution.SolutionInfos[solutionSelector][solName].OptionalNotes {
+			// optional notes are enclosed in brackets
+			solNotes = solNotes + " [" + noteString + "]"
+		}
 		if _, ok := solution.DeprecSolutions[solutionSelector][solName]; ok {
 			format = " D" + format
 		}
+		if solution.IsCustomSolution(solutionSelector, solName) {
+			format = " C" + format
+		}
 		format = format + solNotes
 		if setColor {
 			format = format + resetTextColor
@@ -86,6 +205,9 @@ func SolutionActionList(writer io.Writer, tuneApp *app.App) {
 		format = format + "\n"
 		//fmt.Printf(format, solName)
 		fmt.Fprintf(writer, format, solName)
+		if info := solutionInfoText(solutionSelector, solName); info != "" {
+			fmt.Fprintf(writer, "\t%-21s%s\n", "", info)
+		}
 	}
 	rememberMessage(writer)
 }
@@ -101,7 +223,10 @@ func SolutionActionVerify(writer io.Writer, solName string, tuneApp *app.App) {
 		if err != nil {
 			system.ErrorExit("Failed to test the current system against the specified SAP solution: %v", err)
 		}
+		printSolutionInfo(writer, solName, tuneApp)
 		PrintNoteFields(writer, "NONE", comparisons, true)
+		printParameterConflicts(writer, tuneApp.AllSolutions[solName], tuneApp)
+		printStaleNotes(writer, tuneApp.AllSolutions[solName], tuneApp)
 		if len(unsatisfiedNotes) == 0 {
 			fmt.Fprintf(writer, "The system fully conforms to the tuning guidelines of the specified SAP solution.\n")
 		} else {
@@ -110,6 +235,53 @@ func SolutionActionVerify(writer io.Writer, solName string, tuneApp *app.App) {
 	}
 }
 
+// solutionInfoText returns the description, the version and - for deprecated
+// solutions - the successor of a solution as a single line
+func solutionInfoText(solutionSelector, solName string) string {
+	info := solution.SolutionInfos[solutionSelector][solName]
+	text := info.Description
+	if info.Version != "" {
+		text = strings.TrimSpace(fmt.Sprintf("%s (version %s)", text, info.Version))
+	}
+	if successor := solution.GetDeprecatedSuccessor(solutionSelector, solName); successor != "" {
+		if text != "" {
+			text = text + ", "
+		}
+		text = text + fmt.Sprintf("deprecated, please use solution '%s' instead", successor)
+	}
+	return text
+}
+
+// printSolutionInfo prints the metadata of a solution - description, version,
+// included solutions and optional notes - in front of the verify output
+func printSolutionInfo(writer io.Writer, solName string, tuneApp *app.App) {
+	solutionSelector := system.GetSolutionSelector()
+	info := solution.SolutionInfos[solutionSelector][solName]
+	text := solutionInfoText(solutionSelector, solName)
+	if text == "" && len(info.Includes) == 0 && len(info.OptionalNotes) == 0 {
+		return
+	}
+	fmt.Fprintf(writer, "Solution %s", solName)
+	if text != "" {
+		fmt.Fprintf(writer, " - %s", text)
+	}
+	fmt.Fprintf(writer, "\n")
+	if len(info.Includes) != 0 {
+		fmt.Fprintf(writer, "\tincludes solutions:\t%s\n", strings.Join(info.Includes, " "))
+	}
+	if len(info.OptionalNotes) != 0 {
+		fmt.Fprintf(writer, "\toptional notes (not part of the verification):\n")
+		for _, noteID := range info.OptionalNotes {
+			state := "not applied"
+			if _, ok := tuneApp.IsNoteApplied(noteID); ok {
+				state = "applied"
+			}
+			fmt.Fprintf(writer, "\t\t%s\t%s\n", noteID, state)
+		}
+	}
+	fmt.Fprintf(writer, "\n")
+}
+
 // SolutionActionSimulate shows all changes that will be applied to the system if
 // the solution will be applied.
 func SolutionActionSimulate(writer io.Writer, solName string, tuneApp *app.App) {
@@ -2,6 +2,9 @@ package actions
 
 import (
 	"bytes"
+	"github.com/SUSE/saptune/app"
+	"github.com/SUSE/saptune/sap/solution"
+	"github.com/SUSE/saptune/system"
 	"testing"
 )
 
@@ -11,7 +14,7 @@ func TestSolutionActions(t *testing.T) {
 	// Test SolutionActionList
 	t.Run("SolutionActionList", func(t *testing.T) {
 		var listMatchText = `
-All solutions (* denotes enabled solution, O denotes override file exists for solution, D denotes deprecated solutions):
+All solutions (* denotes enabled solution, O denotes override file exists for solution, D denotes deprecated solutions, C denotes custom solutions):
 	BWA                - 941735 2534844 SAP_BWA
 	HANA               - 941735 1771258 1980196 1984787 2205917 2382421 2534844
 	NETW               - 941735 1771258 1980196 1984787 2534844
@@ -109,3 +112,96 @@ The system fully conforms to the tuning guidelines of the specified SAP solution
 
 	tearDown(t)
 }
+
+func TestPrintSolutionInfo(t *testing.T) {
+	solutionSelector := system.GetSolutionSelector()
+	oldSolutionInfos := solution.SolutionInfos
+	oldDeprecSolutions := solution.DeprecSolutions
+	defer func() {
+		solution.SolutionInfos = oldSolutionInfos
+		solution.DeprecSolutions = oldDeprecSolutions
+	}()
+	solution.SolutionInfos = map[string]map[string]solution.SolutionInfo{
+		solutionSelector: {"sol12": {Description: "both sample notes", Version: "2", OptionalNotes: []string{"2578899"}, Includes: []string{"sol1"}}},
+	}
+	solution.DeprecSolutions = map[string]map[string]string{solutionSelector: {"sol12": "deprecated\tsol2"}}
+
+	infoMatchText := `Solution sol12 - both sample notes (version 2), deprecated, please use solution 'sol2' instead
+	includes solutions:	sol1
+	optional notes (not part of the verification):
+		2578899	not applied
+
+`
+	buffer := bytes.Buffer{}
+	printSolutionInfo(&buffer, "sol12", tApp)
+	checkOut(t, buffer.String(), infoMatchText)
+
+	// no metadata available
+	buffer.Reset()
+	printSolutionInfo(&buffer, "sol1", tApp)
+	checkOut(t, buffer.String(), "")
+}
+
+func TestGetDeprecatedEnabledSolution(t *testing.T) {
+	solutionSelector := system.GetSolutionSelector()
+	oldDeprecSolutions := solution.DeprecSolutions
+	defer func() { solution.DeprecSolutions = oldDeprecSolutions }()
+	solution.DeprecSolutions = map[string]map[string]string{solutionSelector: {"sol1": "deprecated\tsol12", "sol2": "deprecated"}}
+
+	depApp := &app.App{TuneForSolutions: []string{"sol1"}}
+	if solName, successor := getDeprecatedEnabledSolution(depApp); solName != "sol1" || successor != "sol12" {
+		t.Errorf("got '%s', '%s'", solName, successor)
+	}
+	depApp.TuneForSolutions = []string{"sol2"}
+	if solName, successor := getDeprecatedEnabledSolution(depApp); solName != "sol2" || successor != "" {
+		t.Errorf("got '%s', '%s'", solName, successor)
+	}
+	depApp.TuneForSolutions = []string{"sol12"}
+	if solName, successor := getDeprecatedEnabledSolution(depApp); solName != "" || successor != "" {
+		t.Errorf("got '%s', '%s'", solName, successor)
+	}
+
+	// nothing to migrate
+	buffer := bytes.Buffer{}
+	SolutionActionMigrate(&buffer, depApp)
+	checkOut(t, buffer.String(), "No deprecated solution enabled, nothing to migrate.\n")
+}
+
+func TestSolutionActionSuggest(t *testing.T) {
+	solutionSelector := system.GetSolutionSelector()
+	oldAllSolutions := solution.AllSolutions
+	defer func() { solution.AllSolutions = oldAllSolutions }()
+	solution.AllSolutions = map[string]map[string]solution.Solution{solutionSelector: {"HANA": {"simpleNote"}}}
+	sugApp := &app.App{TuneForSolutions: []string{}}
+
+	// no SAP components
+	buffer := bytes.Buffer{}
+	SolutionActionSuggest(&buffer, []system.SAPComponent{}, false, sugApp)
+	checkOut(t, buffer.String(), "No SAP components found on the system. Please choose a solution from 'saptune solution list'.\n")
+
+	comps := []system.SAPComponent{{Type: system.SAPCompHANA, SID: "HA0", Instance: "HDB00", Source: "/usr/sap/sapservices"}}
+	suggestMatchText := `Found SAP components:
+	HANA       HA0 HDB00    (/usr/sap/sapservices)
+
+solution 'HANA' matches the found SAP components 'HANA'
+
+Suggested solution: HANA
+Run 'saptune solution suggest --apply' or 'saptune solution apply HANA' to apply the suggested solution.
+`
+	buffer.Reset()
+	SolutionActionSuggest(&buffer, comps, false, sugApp)
+	checkOut(t, buffer.String(), suggestMatchText)
+
+	// no matching solution
+	suggestMatchText = `Found SAP components:
+	ASE        AS1          (/sybase/AS1/ASE-16_0)
+
+solution 'SAP-ASE' matches the found SAP components 'ASE'
+solution 'SAP-ASE' is not available on this system
+
+No matching solution found. Please choose a solution from 'saptune solution list'.
+`
+	buffer.Reset()
+	SolutionActionSuggest(&buffer, []system.SAPComponent{{Type: system.SAPCompASE, SID: "AS1", Source: "/sybase/AS1/ASE-16_0"}}, false, sugApp)
+	checkOut(t, buffer.String(), suggestMatchText)
+}
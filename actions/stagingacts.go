@@ -468,9 +468,9 @@ func collectStageFileInfo(tuneApp *app.App) stageFiles {
 				stageMap["updated"] = "false"
 			}
 		}
-		// check for override file
+		// check for override file or override drop-in files
 		stageMap["override"] = "false"
-		if _, override := getovFile(stageName, OverrideTuningSheets); override {
+		if len(note.GetOverrideFiles(OverrideTuningSheets, stageName)) != 0 {
 			stageMap["override"] = "true"
 		}
 		// check if applied
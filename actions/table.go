@@ -21,7 +21,7 @@ func PrintNoteFields(writer io.Writer, header string, noteComparisons map[string
 	compliant := "yes"
 	printHead := ""
 	noteField := ""
-	footnote := make([]string, 8, 8)
+	footnote := make([]string, 14, 14)
 	reminder := make(map[string]string)
 	override := ""
 	comment := ""
@@ -77,6 +77,12 @@ func PrintNoteFields(writer io.Writer, header string, noteComparisons map[string
 		// prepare footnote
 		compliant, comment, footnote = prepareFootnote(comparison, compliant, comment, inform, footnote)
 
+		// show value expression together with the resolved value
+		comparison.ExpectedValueJS = expValueWithFormula(noteComparisons[noteID], comparison)
+		// show the limits of the running processes together with the
+		// configured limits
+		comparison.ActualValueJS = actValueWithRuntime(noteComparisons[noteID], comparison)
+
 		// print table header
 		if printHead != "" {
 			printHeadline(writer, header, noteID, tuningOptions)
@@ -93,7 +99,12 @@ func PrintNoteFields(writer io.Writer, header string, noteComparisons map[string
 		}
 	}
 	// print footer
-	printTableFooter(writer, header, footnote, reminder, hasDiff)
+	sectionTags := make(map[string]map[string]string)
+	if printComparison {
+		// verify
+		sectionTags = collectSectionTags(noteComparisons)
+	}
+	printTableFooter(writer, header, footnote, reminder, sectionTags, hasDiff)
 }
 
 // sortNoteComparisonsOutput sorts the output of the Note comparison
@@ -104,8 +115,9 @@ func sortNoteComparisonsOutput(noteCompare map[string]map[string]note.FieldCompa
 	// sort output
 	for noteID, comparisons := range noteCompare {
 		for _, comparison := range comparisons {
-			if comparison.ReflectFieldName == "Inform" {
-				// skip inform map to avoid double entries in verify table
+			if comparison.ReflectFieldName == "Inform" || comparison.ReflectFieldName == "Formulas" || comparison.ReflectFieldName == "SectionTags" {
+				// skip inform, formulas and section tags map to
+				// avoid double entries in verify table
 				continue
 			}
 			if len(comparison.ReflectMapKey) != 0 && comparison.ReflectFieldName != "OverrideParams" {
@@ -149,9 +161,11 @@ func setupTableFormat(skeys []string, noteField string, noteCompare map[string]m
 		noteID := keyFields[0]
 		comparisons := noteCompare[noteID]
 		for _, comparison := range comparisons {
-			if comparison.ReflectMapKey == "reminder" {
+			if comparison.ReflectMapKey == "reminder" || comparison.ReflectFieldName == "Formulas" || comparison.ReflectFieldName == "SectionTags" {
 				continue
 			}
+			comparison.ExpectedValueJS = expValueWithFormula(comparisons, comparison)
+			comparison.ActualValueJS = actValueWithRuntime(comparisons, comparison)
 			if printComp {
 				// verify
 				if len(noteField) > fmtlen0 {
@@ -222,11 +236,25 @@ func prepareFootnote(comparison note.FieldComparison, compliant, comment, inform
 		comment = comment + " [1]"
 		footnote[0] = footnote1
 	case "NA":
+		if strings.HasPrefix(comparison.ReflectMapKey, "fs:") && strings.HasSuffix(comparison.ReflectMapKey, ":fstab") && !note.IsFsGlobKey(comparison.ReflectMapKey) {
+			// explicitly named mount point is missing in /etc/fstab
+			compliant = compliant + " [14]"
+			comment = comment + " [14]"
+			footnote[13] = footnote14
+			break
+		}
+		if strings.HasPrefix(comparison.ReflectMapKey, "fs:") && !note.IsFsGlobKey(comparison.ReflectMapKey) {
+			// explicitly named mount point is missing
+			compliant = compliant + " [13]"
+			comment = comment + " [13]"
+			footnote[12] = footnote13
+			break
+		}
 		compliant = compliant + " [2]"
 		comment = comment + " [2]"
 		footnote[1] = footnote2
 	}
-	if strings.Contains(comparison.ReflectMapKey, "rpm") || strings.Contains(comparison.ReflectMapKey, "grub") {
+	if strings.Contains(comparison.ReflectMapKey, "rpm") || strings.Contains(comparison.ReflectMapKey, "grub") || (strings.HasPrefix(comparison.ReflectMapKey, "fs:") && !strings.HasSuffix(comparison.ReflectMapKey, ":remount")) {
 		compliant = compliant + " [3]"
 		comment = comment + " [3]"
 		footnote[2] = footnote3
@@ -260,13 +288,33 @@ func prepareFootnote(comparison note.FieldComparison, compliant, comment, inform
 		comment = comment + " [8]"
 		footnote[7] = footnote8
 	}
+	if strings.HasPrefix(comparison.ReflectMapKey, "module:") && inform == "reboot" {
+		compliant = compliant + " [9]"
+		comment = comment + " [9]"
+		footnote[8] = footnote9
+	}
+	if strings.HasPrefix(comparison.ReflectMapKey, "hardware:") || strings.HasPrefix(comparison.ReflectMapKey, "os:") {
+		compliant = compliant + " [10]"
+		comment = comment + " [10]"
+		footnote[9] = footnote10
+	}
+	if strings.HasPrefix(comparison.ReflectMapKey, "systemd-limits:") && inform == "pamDiffers" {
+		compliant = compliant + " [11]"
+		comment = comment + " [11]"
+		footnote[10] = footnote11
+	}
+	if strings.HasPrefix(comparison.ReflectMapKey, "LIMIT_") && strings.HasPrefix(inform, "restart:") {
+		compliant = compliant + " [12]"
+		comment = comment + " [12]"
+		footnote[11] = footnote12
+	}
 
 	return compliant, comment, footnote
 }
 
 // printTableFooter prints the footer of the table
 // footnotes and reminder section
-func printTableFooter(writer io.Writer, header string, footnote []string, reminder map[string]string, hasDiff bool) {
+func printTableFooter(writer io.Writer, header string, footnote []string, reminder map[string]string, sectionTags map[string]map[string]string, hasDiff bool) {
 	if header != "NONE" && !hasDiff {
 		fmt.Fprintf(writer, "\n   (no change)\n")
 	}
@@ -276,6 +324,7 @@ func printTableFooter(writer io.Writer, header string, footnote []string, remind
 		}
 	}
 	fmt.Fprintf(writer, "\n\n")
+	printSectionTags(writer, sectionTags)
 	for noteID, reminde := range reminder {
 		if reminde != "" {
 			reminderHead := fmt.Sprintf("Attention for SAP Note %s:\nHints or values not yet handled by saptune. So please read carefully, check and set manually, if needed:\n", noteID)
@@ -284,6 +333,80 @@ func printTableFooter(writer io.Writer, header string, footnote []string, remind
 	}
 }
 
+// collectSectionTags returns the conditional sections of all Notes
+// together with their state (active or skipped)
+func collectSectionTags(noteCompare map[string]map[string]note.FieldComparison) map[string]map[string]string {
+	sectionTags := make(map[string]map[string]string)
+	for noteID, comparisons := range noteCompare {
+		for _, comparison := range comparisons {
+			if comparison.ReflectFieldName != "SectionTags" || comparison.ActualValue == nil {
+				continue
+			}
+			if sectionTags[noteID] == nil {
+				sectionTags[noteID] = make(map[string]string)
+			}
+			sectionTags[noteID][comparison.ReflectMapKey] = comparison.ActualValue.(string)
+		}
+	}
+	return sectionTags
+}
+
+// printSectionTags prints the conditional sections of the Notes, which
+// were active or skipped on the running system
+func printSectionTags(writer io.Writer, sectionTags map[string]map[string]string) {
+	noteIDs := make([]string, 0, len(sectionTags))
+	for noteID := range sectionTags {
+		noteIDs = append(noteIDs, noteID)
+	}
+	sort.Strings(noteIDs)
+	for _, noteID := range noteIDs {
+		sections := make([]string, 0, len(sectionTags[noteID]))
+		fmtlen := 0
+		for section := range sectionTags[noteID] {
+			sections = append(sections, section)
+			if len(section) > fmtlen {
+				fmtlen = len(section)
+			}
+		}
+		sort.Strings(sections)
+		fmt.Fprintf(writer, "Conditional sections of SAP Note %s:\n", noteID)
+		for _, section := range sections {
+			fmt.Fprintf(writer, "   %-"+strconv.Itoa(fmtlen)+"s : %s\n", section, sectionTags[noteID][section])
+		}
+		fmt.Fprintf(writer, "\n")
+	}
+}
+
+// expValueWithFormula returns the expected value of a parameter together
+// with the value expression, if the value was calculated from an expression
+func expValueWithFormula(comparisons map[string]note.FieldComparison, comparison note.FieldComparison) string {
+	if comparison.ReflectFieldName != "SysctlParams" {
+		return comparison.ExpectedValueJS
+	}
+	formula := comparisons[fmt.Sprintf("%s[%s]", "Formulas", comparison.ReflectMapKey)]
+	if formula.ActualValue == nil || formula.ActualValue.(string) == "" {
+		return comparison.ExpectedValueJS
+	}
+	return fmt.Sprintf("%s (%s)", comparison.ExpectedValueJS, formula.ActualValue.(string))
+}
+
+// actValueWithRuntime returns the actual value of a limits parameter
+// together with the effective limit of the running processes, if available
+func actValueWithRuntime(comparisons map[string]note.FieldComparison, comparison note.FieldComparison) string {
+	if comparison.ReflectFieldName != "SysctlParams" || !strings.HasPrefix(comparison.ReflectMapKey, "LIMIT_") {
+		return comparison.ActualValueJS
+	}
+	inform := comparisons[fmt.Sprintf("%s[%s]", "Inform", comparison.ReflectMapKey)]
+	if inform.ActualValue == nil {
+		return comparison.ActualValueJS
+	}
+	fields := strings.SplitN(inform.ActualValue.(string), ":", 2)
+	if len(fields) != 2 || (fields[0] != "runtime" && fields[0] != "restart") {
+		return comparison.ActualValueJS
+	}
+	return fmt.Sprintf("%s (running: %s)", comparison.ActualValueJS, fields[1])
+}
+
 // setWidthOfColums sets the width of the columns for verify and simulate
 // depending on the highest number of characters of the content to be
 // displayed
@@ -52,6 +52,81 @@ func TestSetWidthOfColums(t *testing.T) {
 	}
 }
 
+func TestExpValueWithFormula(t *testing.T) {
+	comparisons := map[string]note.FieldComparison{
+		"SysctlParams[vm.nr_hugepages]": note.FieldComparison{ReflectFieldName: "SysctlParams", ReflectMapKey: "vm.nr_hugepages", ActualValueJS: "0", ExpectedValueJS: "65536"},
+		"Formulas[vm.nr_hugepages]":     note.FieldComparison{ReflectFieldName: "Formulas", ReflectMapKey: "vm.nr_hugepages", ActualValue: "${MEM_TOTAL_MB}/2", ExpectedValue: "${MEM_TOTAL_MB}/2"},
+		"SysctlParams[vm.swappiness]":   note.FieldComparison{ReflectFieldName: "SysctlParams", ReflectMapKey: "vm.swappiness", ActualValueJS: "60", ExpectedValueJS: "10"},
+	}
+	if val := expValueWithFormula(comparisons, comparisons["SysctlParams[vm.nr_hugepages]"]); val != "65536 (${MEM_TOTAL_MB}/2)" {
+		t.Error(val)
+	}
+	if val := expValueWithFormula(comparisons, comparisons["SysctlParams[vm.swappiness]"]); val != "10" {
+		t.Error(val)
+	}
+}
+
+func TestActValueWithRuntime(t *testing.T) {
+	comparisons := map[string]note.FieldComparison{
+		"SysctlParams[LIMIT_@sapsys_soft_nofile]": note.FieldComparison{ReflectFieldName: "SysctlParams", ReflectMapKey: "LIMIT_@sapsys_soft_nofile", ActualValueJS: "@sapsys soft nofile 1048576", ExpectedValueJS: "@sapsys soft nofile 1048576"},
+		"Inform[LIMIT_@sapsys_soft_nofile]":       note.FieldComparison{ReflectFieldName: "Inform", ReflectMapKey: "LIMIT_@sapsys_soft_nofile", ActualValue: "restart:65536", ExpectedValue: "restart:65536"},
+		"SysctlParams[LIMIT_@sdba_soft_nofile]":   note.FieldComparison{ReflectFieldName: "SysctlParams", ReflectMapKey: "LIMIT_@sdba_soft_nofile", ActualValueJS: "@sdba soft nofile 1048576", ExpectedValueJS: "@sdba soft nofile 1048576"},
+		"Inform[LIMIT_@sdba_soft_nofile]":         note.FieldComparison{ReflectFieldName: "Inform", ReflectMapKey: "LIMIT_@sdba_soft_nofile", ActualValue: "", ExpectedValue: ""},
+	}
+	if val := actValueWithRuntime(comparisons, comparisons["SysctlParams[LIMIT_@sapsys_soft_nofile]"]); val != "@sapsys soft nofile 1048576 (running: 65536)" {
+		t.Error(val)
+	}
+	if val := actValueWithRuntime(comparisons, comparisons["SysctlParams[LIMIT_@sdba_soft_nofile]"]); val != "@sdba soft nofile 1048576" {
+		t.Error(val)
+	}
+	_, _, footnote := prepareFootnote(comparisons["SysctlParams[LIMIT_@sapsys_soft_nofile]"], "yes", "", "restart:65536", make([]string, 14, 14))
+	if footnote[11] != footnote12 {
+		t.Errorf("missing footnote: '%v'", footnote)
+	}
+}
+
+func TestFsFootnote(t *testing.T) {
+	named := note.FieldComparison{ReflectFieldName: "SysctlParams", ReflectMapKey: "fs:/hana/log:type", ActualValue: "NA", ExpectedValue: "xfs"}
+	compliant, _, footnote := prepareFootnote(named, "no ", "", "", make([]string, 14, 14))
+	if compliant != "no  [13] [3]" || footnote[12] != footnote13 || footnote[1] != "" {
+		t.Errorf("wrong footnote '%s' - '%v'", compliant, footnote)
+	}
+	// a mounted file system, which is not listed in /etc/fstab
+	fstab := note.FieldComparison{ReflectFieldName: "SysctlParams", ReflectMapKey: "fs:/hana/log:fstab", ActualValue: "NA", ExpectedValue: "noatime"}
+	compliant, _, footnote = prepareFootnote(fstab, "no ", "", "", make([]string, 14, 14))
+	if compliant != "no  [14] [3]" || footnote[13] != footnote14 || footnote[12] != "" || footnote[1] != "" {
+		t.Errorf("wrong footnote '%s' - '%v'", compliant, footnote)
+	}
+	pattern := note.FieldComparison{ReflectFieldName: "SysctlParams", ReflectMapKey: "fs:/hana/log/*:type", ActualValue: "NA", ExpectedValue: "xfs"}
+	compliant, _, footnote = prepareFootnote(pattern, "yes", "", "", make([]string, 14, 14))
+	if compliant != "yes [2] [3]" || footnote[1] != footnote2 || footnote[12] != "" {
+		t.Errorf("wrong footnote '%s' - '%v'", compliant, footnote)
+	}
+}
+
+func TestPrintSectionTags(t *testing.T) {
+	comparisons := map[string]map[string]note.FieldComparison{
+		"941735": {
+			"SectionTags[[vm:numa>=2]]":      note.FieldComparison{ReflectFieldName: "SectionTags", ReflectMapKey: "[vm:numa>=2]", ActualValue: "active", ExpectedValue: "active"},
+			"SectionTags[[sysctl:virt=kvm]]": note.FieldComparison{ReflectFieldName: "SectionTags", ReflectMapKey: "[sysctl:virt=kvm]", ActualValue: "skipped", ExpectedValue: "skipped"},
+			"SysctlParams[vm.swappiness]":    note.FieldComparison{ReflectFieldName: "SysctlParams", ReflectMapKey: "vm.swappiness", ActualValue: "60", ExpectedValue: "10"},
+		},
+		"1410736": {
+			"SysctlParams[net.ipv4.tcp_keepalive_time]": note.FieldComparison{ReflectFieldName: "SysctlParams", ReflectMapKey: "net.ipv4.tcp_keepalive_time", ActualValue: "7200", ExpectedValue: "300"},
+		},
+	}
+	matchTxt := `Conditional sections of SAP Note 941735:
+   [sysctl:virt=kvm] : skipped
+   [vm:numa>=2]      : active
+
+`
+	buffer := bytes.Buffer{}
+	printSectionTags(&buffer, collectSectionTags(comparisons))
+	if buffer.String() != matchTxt {
+		t.Errorf("wrong output '%s'", buffer.String())
+	}
+}
+
 func TestPrintNoteFields(t *testing.T) {
 	var printMatchText1 = `
 941735 -  
@@ -78,6 +78,26 @@ func (app *App) PositionInNoteApplyOrder(noteID string) int {
 	return -1 //not found
 }
 
+// GetParameterConflicts returns the parameters, which are set to different
+// values by the enabled notes. The note applied last in the order of
+// NoteApplyOrder sets the value of the parameter on the system.
+func (app *App) GetParameterConflicts(overrideDir string) []note.ParameterConflict {
+	inis := make(map[string]*txtparser.INIFile)
+	for _, noteID := range app.NoteApplyOrder {
+		noteObj, ok := app.AllNotes[noteID].(note.INISettings)
+		if !ok {
+			continue
+		}
+		ini, err := note.EffectiveINIFile(noteObj.ConfFilePath, overrideDir, noteID)
+		if err != nil {
+			system.WarningLog("can not check note '%s' for conflicting parameter values - %v", noteID, err)
+			continue
+		}
+		inis[noteID] = ini
+	}
+	return note.FindParameterConflicts(app.NoteApplyOrder, inis)
+}
+
 // SaveConfig save configuration to file /etc/sysconfig/saptune.
 func (app *App) SaveConfig() error {
 	sysconf, err := txtparser.ParseSysconfigFile(path.Join(app.SysconfigPrefix, SysconfigSaptuneFile), true)
@@ -163,11 +183,7 @@ func (app *App) IsNoteApplied(noteID string) (string, bool) {
 func (app *App) NoteSanityCheck() error {
 	// app.NoteApplyOrder, app.TuneForNotes
 	errs := make([]error, 0, 0)
-	for _, note := range app.NoteApplyOrder {
-		if _, exists := app.AllNotes[note]; exists {
-			// note definition file for NoteID exists
-			continue
-		}
+	for _, note := range app.NotesWithoutDefinition() {
 		// bsc#1149205
 		// noteID available in apply order list, but no note definition
 		// file found. May be removed or renamed.
@@ -212,6 +228,18 @@ func (app *App) NoteSanityCheck() error {
 	return err
 }
 
+// NotesWithoutDefinition returns the notes of the apply order list, whose
+// Note definition file does not exist
+func (app *App) NotesWithoutDefinition() []string {
+	notes := []string{}
+	for _, note := range app.NoteApplyOrder {
+		if _, exists := app.AllNotes[note]; !exists {
+			notes = append(notes, note)
+		}
+	}
+	return notes
+}
+
 // GetNoteByID return the note corresponding to the number, or an error
 // if the note does not exist.
 func (app *App) GetNoteByID(id string) (note.Note, error) {
@@ -314,6 +342,7 @@ func (app *App) TuneNote(noteID string) error {
 	if err = app.State.Store(noteID, currentState, false); err != nil {
 		return fmt.Errorf("Failed to save current state of note %s - %v", noteID, err)
 	}
+	app.storeAppliedDefinition(noteID)
 
 	optimised, err := currentState.Optimise()
 	if err != nil {
@@ -389,6 +418,284 @@ func (app *App) TuneAll() error {
 	return nil
 }
 
+// MoveInNoteApplyOrder returns the apply order of the enabled notes with
+// noteID moved directly before or after otherID
+func (app *App) MoveInNoteApplyOrder(noteID, otherID string, before bool) ([]string, error) {
+	if noteID == otherID {
+		return nil, fmt.Errorf("a note can not be moved before or after itself")
+	}
+	for _, nID := range []string{noteID, otherID} {
+		if app.PositionInNoteApplyOrder(nID) < 0 {
+			return nil, fmt.Errorf("note '%s' is not enabled", nID)
+		}
+	}
+	newOrder := []string{}
+	for _, nID := range app.NoteApplyOrder {
+		switch nID {
+		case noteID:
+			continue
+		case otherID:
+			if before {
+				newOrder = append(newOrder, noteID, otherID)
+			} else {
+				newOrder = append(newOrder, otherID, noteID)
+			}
+		default:
+			newOrder = append(newOrder, nID)
+		}
+	}
+	return newOrder, nil
+}
+
+// ReorderNotes changes the apply order of the enabled notes to the given
+// order, which has to contain exactly the enabled notes.
+// The parameter state chains are re-arranged accordingly and the parameters,
+// whose value changes because of the new order, are re-applied with the
+// value of the note, which now comes last.
+// Returns the re-applied parameters and the note, which set the value.
+func (app *App) ReorderNotes(newOrder []string) (map[string]string, error) {
+	if len(newOrder) != len(app.NoteApplyOrder) {
+		return nil, fmt.Errorf("the new order has to contain exactly the enabled notes '%s'", strings.Join(app.NoteApplyOrder, " "))
+	}
+	for idx, noteID := range newOrder {
+		if app.PositionInNoteApplyOrder(noteID) < 0 {
+			return nil, fmt.Errorf("note '%s' is not enabled", noteID)
+		}
+		if system.IsStringInList(noteID, newOrder[idx+1:]) {
+			return nil, fmt.Errorf("note '%s' is listed more than once", noteID)
+		}
+	}
+	changed := note.ReorderParameterStates(newOrder)
+	app.NoteApplyOrder = newOrder
+	if err := app.SaveConfig(); err != nil {
+		return nil, err
+	}
+
+	// re-apply the parameters, which change their value, with the
+	// values of the note, which now wins
+	errs := make([]error, 0, 0)
+	for _, noteID := range newOrder {
+		params := []string{}
+		for param, winner := range changed {
+			if winner == noteID {
+				params = append(params, param)
+			}
+		}
+		if len(params) == 0 {
+			continue
+		}
+		if err := app.reapplyNoteParameters(noteID, params); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return changed, sap.PrintErrors(errs)
+}
+
+// reapplyNoteParameters applies only the given parameters of a note
+// The values are calculated without touching the section and parameter
+// state files of the already applied note
+func (app *App) reapplyNoteParameters(noteID string, params []string) error {
+	aNote, err := app.GetNoteByID(noteID)
+	if err != nil {
+		return err
+	}
+	iniNote, ok := aNote.(note.INISettings)
+	if !ok {
+		return nil
+	}
+	verifyNote, err := iniNote.SetValuesToApply([]string{"verify"}).Initialise()
+	if err != nil {
+		return fmt.Errorf("Failed to examine system for the current status of note %s - %v", noteID, err)
+	}
+	optimised, err := verifyNote.Optimise()
+	if err != nil {
+		return fmt.Errorf("Failed to calculate optimised parameters for note %s - %v", noteID, err)
+	}
+	if err := optimised.(note.INISettings).SetValuesToApply(params).Apply(); err != nil {
+		return fmt.Errorf("Failed to apply parameters of note %s - %v", noteID, err)
+	}
+	return nil
+}
+
+// StaleNote describes an applied note, whose effective definition changed
+// since the note was applied
+type StaleNote struct {
+	NoteID  string
+	Applied note.AppliedDefinition
+	Current note.AppliedDefinition
+}
+
+// currentDefinition returns the current effective definition of a note
+func (app *App) currentDefinition(noteID, overrideDir string) (note.AppliedDefinition, error) {
+	iniNote, ok := app.AllNotes[noteID].(note.INISettings)
+	if !ok {
+		return note.AppliedDefinition{}, fmt.Errorf("note %s is not a Note definition file", noteID)
+	}
+	ini, err := note.EffectiveINIFile(iniNote.ConfFilePath, overrideDir, noteID)
+	if err != nil {
+		return note.AppliedDefinition{}, err
+	}
+	return note.NewAppliedDefinition(ini, iniNote.ConfFilePath), nil
+}
+
+// storeAppliedDefinition remembers the effective definition of a note at
+// the time the note is applied
+func (app *App) storeAppliedDefinition(noteID string) {
+	def, err := app.currentDefinition(noteID, note.OverrideTuningSheets)
+	if err != nil {
+		return
+	}
+	if err := note.StoreAppliedDefinition(noteID, def); err != nil {
+		system.WarningLog("Failed to store the applied definition of note %s - %v", noteID, err)
+	}
+}
+
+// GetStaleNotes returns the applied notes, whose effective definition (Note
+// definition file, override file and override drop-in files) changed since
+// the note was applied. Notes applied by a saptune version, which did not
+// remember the applied definition, are not reported.
+func (app *App) GetStaleNotes(overrideDir string) []StaleNote {
+	stale := []StaleNote{}
+	for _, noteID := range app.NoteApplyOrder {
+		if _, err := os.Stat(app.State.GetPathToNote(noteID)); err != nil {
+			// note not applied
+			continue
+		}
+		applied, err := note.GetAppliedDefinition(noteID)
+		if err != nil {
+			continue
+		}
+		current, err := app.currentDefinition(noteID, overrideDir)
+		if err != nil {
+			continue
+		}
+		if applied.Hash != current.Hash || applied.Version != current.Version || applied.Date != current.Date {
+			stale = append(stale, StaleNote{NoteID: noteID, Applied: applied, Current: current})
+		}
+	}
+	return stale
+}
+
+// RefreshNote brings an applied note in line with its current definition
+// (Note definition file, override file and override drop-in files) without
+// reverting the whole note.
+// Parameters no longer set by the note are reverted, new or changed
+// parameters are applied, if the note is the last one in the apply order
+// setting the parameter. The saved state of the note and the parameter
+// state files are updated in place.
+// Returns the applied and the reverted parameters.
+func (app *App) RefreshNote(noteID string) (changed []string, reverted []string, err error) {
+	changed = []string{}
+	reverted = []string{}
+	if _, err = os.Stat(app.State.GetPathToNote(noteID)); err != nil {
+		return nil, nil, fmt.Errorf("note %s is not applied", noteID)
+	}
+	aNote, err := app.GetNoteByID(noteID)
+	if err != nil {
+		return nil, nil, err
+	}
+	iniNote, ok := aNote.(note.INISettings)
+	if !ok {
+		return nil, nil, fmt.Errorf("refresh is not supported for note %s", noteID)
+	}
+	savedState := note.INISettings{}
+	if err = app.State.Retrieve(noteID, &savedState); err != nil {
+		return nil, nil, fmt.Errorf("Failed to read the saved state of note %s - %v", noteID, err)
+	}
+
+	// values set by the note during apply
+	oldVals := note.GetNoteParameterValues(noteID)
+	// values of the current definition of the note, calculated without
+	// touching the parameter state files
+	verifyNote, err := iniNote.SetValuesToApply([]string{"verify"}).Initialise()
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to examine system for the current status of note %s - %v", noteID, err)
+	}
+	verifyNote, err = verifyNote.Optimise()
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to calculate optimised parameters for note %s - %v", noteID, err)
+	}
+	newVals, err := verifyNote.(note.INISettings).TunedParameters()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// revert the parameters, which are no longer set by the note
+	for param := range oldVals {
+		if _, ok := newVals[param]; !ok && param != "fl_states" {
+			reverted = append(reverted, param)
+		}
+	}
+	sort.Strings(reverted)
+	if len(reverted) != 0 {
+		revertNote := savedState.SetValuesToApply(append([]string{"revert"}, reverted...))
+		if err = revertNote.Apply(); err != nil {
+			return nil, nil, fmt.Errorf("Failed to revert parameters of note %s - %v", noteID, err)
+		}
+	}
+
+	// store the start values of new parameters and the new section
+	// information of the note
+	currentState, err := iniNote.Initialise()
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to examine system for the current status of note %s - %v", noteID, err)
+	}
+	current := currentState.(note.INISettings)
+	// Optimise changes the values of the shared map, so remember the
+	// start values for the saved state of the note
+	startVals := make(map[string]string, len(current.SysctlParams))
+	for param, val := range current.SysctlParams {
+		startVals[param] = val
+	}
+	// Initialise stored the start values of the reverted parameters
+	// again, remove them, if no other note sets the parameter
+	for _, param := range reverted {
+		if len(note.GetSavedParameterNotes(param).AllNotes) == 1 {
+			note.CleanUpParamFile(param)
+		}
+	}
+	optimised, err := currentState.Optimise()
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to calculate optimised parameters for note %s - %v", noteID, err)
+	}
+
+	// apply new and changed parameters, if the note sets the value
+	toApply := []string{}
+	for param, val := range newVals {
+		if oldVal, ok := oldVals[param]; ok && oldVal == val {
+			continue
+		}
+		changed = append(changed, param)
+		if note.UpdateParameterNoteValue(param, noteID, val, app.NoteApplyOrder) {
+			toApply = append(toApply, param)
+		}
+	}
+	sort.Strings(changed)
+	if len(toApply) != 0 {
+		if err = optimised.(note.INISettings).SetValuesToApply(toApply).Apply(); err != nil {
+			return nil, nil, fmt.Errorf("Failed to apply parameters of note %s - %v", noteID, err)
+		}
+	}
+
+	// update the saved state of the note
+	for _, param := range reverted {
+		delete(savedState.SysctlParams, param)
+	}
+	for _, param := range changed {
+		if _, ok := savedState.SysctlParams[param]; !ok {
+			savedState.SysctlParams[param] = startVals[param]
+		}
+	}
+	savedState.OverrideParams = current.OverrideParams
+	savedState.Formulas = current.Formulas
+	savedState.SectionTags = current.SectionTags
+	if err = app.State.Store(noteID, savedState, true); err != nil {
+		return nil, nil, fmt.Errorf("Failed to save current state of note %s - %v", noteID, err)
+	}
+	app.storeAppliedDefinition(noteID)
+	return changed, reverted, nil
+}
+
 // RevertNote revert parameters tuned by the note and clear its stored states.
 func (app *App) RevertNote(noteID string, permanent bool) error {
 
@@ -429,6 +736,7 @@ func (app *App) RevertNote(noteID string, permanent bool) error {
 		} else if err := app.State.Remove(noteID); err != nil {
 			return err
 		}
+		note.RemoveAppliedDefinition(noteID)
 	} else if !os.IsNotExist(err) {
 		return err
 	}
@@ -438,16 +746,24 @@ func (app *App) RevertNote(noteID string, permanent bool) error {
 // RevertSolution permanently revert notes tuned by the solution and
 // clear their stored states.
 func (app *App) RevertSolution(solName string) error {
+	_, err := app.revertSolution(solName, nil)
+	return err
+}
+
+// revertSolution permanently reverts the notes tuned by the solution except
+// the notes listed in keepNotes and returns the reverted notes
+func (app *App) revertSolution(solName string, keepNotes []string) ([]string, error) {
+	revertedNotes := []string{}
 	sol, err := app.GetSolutionByName(solName)
 	if err != nil {
-		return err
+		return revertedNotes, err
 	}
 	// Remove from configuration
 	i := sort.SearchStrings(app.TuneForSolutions, solName)
 	if i < len(app.TuneForSolutions) && app.TuneForSolutions[i] == solName {
 		app.TuneForSolutions = append(app.TuneForSolutions[0:i], app.TuneForSolutions[i+1:]...)
 		if err := app.SaveConfig(); err != nil {
-			return err
+			return revertedNotes, err
 		}
 	}
 	// The tricky part: figure out which notes are to be reverted, do not revert manually enabled notes.
@@ -455,19 +771,22 @@ func (app *App) RevertSolution(solName string) error {
 	for _, noteID := range app.TuneForNotes {
 		notesDoNotRevert[noteID] = struct{}{}
 	}
+	for _, noteID := range keepNotes {
+		notesDoNotRevert[noteID] = struct{}{}
+	}
 	// Do not revert notes that are referred to by other enabled solutions
 	for _, otherSolName := range app.TuneForSolutions {
 		if otherSolName != solName {
 			otherSolNotes, err := app.GetSolutionByName(otherSolName)
 			if err != nil {
-				return err
+				return revertedNotes, err
 			}
 			for _, noteID := range otherSolNotes {
 				notesDoNotRevert[noteID] = struct{}{}
 			}
 		}
 	}
-	// Now revert the (sol notes - manually enabled - other sol notes)
+	// Now revert the (sol notes - manually enabled - other sol notes - keep notes)
 	noteErrs := make([]error, 0, 0)
 	for _, noteID := range sol {
 		if _, found := notesDoNotRevert[noteID]; found {
@@ -475,12 +794,57 @@ func (app *App) RevertSolution(solName string) error {
 		}
 		if err := app.RevertNote(noteID, true); err != nil {
 			noteErrs = append(noteErrs, err)
+		} else {
+			revertedNotes = append(revertedNotes, noteID)
 		}
 	}
 	if len(noteErrs) == 0 {
-		return nil
+		return revertedNotes, nil
 	}
-	return fmt.Errorf("Failed to revert one or more SAP notes that belong to the solution: %v", noteErrs)
+	return revertedNotes, fmt.Errorf("Failed to revert one or more SAP notes that belong to the solution: %v", noteErrs)
+}
+
+// ChangeSolution switches the enabled solution to the solution newSolName.
+// Only the notes, which are not part of the new solution, are reverted and
+// only the notes, which are not yet applied, are applied. The notes shared
+// by both solutions stay untouched.
+// Returns the kept, the reverted and the applied notes and the additional
+// notes, which are now tuned by the new solution
+func (app *App) ChangeSolution(newSolName string) (keptNotes, revertedNotes, appliedNotes, removedExplicitNotes []string, err error) {
+	if len(app.TuneForSolutions) != 1 {
+		err = fmt.Errorf("changing the solution needs exactly one enabled solution, but found '%s'", strings.Join(app.TuneForSolutions, " "))
+		return
+	}
+	oldSolName := app.TuneForSolutions[0]
+	if oldSolName == newSolName {
+		err = fmt.Errorf("solution '%s' is already enabled", newSolName)
+		return
+	}
+	newSol, err := app.GetSolutionByName(newSolName)
+	if err != nil {
+		return
+	}
+	oldSol, err := app.GetSolutionByName(oldSolName)
+	if err != nil {
+		return
+	}
+	keptNotes = []string{}
+	appliedNotes = []string{}
+	for _, noteID := range oldSol {
+		if system.IsStringInList(noteID, newSol) {
+			keptNotes = append(keptNotes, noteID)
+		}
+	}
+	if revertedNotes, err = app.revertSolution(oldSolName, newSol); err != nil {
+		return
+	}
+	for _, noteID := range newSol {
+		if _, ok := app.IsNoteApplied(noteID); !ok {
+			appliedNotes = append(appliedNotes, noteID)
+		}
+	}
+	removedExplicitNotes, err = app.TuneSolution(newSolName)
+	return
 }
 
 // RevertAll revert all tuned parameters (both solutions and additional notes),
@@ -6,6 +6,7 @@ import (
 	"github.com/SUSE/saptune/sap/note"
 	"github.com/SUSE/saptune/sap/param"
 	"github.com/SUSE/saptune/sap/solution"
+	"github.com/SUSE/saptune/system"
 	"io/ioutil"
 	"os"
 	"path"
@@ -382,6 +383,63 @@ func TestOverlappingSolutions(t *testing.T) {
 	VerifyFileContent(t, SampleParamFile, "optimised1")
 }
 
+func TestChangeSolution(t *testing.T) {
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), AllTestNotes, AllTestSolutions)
+	VerifyConfig(t, tuneApp, []string{}, []string{})
+
+	// no solution enabled
+	if _, _, _, _, err := tuneApp.ChangeSolution("sol12"); err == nil {
+		t.Error("expected an error, as no solution is enabled")
+	}
+	if _, err := tuneApp.TuneSolution("sol1"); err != nil {
+		t.Fatal(err)
+	}
+	// same solution
+	if _, _, _, _, err := tuneApp.ChangeSolution("sol1"); err == nil {
+		t.Error("expected an error, as the solution is already enabled")
+	}
+	// unknown solution
+	if _, _, _, _, err := tuneApp.ChangeSolution("unknownSol"); err == nil {
+		t.Error("expected an error, as the solution does not exist")
+	}
+	VerifyConfig(t, tuneApp, []string{}, []string{"sol1"})
+
+	// sol1 -> sol12, note 1001 is kept, note 1002 is applied
+	kept, reverted, applied, _, err := tuneApp.ChangeSolution("sol12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(kept, []string{"1001"}) || len(reverted) != 0 || !reflect.DeepEqual(applied, []string{"1002"}) {
+		t.Errorf("kept: '%v', reverted: '%v', applied: '%v'", kept, reverted, applied)
+	}
+	VerifyConfig(t, tuneApp, []string{}, []string{"sol12"})
+	if !reflect.DeepEqual(tuneApp.NoteApplyOrder, []string{"1001", "1002"}) {
+		t.Errorf("unexpected note apply order '%v'", tuneApp.NoteApplyOrder)
+	}
+	VerifyFileContent(t, SampleParamFile, "optimised2")
+
+	// sol12 -> sol2, note 1002 is kept, note 1001 is reverted
+	kept, reverted, applied, _, err = tuneApp.ChangeSolution("sol2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(kept, []string{"1002"}) || !reflect.DeepEqual(reverted, []string{"1001"}) || len(applied) != 0 {
+		t.Errorf("kept: '%v', reverted: '%v', applied: '%v'", kept, reverted, applied)
+	}
+	VerifyConfig(t, tuneApp, []string{}, []string{"sol2"})
+	if !reflect.DeepEqual(tuneApp.NoteApplyOrder, []string{"1002"}) {
+		t.Errorf("unexpected note apply order '%v'", tuneApp.NoteApplyOrder)
+	}
+	if _, ok := tuneApp.IsNoteApplied("1001"); ok {
+		t.Error("note '1001' should be reverted")
+	}
+	if _, ok := tuneApp.IsNoteApplied("1002"); !ok {
+		t.Error("note '1002' should still be applied")
+	}
+}
+
 func TestCombiningSolutionAndNotes(t *testing.T) {
 	os.RemoveAll(SampleNoteDataDir)
 	defer os.RemoveAll(SampleNoteDataDir)
@@ -492,7 +550,13 @@ func TestNoteSanityCheck(t *testing.T) {
 		t.Errorf("Error during NoteSanityCheck - '%v'\n", err)
 	}
 
+	if notes := tuneApp.NotesWithoutDefinition(); len(notes) != 0 {
+		t.Errorf("unexpected notes without definition '%+v'\n", notes)
+	}
 	tuneApp.NoteApplyOrder = append(tuneApp.NoteApplyOrder, "8932147")
+	if notes := tuneApp.NotesWithoutDefinition(); !reflect.DeepEqual(notes, []string{"8932147"}) {
+		t.Errorf("wrong notes without definition '%+v'\n", notes)
+	}
 	err := tuneApp.NoteSanityCheck()
 	t.Logf("NoteSanityCheck - '%v'\n", err)
 	tuneApp = InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), AllTestNotes, AllTestSolutions)
@@ -524,3 +588,216 @@ func TestInitialiseApp(t *testing.T) {
 		t.Error(tstApp)
 	}
 }
+
+func TestReorderNotes(t *testing.T) {
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), AllTestNotes, AllTestSolutions)
+	if err := tuneApp.TuneNote("1001"); err != nil {
+		t.Error(err)
+	}
+	if err := tuneApp.TuneNote("1002"); err != nil {
+		t.Error(err)
+	}
+
+	newOrder, err := tuneApp.MoveInNoteApplyOrder("1002", "1001", true)
+	if err != nil || !reflect.DeepEqual(newOrder, []string{"1002", "1001"}) {
+		t.Errorf("wrong order '%+v' - '%v'\n", newOrder, err)
+	}
+	newOrder, err = tuneApp.MoveInNoteApplyOrder("1001", "1002", false)
+	if err != nil || !reflect.DeepEqual(newOrder, []string{"1002", "1001"}) {
+		t.Errorf("wrong order '%+v' - '%v'\n", newOrder, err)
+	}
+	if _, err := tuneApp.MoveInNoteApplyOrder("1001", "8932147", true); err == nil {
+		t.Error("moving before a not enabled note should fail")
+	}
+	if _, err := tuneApp.MoveInNoteApplyOrder("1001", "1001", true); err == nil {
+		t.Error("moving a note before itself should fail")
+	}
+
+	for _, wrongOrder := range [][]string{{"1001"}, {"1001", "1001"}, {"1001", "8932147"}} {
+		if _, err := tuneApp.ReorderNotes(wrongOrder); err == nil {
+			t.Errorf("order '%+v' should be rejected\n", wrongOrder)
+		}
+	}
+	if _, err := tuneApp.ReorderNotes([]string{"1002", "1001"}); err != nil {
+		t.Error(err)
+	}
+	tuneApp = InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), AllTestNotes, AllTestSolutions)
+	if !reflect.DeepEqual(tuneApp.NoteApplyOrder, []string{"1002", "1001"}) {
+		t.Errorf("new order not saved: '%+v'\n", tuneApp.NoteApplyOrder)
+	}
+}
+
+func TestRefreshNote(t *testing.T) {
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), AllTestNotes, AllTestSolutions)
+	if _, _, err := tuneApp.RefreshNote("1001"); err == nil {
+		t.Error("refresh of a not applied note should fail")
+	}
+	if err := tuneApp.TuneNote("1001"); err != nil {
+		t.Error(err)
+	}
+	if _, _, err := tuneApp.RefreshNote("8932147"); err == nil {
+		t.Error("refresh of an unknown note should fail")
+	}
+	// the sample notes are no Note definition files
+	if _, _, err := tuneApp.RefreshNote("1001"); err == nil {
+		t.Error("refresh of a note, which is not a Note definition file, should fail")
+	}
+}
+
+func TestRefreshININote(t *testing.T) {
+	noteID := "4711refresh"
+	params := []string{"kernel.shmmni", "kernel.msgmnb", "vm.swappiness"}
+	startVals := make(map[string]string)
+	for _, param := range params {
+		val, err := system.GetSysctlString(param)
+		if err != nil {
+			t.Skipf("sysctl parameter '%s' not available - %v", param, err)
+		}
+		startVals[param] = val
+		if _, err := os.Stat(note.GetPathToParameter(param)); err == nil {
+			t.Skipf("parameter state file of '%s' already exists", param)
+		}
+	}
+	ovFile := path.Join(note.OverrideTuningSheets, noteID)
+	if err := os.MkdirAll(note.OverrideTuningSheets, 0755); err != nil {
+		t.Skipf("override directory not available - %v", err)
+	}
+	os.RemoveAll(SampleNoteDataDir)
+	note.CleanUpRun()
+	defer func() {
+		os.RemoveAll(SampleNoteDataDir)
+		note.CleanUpRun()
+		os.Remove(ovFile)
+		os.Remove(path.Join(note.SaptuneSectionDir, noteID+".sections"))
+		note.RemoveAppliedDefinition(noteID)
+		for param, val := range startVals {
+			_ = system.SetSysctlString(param, val)
+			note.CleanUpParamFile(param)
+		}
+	}()
+	if err := os.MkdirAll(SampleNoteDataDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	confFile := path.Join(SampleNoteDataDir, noteID)
+	noteHead := "[version]\n# SAP-NOTE=4711refresh CATEGORY=test VERSION=1 DATE=01.02.2020 NAME=\"refresh test\"\n\n[sysctl]\nvm.swappiness = 11\nkernel.msgmnb = 17\n"
+	if err := ioutil.WriteFile(confFile, []byte(noteHead), 0644); err != nil {
+		t.Fatal(err)
+	}
+	iniNote := note.INISettings{ConfFilePath: confFile, ID: noteID}
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), map[string]note.Note{noteID: iniNote}, AllTestSolutions)
+	if err := tuneApp.TuneNote(noteID); err != nil {
+		t.Fatal(err)
+	}
+	if vals := note.GetNoteParameterValues(noteID); !reflect.DeepEqual(vals, map[string]string{"vm.swappiness": "11", "kernel.msgmnb": "17"}) {
+		t.Fatalf("wrong parameter values after apply: %+v\n", vals)
+	}
+
+	// the override file changes one value and removes one parameter,
+	// the Note definition file adds one parameter
+	if err := ioutil.WriteFile(ovFile, []byte("[sysctl]\nvm.swappiness = 21\nkernel.msgmnb =\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(confFile, []byte(noteHead+"kernel.shmmni = 4099\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// a new saptune call starts without section runtime files
+	note.CleanUpRun()
+	changed, reverted, err := tuneApp.RefreshNote(noteID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(changed, []string{"kernel.shmmni", "vm.swappiness"}) {
+		t.Errorf("wrong changed parameters: %+v\n", changed)
+	}
+	if !reflect.DeepEqual(reverted, []string{"kernel.msgmnb"}) {
+		t.Errorf("wrong reverted parameters: %+v\n", reverted)
+	}
+	for param, exp := range map[string]string{"vm.swappiness": "21", "kernel.msgmnb": startVals["kernel.msgmnb"], "kernel.shmmni": "4099"} {
+		if val, _ := system.GetSysctlString(param); val != exp {
+			t.Errorf("wrong value '%s' of '%s' after refresh, expected '%s'\n", val, param, exp)
+		}
+	}
+	// parameter state files
+	if vals := note.GetNoteParameterValues(noteID); !reflect.DeepEqual(vals, map[string]string{"vm.swappiness": "21", "kernel.shmmni": "4099"}) {
+		t.Errorf("wrong parameter values after refresh: %+v\n", vals)
+	}
+	for _, param := range []string{"vm.swappiness", "kernel.shmmni"} {
+		pEntries := note.GetSavedParameterNotes(param)
+		if len(pEntries.AllNotes) != 2 || pEntries.AllNotes[0].NoteID != "start" || pEntries.AllNotes[0].Value != startVals[param] {
+			t.Errorf("wrong parameter state of '%s': %+v\n", param, pEntries)
+		}
+	}
+	if _, err := os.Stat(note.GetPathToParameter("kernel.msgmnb")); err == nil {
+		t.Error("parameter state file of the reverted parameter still exists")
+	}
+	// section information and saved state of the note
+	if !system.CheckForPattern(path.Join(note.SaptuneSectionDir, noteID+".sections"), "kernel.shmmni") {
+		t.Error("new parameter missing in the section file")
+	}
+	savedState := note.INISettings{}
+	if err := tuneApp.State.Retrieve(noteID, &savedState); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := savedState.SysctlParams["kernel.msgmnb"]; ok || savedState.SysctlParams["kernel.shmmni"] != startVals["kernel.shmmni"] || savedState.OverrideParams["vm.swappiness"] != "21" {
+		t.Errorf("wrong saved state: %+v\n", savedState)
+	}
+
+	// revert restores the start values
+	if err := tuneApp.RevertNote(noteID, true); err != nil {
+		t.Fatal(err)
+	}
+	for param, exp := range startVals {
+		if val, _ := system.GetSysctlString(param); val != exp {
+			t.Errorf("wrong value '%s' of '%s' after revert, expected '%s'\n", val, param, exp)
+		}
+		if _, err := os.Stat(note.GetPathToParameter(param)); err == nil {
+			t.Errorf("parameter state file of '%s' still exists after revert", param)
+		}
+	}
+}
+
+func TestGetStaleNotes(t *testing.T) {
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	defer note.RemoveAppliedDefinition("4711stale")
+	ovDir := path.Join(SampleNoteDataDir, "override")
+	if err := os.MkdirAll(ovDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	confFile := path.Join(SampleNoteDataDir, "4711stale")
+	if err := ioutil.WriteFile(confFile, []byte("[version]\n# SAP-NOTE=4711stale CATEGORY=test VERSION=1 DATE=01.02.2020 NAME=\"stale test\"\n\n[sysctl]\nvm.swappiness = 10\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	staleNote := note.INISettings{ConfFilePath: confFile, ID: "4711stale"}
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), map[string]note.Note{"4711stale": staleNote}, AllTestSolutions)
+	tuneApp.NoteApplyOrder = []string{"4711stale"}
+	// not applied
+	if stale := tuneApp.GetStaleNotes(ovDir); len(stale) != 0 {
+		t.Errorf("not applied note reported as stale: %+v\n", stale)
+	}
+	if err := tuneApp.State.Store("4711stale", staleNote, true); err != nil {
+		t.Fatal(err)
+	}
+	// applied without remembered definition
+	if stale := tuneApp.GetStaleNotes(ovDir); len(stale) != 0 {
+		t.Errorf("note without applied definition reported as stale: %+v\n", stale)
+	}
+	tuneApp.storeAppliedDefinition("4711stale")
+	if stale := tuneApp.GetStaleNotes(ovDir); len(stale) != 0 {
+		t.Errorf("unchanged note reported as stale: %+v\n", stale)
+	}
+	if err := ioutil.WriteFile(path.Join(ovDir, "4711stale"), []byte("[sysctl]\nvm.swappiness = 60\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stale := tuneApp.GetStaleNotes(ovDir)
+	if len(stale) != 1 || stale[0].NoteID != "4711stale" {
+		t.Fatalf("changed note not reported as stale: %+v\n", stale)
+	}
+	if changed := stale[0].Applied.ChangedParams(stale[0].Current); !reflect.DeepEqual(changed, []string{"[sysctl] vm.swappiness: '= 10' -> '= 60'"}) {
+		t.Errorf("wrong changed parameters: %+v\n", changed)
+	}
+}
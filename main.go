@@ -14,7 +14,6 @@ import (
 
 // constant definitions
 const (
-	TunedService    = "tuned.service"
 	saptuneV1       = "/usr/sbin/saptune_v1"
 	logFile         = "/var/log/saptune/saptune.log"
 	exitNotYetTuned = 5
@@ -29,11 +28,16 @@ var verboseSwitch = os.Getenv("SAPTUNE_VERBOSE") // Switch verbose mode on ("on"
 var SaptuneVersion = ""
 
 func main() {
+	// errors of 'saptune check' must not be mixed up with its results
+	if system.CliArg(1) == "check" {
+		system.SetErrorExitCode(actions.ExitCheckUnknown)
+	}
+
 	// get saptune version
 	sconf, err := txtparser.ParseSysconfigFile("/etc/sysconfig/saptune", true)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: Unable to read file '/etc/sysconfig/saptune': %v\n", err)
-		system.ErrorExit("", 1)
+		system.ErrorExit("")
 	}
 	SaptuneVersion = sconf.GetString("SAPTUNE_VERSION", "")
 	// check, if DEBUG is set in /etc/sysconfig/saptune
@@ -55,7 +59,7 @@ func main() {
 	// All other actions require super user privilege
 	if os.Geteuid() != 0 {
 		fmt.Fprintf(os.Stderr, "Please run saptune with root privilege.\n")
-		system.ErrorExit("", 1)
+		system.ErrorExit("")
 	}
 
 	// activate logging
@@ -102,21 +106,30 @@ func main() {
 		system.ErrorExit("Wrong saptune version in file '/etc/sysconfig/saptune': %s", SaptuneVersion)
 	}
 
+	// Initialise application configuration and tuning procedures
+	tuningOptions = note.GetTuningOptions(actions.NoteTuningSheets, actions.ExtraTuningSheets)
+	// custom solutions can reference built-in and 3rd party notes
+	customSols, customInfos := solution.GetCustomSolutions(actions.ExtraTuningSheets, tuningOptions)
+	solution.CustomSolutions = customSols
+	solution.AddCustomSolutions(customSols, customInfos)
+
 	solutionSelector := system.GetSolutionSelector()
 	archSolutions, exist := solution.AllSolutions[solutionSelector]
 	if !exist {
 		system.ErrorExit("The system architecture (%s) is not supported.", solutionSelector)
 		return
 	}
-	// Initialise application configuration and tuning procedures
-	tuningOptions = note.GetTuningOptions(actions.NoteTuningSheets, actions.ExtraTuningSheets)
 	tuneApp = app.InitialiseApp("", "", tuningOptions, archSolutions)
 
-	checkUpdateLeftOvers()
-	if err := tuneApp.NoteSanityCheck(); err != nil {
-		system.ErrorExit("Error during NoteSanityCheck - '%v'\n", err)
+	// 'saptune check' reports the left overs, the notes without
+	// definition file and the tuned service itself, but changes nothing
+	if system.CliArg(1) != "check" {
+		checkUpdateLeftOvers()
+		if err := tuneApp.NoteSanityCheck(); err != nil {
+			system.ErrorExit("Error during NoteSanityCheck - '%v'\n", err)
+		}
+		checkForTuned()
 	}
-	checkForTuned()
 	actions.SelectAction(tuneApp, SaptuneVersion)
 }
 
@@ -126,12 +139,12 @@ func checkUpdateLeftOvers() {
 	// check for the /etc/tuned/saptune/tuned.conf file created during
 	// the package update from saptune v1 to saptune v2
 	// give a Warning but go ahead tuning the system
-	if system.CheckForPattern("/etc/tuned/saptune/tuned.conf", "#stv1tov2#") {
+	if actions.V1TunedConfLeftOver() {
 		system.WarningLog("found file '/etc/tuned/saptune/tuned.conf' left over from the migration of saptune version 1 to saptune version 2. Please check and remove this file as it may work against the settings of some SAP Notes. For more information refer to the man page saptune-migrate(7)")
 	}
 
 	// check if old solution or notes are applied
-	if tuneApp != nil && (len(tuneApp.NoteApplyOrder) == 0 && (len(tuneApp.TuneForNotes) != 0 || len(tuneApp.TuneForSolutions) != 0)) {
+	if actions.V1ConfigLeftOver(tuneApp) {
 		system.ErrorExit("There are 'old' solutions or notes defined in file '/etc/sysconfig/saptune'. Seems there were some steps missed during the migration from saptune version 1 to version 2. Please check. Refer to saptune-migrate(7) for more information")
 	}
 }
@@ -139,7 +152,7 @@ func checkUpdateLeftOvers() {
 // checkForTuned checks for enabled and/or running tuned and prints out
 // a warning message
 func checkForTuned() {
-	if system.SystemctlIsEnabled(TunedService) || system.SystemctlIsRunning(TunedService) {
+	if actions.TunedIsActive() {
 		system.WarningLog("ATTENTION: tuned service is enabled/active, so we may encounter conflicting tuning values")
 	}
 }
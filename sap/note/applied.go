@@ -0,0 +1,115 @@
+package note
+
+// Remember the effective definition of a Note at the time it was applied
+// to be able to detect later changes of the Note definition file, the
+// override file or the override drop-in files
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"github.com/SUSE/saptune/txtparser"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+)
+
+// AppliedDefinition describes the effective definition of a Note at the
+// time the Note was applied
+type AppliedDefinition struct {
+	Hash    string            // hash of the effective parameter definitions
+	Version string            // VERSION from the [version] section
+	Date    string            // DATE from the [version] section
+	Params  map[string]string // '[section] key' and '<operator> <value>'
+}
+
+// GetPathToAppliedDefinition returns the path to the file, which stores the
+// applied definition of a Note
+func GetPathToAppliedDefinition(noteID string) string {
+	return path.Join(SaptuneSectionDir, noteID+".applied")
+}
+
+// NewAppliedDefinition returns the applied definition of the effective Note
+// definition (Note definition file merged with the override files) and
+// the VERSION and DATE of the Note definition file
+func NewAppliedDefinition(ini *txtparser.INIFile, confFilePath string) AppliedDefinition {
+	def := AppliedDefinition{
+		Version: txtparser.GetINIFileVersionSectionEntry(confFilePath, "version"),
+		Date:    txtparser.GetINIFileVersionSectionEntry(confFilePath, "date"),
+		Params:  make(map[string]string),
+	}
+	for _, entry := range ini.AllValues {
+		if entry.Section == INISectionVersion || entry.Section == INISectionReminder {
+			continue
+		}
+		def.Params[fmt.Sprintf("[%s] %s", entry.Section, entry.Key)] = fmt.Sprintf("%s %s", entry.Operator, entry.Value)
+	}
+	keys := make([]string, 0, len(def.Params))
+	for key := range def.Params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	hash := sha256.New()
+	for _, key := range keys {
+		fmt.Fprintf(hash, "%s %s\n", key, def.Params[key])
+	}
+	def.Hash = fmt.Sprintf("%x", hash.Sum(nil))
+	return def
+}
+
+// StoreAppliedDefinition writes the applied definition of a Note to the
+// section directory
+func StoreAppliedDefinition(noteID string, def AppliedDefinition) error {
+	content, err := json.Marshal(def)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(SaptuneSectionDir, 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(GetPathToAppliedDefinition(noteID), content, 0644)
+}
+
+// GetAppliedDefinition reads the applied definition of a Note.
+// An error is returned, if the Note was applied by a saptune version,
+// which did not store the applied definition
+func GetAppliedDefinition(noteID string) (AppliedDefinition, error) {
+	def := AppliedDefinition{}
+	content, err := ioutil.ReadFile(GetPathToAppliedDefinition(noteID))
+	if err != nil {
+		return def, err
+	}
+	err = json.Unmarshal(content, &def)
+	return def, err
+}
+
+// RemoveAppliedDefinition removes the applied definition of a Note
+func RemoveAppliedDefinition(noteID string) {
+	if _, err := os.Stat(GetPathToAppliedDefinition(noteID)); err == nil {
+		os.Remove(GetPathToAppliedDefinition(noteID))
+	}
+}
+
+// ChangedParams returns the parameters, which differ between the applied
+// and the current definition of a Note in the form
+// '[section] key: <applied> -> <current>'. Parameters missing on one side
+// are shown as '-'
+func (def AppliedDefinition) ChangedParams(current AppliedDefinition) []string {
+	changed := []string{}
+	for key, val := range def.Params {
+		curVal, ok := current.Params[key]
+		if !ok {
+			changed = append(changed, fmt.Sprintf("%s: '%s' -> -", key, val))
+		} else if curVal != val {
+			changed = append(changed, fmt.Sprintf("%s: '%s' -> '%s'", key, val, curVal))
+		}
+	}
+	for key, curVal := range current.Params {
+		if _, ok := def.Params[key]; !ok {
+			changed = append(changed, fmt.Sprintf("%s: - -> '%s'", key, curVal))
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
@@ -0,0 +1,60 @@
+package note
+
+import (
+	"github.com/SUSE/saptune/txtparser"
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+)
+
+func TestAppliedDefinition(t *testing.T) {
+	confFile := path.Join(os.TempDir(), "saptune_applied_def")
+	defer os.Remove(confFile)
+	content := "[version]\n# SAP-NOTE=4711 CATEGORY=test VERSION=3 DATE=01.02.2020 NAME=\"applied test\"\n\n[sysctl]\nvm.swappiness = 10\nvm.dirty_ratio = 10\n\n[reminder]\n# remember me\n"
+	if err := ioutil.WriteFile(confFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ini, err := txtparser.ParseINIFile(confFile, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	def := NewAppliedDefinition(ini, confFile)
+	if def.Version != "3" || def.Date != "01.02.2020" {
+		t.Errorf("wrong version '%s' or date '%s'\n", def.Version, def.Date)
+	}
+	expParams := map[string]string{"[sysctl] vm.swappiness": "= 10", "[sysctl] vm.dirty_ratio": "= 10"}
+	if !reflect.DeepEqual(def.Params, expParams) {
+		t.Errorf("got: %+v, expected: %+v\n", def.Params, expParams)
+	}
+	if def.Hash == "" || NewAppliedDefinition(ini, confFile).Hash != def.Hash {
+		t.Errorf("hash '%s' is empty or not stable\n", def.Hash)
+	}
+
+	// changed definition
+	ow := txtparser.ParseINI("[sysctl]\nvm.swappiness = 60\nkernel.shmmni = 4096\n")
+	ini.Merge(ow)
+	current := NewAppliedDefinition(ini, confFile)
+	if current.Hash == def.Hash {
+		t.Error("hash should change with the definition")
+	}
+	expChanged := []string{"[sysctl] kernel.shmmni: - -> '= 4096'", "[sysctl] vm.swappiness: '= 10' -> '= 60'"}
+	if changed := def.ChangedParams(current); !reflect.DeepEqual(changed, expChanged) {
+		t.Errorf("got: %+v, expected: %+v\n", changed, expChanged)
+	}
+
+	// store, read and remove
+	defer RemoveAppliedDefinition("4711applied")
+	if err := StoreAppliedDefinition("4711applied", def); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := GetAppliedDefinition("4711applied")
+	if err != nil || !reflect.DeepEqual(stored, def) {
+		t.Errorf("got: %+v, expected: %+v - %v\n", stored, def, err)
+	}
+	RemoveAppliedDefinition("4711applied")
+	if _, err := GetAppliedDefinition("4711applied"); err == nil {
+		t.Error("applied definition should be removed")
+	}
+}
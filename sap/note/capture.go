@@ -0,0 +1,185 @@
+package note
+
+// Capture the current values of system parameters into a new Note
+// definition ('saptune note create NoteID --from-system ...')
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/SUSE/saptune/sap/param"
+	"github.com/SUSE/saptune/system"
+	"github.com/SUSE/saptune/txtparser"
+	"strings"
+)
+
+// captureSections are the sections, which support capturing the current
+// system values, with the supported keys (nil - all keys)
+var captureSections = map[string][]string{
+	INISectionSysctl:  nil,
+	INISectionBlock:   {"IO_SCHEDULER", "NRREQ", "READ_AHEAD_KB"},
+	INISectionCPU:     {"governor", "energy_perf_bias", "force_latency"},
+	INISectionVM:      {"THP", "KSM"},
+	INISectionService: nil,
+}
+
+// energy_perf_bias values and their names used in the Note definitions
+var perfBiasNames = map[string]string{"0": "performance", "6": "normal", "15": "powersave"}
+
+// CaptureSystemValues returns the current system values of the given
+// parameters ('section.key') as entries of a Note definition.
+// The keys of the [sysctl] section can contain glob patterns.
+// Values, which differ between block devices or cpus, are captured with
+// the most common value. Parameters without a value are skipped with a
+// warning.
+func CaptureSystemValues(params []string) ([]txtparser.INIEntry, error) {
+	entries := []txtparser.INIEntry{}
+	seen := make(map[string]bool)
+	add := func(section, key, value string) {
+		if seen[section+"."+key] {
+			return
+		}
+		seen[section+"."+key] = true
+		entries = append(entries, txtparser.INIEntry{Section: section, Key: key, Operator: txtparser.OperatorEqual, Value: value})
+	}
+	for _, param := range params {
+		fields := strings.SplitN(strings.TrimSpace(param), ".", 2)
+		if len(fields) != 2 || fields[0] == "" || fields[1] == "" {
+			return nil, fmt.Errorf("wrong syntax '%s', expected 'section.key'", param)
+		}
+		section, key := fields[0], fields[1]
+		keys, ok := captureSections[section]
+		if !ok {
+			return nil, fmt.Errorf("capturing the values of section [%s] is not supported", section)
+		}
+		if keys != nil && !system.IsStringInList(key, keys) {
+			return nil, fmt.Errorf("capturing the value of '%s' in section [%s] is not supported. Supported are '%s'", key, section, strings.Join(keys, "', '"))
+		}
+		switch section {
+		case INISectionSysctl:
+			sysctlKeys := []string{key}
+			if strings.ContainsAny(key, "*?[") {
+				sysctlKeys = system.ListSysctlKeys(key)
+				if len(sysctlKeys) == 0 {
+					system.WarningLog("no sysctl keys found matching '%s'", key)
+				}
+			}
+			for _, sysctlKey := range sysctlKeys {
+				val, err := system.GetSysctlString(sysctlKey)
+				if err != nil {
+					continue
+				}
+				add(section, sysctlKey, strings.Join(strings.Fields(val), " "))
+			}
+		case INISectionBlock:
+			if val := captureBlockValue(key); val != "" {
+				add(section, key, val)
+			}
+		case INISectionCPU:
+			if val := captureCPUValue(key); val != "" {
+				add(section, key, val)
+			}
+		case INISectionVM:
+			if val := GetVMVal(key); val != "" {
+				add(section, key, val)
+			}
+		case INISectionService:
+			val := GetServiceVal(key)
+			if val == "NA" {
+				system.WarningLog("service '%s' not available on the system. Skipping.", key)
+				continue
+			}
+			add(section, key, val)
+		}
+	}
+	return entries, nil
+}
+
+// captureBlockValue returns the most common value of a [block] parameter
+// of all block devices of the system
+func captureBlockValue(key string) string {
+	blck := param.BlockDeviceQueue{BlockDeviceSchedulers: param.BlockDeviceSchedulers{SchedulerChoice: make(map[string]string)}, BlockDeviceNrRequests: param.BlockDeviceNrRequests{NrRequests: make(map[string]int)}, BlockDeviceReadAheadKB: param.BlockDeviceReadAheadKB{ReadAheadKB: make(map[string]int)}}
+	vals := []string{}
+	for _, bdev := range system.CollectBlockDeviceInfo() {
+		if val, _, err := GetBlkVal(key+"_"+bdev, &blck); err == nil && val != "" {
+			vals = append(vals, val)
+		}
+	}
+	val, differ := commonValue(vals)
+	if val == "" {
+		system.WarningLog("no value found for '%s' on the block devices of the system. Skipping.", key)
+	} else if differ {
+		system.WarningLog("the values of '%s' differ between the block devices, using the most common value '%s'", key, val)
+	}
+	return val
+}
+
+// captureCPUValue returns the value of a [cpu] parameter in the syntax of
+// the Note definition files. If the value differs between the cpus, the
+// most common value is used
+func captureCPUValue(key string) string {
+	cpuVal, _, _ := GetCPUVal(key)
+	vals := []string{}
+	for _, entry := range strings.Fields(cpuVal) {
+		// 'all:<value>' or 'cpu<n>:<value>'
+		fields := strings.Split(entry, ":")
+		vals = append(vals, fields[len(fields)-1])
+	}
+	val, differ := commonValue(vals)
+	if val == "" || val == "none" {
+		system.WarningLog("'%s' is not supported on the system. Skipping.", key)
+		return ""
+	}
+	if differ {
+		system.WarningLog("the values of '%s' differ between the cpus, using the most common value '%s'", key, val)
+	}
+	if key == "energy_perf_bias" {
+		name, ok := perfBiasNames[val]
+		if !ok {
+			system.WarningLog("energy_perf_bias value '%s' can not be expressed as 'performance', 'normal' or 'powersave'. Skipping.", val)
+			return ""
+		}
+		val = name
+	}
+	return val
+}
+
+// commonValue returns the most common value of the list and true, if the
+// values of the list differ
+func commonValue(vals []string) (string, bool) {
+	count := make(map[string]int)
+	for _, val := range vals {
+		count[val]++
+	}
+	common := ""
+	for val, cnt := range count {
+		if common == "" || cnt > count[common] || (cnt == count[common] && val < common) {
+			common = val
+		}
+	}
+	return common, len(count) > 1
+}
+
+// CapturedNoteContent returns the content of a Note definition file with
+// a generated [version] header for the captured entries
+// the sections are written in the order of their first appearance
+func CapturedNoteContent(noteID, name, date, host string, entries []txtparser.INIEntry) string {
+	var ret bytes.Buffer
+	ret.WriteString(fmt.Sprintf("# %s - %s\n", noteID, name))
+	ret.WriteString(fmt.Sprintf("# captured from host '%s' on %s by 'saptune note create --from-system'\n\n", host, date))
+	ret.WriteString(fmt.Sprintf("[version]\n# SAP-NOTE=%s CATEGORY=custom VERSION=1 DATE=%s NAME=\"%s\"\n", noteID, date, name))
+	sections := []string{}
+	sectEntries := make(map[string][]txtparser.INIEntry)
+	for _, entry := range entries {
+		if _, ok := sectEntries[entry.Section]; !ok {
+			sections = append(sections, entry.Section)
+		}
+		sectEntries[entry.Section] = append(sectEntries[entry.Section], entry)
+	}
+	for _, section := range sections {
+		ret.WriteString(fmt.Sprintf("\n[%s]\n", section))
+		for _, entry := range sectEntries[section] {
+			ret.WriteString(fmt.Sprintf("%s %s %s\n", entry.Key, entry.Operator, entry.Value))
+		}
+	}
+	return ret.String()
+}
@@ -0,0 +1,53 @@
+package note
+
+import (
+	"github.com/SUSE/saptune/txtparser"
+	"testing"
+)
+
+func TestCaptureSystemValues(t *testing.T) {
+	entries, err := CaptureSystemValues([]string{"sysctl.vm.max_map_count", "sysctl.vm.max_map_count"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Section != "sysctl" || entries[0].Key != "vm.max_map_count" || entries[0].Value == "" {
+		t.Errorf("wrong entries '%+v'", entries)
+	}
+	entries, err = CaptureSystemValues([]string{"sysctl.vm.max_map_c*"})
+	if err != nil || len(entries) != 1 || entries[0].Key != "vm.max_map_count" {
+		t.Errorf("wrong entries '%+v' - %v", entries, err)
+	}
+	for _, param := range []string{"swappiness", "rpm.glibc", "cpu.unknown"} {
+		if _, err := CaptureSystemValues([]string{param}); err == nil {
+			t.Errorf("'%s' should not be supported", param)
+		}
+	}
+}
+
+func TestCommonValue(t *testing.T) {
+	if val, differ := commonValue([]string{"mq-deadline", "none", "mq-deadline"}); val != "mq-deadline" || !differ {
+		t.Errorf("wrong result '%s', '%v'", val, differ)
+	}
+	if val, differ := commonValue([]string{"performance"}); val != "performance" || differ {
+		t.Errorf("wrong result '%s', '%v'", val, differ)
+	}
+	if val, _ := commonValue([]string{}); val != "" {
+		t.Errorf("wrong result '%s'", val)
+	}
+}
+
+func TestCapturedNoteContent(t *testing.T) {
+	entries := []txtparser.INIEntry{
+		{Section: "sysctl", Key: "vm.swappiness", Operator: "=", Value: "10"},
+		{Section: "vm", Key: "THP", Operator: "=", Value: "never"},
+		{Section: "sysctl", Key: "net.ipv4.tcp_rmem", Operator: "=", Value: "4096 131072 6291456"},
+	}
+	content := CapturedNoteContent("myNote", "Parameters captured from host1", "18.10.2026", "host1", entries)
+	if name := txtparser.GetINIDescriptiveName(content); name != "Parameters captured from host1\n\t\t\tVersion 1 from 18.10.2026 " {
+		t.Errorf("wrong header '%s'", name)
+	}
+	ini := txtparser.ParseINI(content)
+	if len(ini.AllValues) != 3 || ini.AllValues[1].Key != "net.ipv4.tcp_rmem" || ini.KeyValue["sysctl"]["net.ipv4.tcp_rmem"].Value != "4096\t131072\t6291456" || ini.KeyValue["vm"]["THP"].Value != "never" {
+		t.Errorf("wrong content '%s'", content)
+	}
+}
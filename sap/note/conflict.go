@@ -0,0 +1,93 @@
+package note
+
+// Detection of parameters, which are set to different values by more than
+// one of the enabled Notes
+
+import (
+	"fmt"
+	"github.com/SUSE/saptune/system"
+	"github.com/SUSE/saptune/txtparser"
+	"strings"
+)
+
+// conflictSkipSections are the sections of a Note definition, which are not
+// checked for conflicting values, because they do not tune the system or
+// only contain information
+var conflictSkipSections = []string{INISectionVersion, INISectionReminder, INISectionRpm, INISectionGrub, INISectionHardware, INISectionOS, INISectionFs}
+
+// ParameterConflict describes a parameter, which is set to different
+// values by the enabled Notes
+type ParameterConflict struct {
+	Section string
+	Key     string
+	Notes   []string // Notes setting the parameter in the order they are applied
+	Values  []string // expected values of the Notes ('<operator> <value>')
+}
+
+// Winner returns the Note, which was applied last and therefore sets the
+// value of the parameter on the system
+func (conflict ParameterConflict) Winner() string {
+	return conflict.Notes[len(conflict.Notes)-1]
+}
+
+// WinnerValue returns the value of the parameter, which is set by the
+// Note applied last
+func (conflict ParameterConflict) WinnerValue() string {
+	return strings.TrimSpace(strings.TrimLeft(conflict.Values[len(conflict.Values)-1], "=<>"))
+}
+
+// EffectiveINIFile returns the Note definition file of a Note merged with
+// the override file and the override drop-in files of the Note
+func EffectiveINIFile(confFilePath, overrideDir, noteID string) (*txtparser.INIFile, error) {
+	ini, err := txtparser.ParseINIFile(confFilePath, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(GetOverrideFiles(overrideDir, noteID)) != 0 {
+		ow, err := ParseOverrideFiles(overrideDir, noteID)
+		if err != nil {
+			return nil, err
+		}
+		ini.Merge(ow)
+	}
+	return ini, nil
+}
+
+// FindParameterConflicts compares the parameters of the Note definitions
+// of the given Notes and returns the parameters, which are set to different
+// values. The Notes have to be in the order they are applied.
+// Parameters with an empty value ('untouched') are not considered.
+func FindParameterConflicts(noteIDs []string, inis map[string]*txtparser.INIFile) []ParameterConflict {
+	conflicts := []ParameterConflict{}
+	params := []string{}
+	found := make(map[string]*ParameterConflict)
+	for _, noteID := range noteIDs {
+		ini, ok := inis[noteID]
+		if !ok {
+			continue
+		}
+		for _, entry := range ini.AllValues {
+			val := strings.Join(strings.Fields(entry.Value), " ")
+			if val == "" || system.IsStringInList(entry.Section, conflictSkipSections) {
+				continue
+			}
+			param := entry.Section + "." + entry.Key
+			if _, ok := found[param]; !ok {
+				params = append(params, param)
+				found[param] = &ParameterConflict{Section: entry.Section, Key: entry.Key}
+			}
+			found[param].Notes = append(found[param].Notes, noteID)
+			found[param].Values = append(found[param].Values, fmt.Sprintf("%s %s", entry.Operator, val))
+		}
+	}
+	for _, param := range params {
+		conflict := found[param]
+		for _, val := range conflict.Values[1:] {
+			if val != conflict.Values[0] {
+				conflicts = append(conflicts, *conflict)
+				break
+			}
+		}
+	}
+	return conflicts
+}
@@ -0,0 +1,66 @@
+package note
+
+import (
+	"github.com/SUSE/saptune/txtparser"
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+)
+
+func TestFindParameterConflicts(t *testing.T) {
+	inis := map[string]*txtparser.INIFile{
+		"4711": txtparser.ParseINI("[version]\n# SAP-NOTE=4711 VERSION=1\n\n[sysctl]\nvm.swappiness = 10\nvm.dirty_ratio = 10\nkernel.sem = 250  32000 32 1024\n\n[rpm]\nglibc all 2.22\n"),
+		"4712": txtparser.ParseINI("[version]\n# SAP-NOTE=4712 VERSION=2\n\n[sysctl]\nvm.swappiness = 60\nvm.dirty_ratio =\nkernel.sem = 250 32000 32 1024\n\n[rpm]\nglibc all 2.31\n"),
+		"4713": txtparser.ParseINI("[sysctl]\nvm.swappiness >= 30\n"),
+	}
+	conflicts := FindParameterConflicts([]string{"4712", "4711", "4713", "4714"}, inis)
+	exp := []ParameterConflict{
+		{Section: "sysctl", Key: "vm.swappiness", Notes: []string{"4712", "4711", "4713"}, Values: []string{"= 60", "= 10", ">= 30"}},
+	}
+	if !reflect.DeepEqual(conflicts, exp) {
+		t.Errorf("got: %+v, expected: %+v\n", conflicts, exp)
+	}
+	if conflicts[0].Winner() != "4713" {
+		t.Errorf("wrong winner '%s'\n", conflicts[0].Winner())
+	}
+	if conflicts[0].WinnerValue() != "30" {
+		t.Errorf("wrong winner value '%s'\n", conflicts[0].WinnerValue())
+	}
+	if conflicts := FindParameterConflicts([]string{"4711"}, inis); len(conflicts) != 0 {
+		t.Errorf("a single Note can not conflict: %+v\n", conflicts)
+	}
+}
+
+func TestEffectiveINIFile(t *testing.T) {
+	ovDir := path.Join(os.TempDir(), "saptune_effective")
+	defer os.RemoveAll(ovDir)
+	if err := os.MkdirAll(ovDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	noteFile := path.Join(ovDir, "base")
+	if err := ioutil.WriteFile(noteFile, []byte("[sysctl]\nvm.swappiness = 10\nvm.dirty_ratio = 10\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ini, err := EffectiveINIFile(noteFile, ovDir, "4711")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ini.KeyValue["sysctl"]["vm.swappiness"].Value != "10" {
+		t.Errorf("wrong value without override: %+v\n", ini.KeyValue["sysctl"])
+	}
+	if err := ioutil.WriteFile(path.Join(ovDir, "4711"), []byte("[sysctl]\nvm.swappiness = 60\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ini, err = EffectiveINIFile(noteFile, ovDir, "4711")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ini.KeyValue["sysctl"]["vm.swappiness"].Value != "60" || ini.KeyValue["sysctl"]["vm.dirty_ratio"].Value != "10" {
+		t.Errorf("wrong values with override: %+v\n", ini.KeyValue["sysctl"])
+	}
+	if _, err := EffectiveINIFile(path.Join(ovDir, "missing"), ovDir, "4711"); err == nil {
+		t.Error("should return an error for a missing Note definition file")
+	}
+}
@@ -0,0 +1,253 @@
+package note
+
+// Evaluate value expressions used in Note definition and override files
+// e.g. vm.nr_hugepages=${MEM_TOTAL_MB}*1024/${HUGEPAGE_SIZE_KB}/2
+
+import (
+	"fmt"
+	"github.com/SUSE/saptune/system"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var isExpression = regexp.MustCompile(`\$\{\w+\}`)
+
+// unit suffixes supported for numbers in value expressions
+var exprUnits = map[string]int64{
+	"K":  1024,
+	"KB": 1024,
+	"M":  1024 * 1024,
+	"MB": 1024 * 1024,
+	"G":  1024 * 1024 * 1024,
+	"GB": 1024 * 1024 * 1024,
+	"T":  1024 * 1024 * 1024 * 1024,
+	"TB": 1024 * 1024 * 1024 * 1024,
+}
+
+// exprFacts returns the system facts, which can be used as variables in
+// value expressions
+var exprFacts = func() map[string]int64 {
+	return map[string]int64{
+		"MEM_TOTAL_MB":     int64(system.GetMainMemSizeMB()),
+		"CPU_COUNT":        int64(system.GetCPUCount()),
+		"NUMA_NODES":       int64(system.GetNumaNodes()),
+		"HUGEPAGE_SIZE_KB": int64(system.GetHugepageSizeKB()),
+	}
+}
+
+// IsExpression returns true, if the parameter value contains a value
+// expression, which needs to be evaluated
+func IsExpression(value string) bool {
+	return isExpression.MatchString(value)
+}
+
+// EvaluateExpression resolves the system facts used in the value expression
+// and calculates the resulting integer value
+// supported are the operators '+', '-', '*', '/', '%', parentheses,
+// the functions min() and max() and the unit suffixes K, M, G and T
+// (or KB, MB, GB and TB) for numbers
+func EvaluateExpression(expr string) (string, error) {
+	p := exprParser{input: expr, facts: exprFacts()}
+	val, err := p.parseExpr()
+	if err != nil {
+		return "", fmt.Errorf("wrong expression '%s' - %v", expr, err)
+	}
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return "", fmt.Errorf("wrong expression '%s' - unexpected character '%c' at position %d", expr, p.input[p.pos], p.pos+1)
+	}
+	return strconv.FormatInt(val, 10), nil
+}
+
+// exprParser is a simple recursive descent parser for value expressions
+type exprParser struct {
+	input string
+	pos   int
+	facts map[string]int64
+}
+
+// skipSpaces skips white spaces in the input
+func (p *exprParser) skipSpaces() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+// peek returns the next non white space character or 0 at the end of
+// the input
+func (p *exprParser) peek() byte {
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+// parseExpr handles additions and subtractions
+func (p *exprParser) parseExpr() (int64, error) {
+	val, err := p.parseTerm()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		op := p.peek()
+		if op != '+' && op != '-' {
+			return val, nil
+		}
+		p.pos++
+		rval, err := p.parseTerm()
+		if err != nil {
+			return 0, err
+		}
+		if op == '+' {
+			val = val + rval
+		} else {
+			val = val - rval
+		}
+	}
+}
+
+// parseTerm handles multiplications, divisions and modulo operations
+func (p *exprParser) parseTerm() (int64, error) {
+	val, err := p.parseFactor()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		op := p.peek()
+		if op != '*' && op != '/' && op != '%' {
+			return val, nil
+		}
+		p.pos++
+		rval, err := p.parseFactor()
+		if err != nil {
+			return 0, err
+		}
+		switch op {
+		case '*':
+			val = val * rval
+		case '/', '%':
+			if rval == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			if op == '/' {
+				val = val / rval
+			} else {
+				val = val % rval
+			}
+		}
+	}
+}
+
+// parseFactor handles numbers, system facts, functions, parentheses and
+// the unary minus
+func (p *exprParser) parseFactor() (int64, error) {
+	switch c := p.peek(); {
+	case c == 0:
+		return 0, fmt.Errorf("unexpected end of expression")
+	case c == '-':
+		p.pos++
+		val, err := p.parseFactor()
+		return -val, err
+	case c == '(':
+		p.pos++
+		val, err := p.parseExpr()
+		if err != nil {
+			return 0, err
+		}
+		if p.peek() != ')' {
+			return 0, fmt.Errorf("missing ')'")
+		}
+		p.pos++
+		return val, nil
+	case c == '$':
+		return p.parseFact()
+	case c >= '0' && c <= '9':
+		return p.parseNumber()
+	case unicode.IsLetter(rune(c)):
+		return p.parseFunc()
+	default:
+		return 0, fmt.Errorf("unexpected character '%c' at position %d", c, p.pos+1)
+	}
+}
+
+// parseWord returns the next sequence of letters, digits and underscores
+func (p *exprParser) parseWord() string {
+	start := p.pos
+	for p.pos < len(p.input) && (unicode.IsLetter(rune(p.input[p.pos])) || unicode.IsDigit(rune(p.input[p.pos])) || p.input[p.pos] == '_') {
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+// parseNumber handles numbers with an optional unit suffix
+func (p *exprParser) parseNumber() (int64, error) {
+	start := p.pos
+	for p.pos < len(p.input) && p.input[p.pos] >= '0' && p.input[p.pos] <= '9' {
+		p.pos++
+	}
+	val, err := strconv.ParseInt(p.input[start:p.pos], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if unit := p.parseWord(); unit != "" {
+		mult, ok := exprUnits[strings.ToUpper(unit)]
+		if !ok {
+			return 0, fmt.Errorf("unsupported unit '%s'", unit)
+		}
+		val = val * mult
+	}
+	return val, nil
+}
+
+// parseFact handles the system facts (${NAME})
+func (p *exprParser) parseFact() (int64, error) {
+	p.pos++
+	if p.peek() != '{' {
+		return 0, fmt.Errorf("missing '{' after '$'")
+	}
+	p.pos++
+	name := p.parseWord()
+	if p.peek() != '}' {
+		return 0, fmt.Errorf("missing '}' after '${%s'", name)
+	}
+	p.pos++
+	val, ok := p.facts[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown variable '${%s}'", name)
+	}
+	return val, nil
+}
+
+// parseFunc handles the functions min() and max()
+func (p *exprParser) parseFunc() (int64, error) {
+	name := strings.ToLower(p.parseWord())
+	if name != "min" && name != "max" {
+		return 0, fmt.Errorf("unknown function '%s'", name)
+	}
+	if p.peek() != '(' {
+		return 0, fmt.Errorf("missing '(' after '%s'", name)
+	}
+	p.pos++
+	val, err := p.parseExpr()
+	if err != nil {
+		return 0, err
+	}
+	for p.peek() == ',' {
+		p.pos++
+		arg, err := p.parseExpr()
+		if err != nil {
+			return 0, err
+		}
+		if (name == "min" && arg < val) || (name == "max" && arg > val) {
+			val = arg
+		}
+	}
+	if p.peek() != ')' {
+		return 0, fmt.Errorf("missing ')' after arguments of '%s'", name)
+	}
+	p.pos++
+	return val, nil
+}
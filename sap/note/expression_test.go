@@ -0,0 +1,59 @@
+package note
+
+import (
+	"testing"
+)
+
+func TestIsExpression(t *testing.T) {
+	if !IsExpression("${MEM_TOTAL_MB}*75/100") {
+		t.Error("expression not detected")
+	}
+	if IsExpression("4096") || IsExpression("@sapsys soft nofile 32800") {
+		t.Error("plain value detected as expression")
+	}
+}
+
+func TestEvaluateExpression(t *testing.T) {
+	oldFacts := exprFacts
+	defer func() { exprFacts = oldFacts }()
+	exprFacts = func() map[string]int64 {
+		return map[string]int64{
+			"MEM_TOTAL_MB":     262144,
+			"CPU_COUNT":        64,
+			"NUMA_NODES":       4,
+			"HUGEPAGE_SIZE_KB": 2048,
+		}
+	}
+	for expr, exp := range map[string]string{
+		"${MEM_TOTAL_MB}*75/100":                       "196608",
+		"${MEM_TOTAL_MB} * 1024 / ${HUGEPAGE_SIZE_KB}": "131072",
+		"(${CPU_COUNT} + 2) * 2":                       "132",
+		"${CPU_COUNT} / ${NUMA_NODES} % 5":             "1",
+		"min(${MEM_TOTAL_MB}*1M, 128G)":                "137438953472",
+		"max(${CPU_COUNT}, 4K, 10)":                    "4096",
+		"-${NUMA_NODES} + 10":                          "6",
+		"2GB/1m":                                       "2048",
+	} {
+		val, err := EvaluateExpression(expr)
+		if err != nil {
+			t.Errorf("'%s': %v", expr, err)
+		}
+		if val != exp {
+			t.Errorf("'%s': expected '%s', got '%s'", expr, exp, val)
+		}
+	}
+	for _, expr := range []string{
+		"${UNKNOWN}",
+		"${MEM_TOTAL_MB}/0",
+		"${MEM_TOTAL_MB}*",
+		"(${CPU_COUNT}",
+		"avg(${CPU_COUNT}, 2)",
+		"${CPU_COUNT} 2",
+		"4X",
+		"${CPU_COUNT",
+	} {
+		if val, err := EvaluateExpression(expr); err == nil {
+			t.Errorf("'%s': expected an error, got '%s'", expr, val)
+		}
+	}
+}
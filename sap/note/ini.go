@@ -11,6 +11,7 @@ import (
 	"os"
 	"path"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -27,6 +28,7 @@ var pc = LinuxPagingImprovements{}
 var blck = param.BlockDeviceQueue{BlockDeviceSchedulers: param.BlockDeviceSchedulers{SchedulerChoice: make(map[string]string)}, BlockDeviceNrRequests: param.BlockDeviceNrRequests{NrRequests: make(map[string]int)}, BlockDeviceReadAheadKB: param.BlockDeviceReadAheadKB{ReadAheadKB: make(map[string]int)}}
 var isLimitSoft = regexp.MustCompile(`LIMIT_.*_soft_memlock`)
 var isLimitHard = regexp.MustCompile(`LIMIT_.*_hard_memlock`)
+var isLimitsPlaceholder = regexp.MustCompile(`^LIMIT_<\w+>`)
 var flstates = ""
 
 // Tuning options composed by a third party vendor.
@@ -90,6 +92,8 @@ type INISettings struct {
 	ValuesToApply   map[string]string // values to apply
 	OverrideParams  map[string]string // parameter values from the override file
 	Inform          map[string]string // special information for parameter values
+	Formulas        map[string]string // value expressions from the configuration or override file
+	SectionTags     map[string]string // conditional sections and their state (active or skipped)
 }
 
 // Name returns the name of the related SAP Note or en empty string
@@ -100,6 +104,23 @@ func (vend INISettings) Name() string {
 	return vend.DescriptiveName
 }
 
+// getSectionTags returns the conditional sections (sections with section
+// tags) of the configuration and the override file together with the
+// information, if the section is 'active' or 'skipped' on the running system
+func getSectionTags(ini, ow *txtparser.INIFile, override bool) map[string]string {
+	tags := make(map[string]string)
+	state := map[bool]string{true: "active", false: "skipped"}
+	for _, sect := range ini.TaggedSections {
+		tags["["+sect.Section+"]"] = state[sect.Active]
+	}
+	if override {
+		for _, sect := range ow.TaggedSections {
+			tags["["+sect.Section+"] (override)"] = state[sect.Active]
+		}
+	}
+	return tags
+}
+
 // Initialise retrieves the current parameter values from the system
 func (vend INISettings) Initialise() (Note, error) {
 	ini, err := vend.getSectionInfo(false)
@@ -117,22 +138,35 @@ func (vend INISettings) Initialise() (Note, error) {
 		}
 	}
 
-	// looking for override file
+	// looking for override file and override drop-in files
 	override := false
-	ow, err := txtparser.ParseINIFile(path.Join(OverrideTuningSheets, vend.ID), false)
+	ow, err := ParseOverrideFiles(OverrideTuningSheets, vend.ID)
 	if err == nil {
 		override = true
 	}
+	// limits missing in the override file disable the limits of the
+	// Note, limits missing in the override drop-in files are kept
+	legacyLimits := override && overrideFileHasSection(OverrideTuningSheets, vend.ID, INISectionLimits)
 	// Read current parameter values
 	vend.SysctlParams = make(map[string]string)
 	vend.OverrideParams = make(map[string]string)
 	vend.Inform = make(map[string]string)
+	vend.Formulas = make(map[string]string)
+	vend.SectionTags = getSectionTags(ini, ow, override)
 	pc = LinuxPagingImprovements{}
 	blck = param.BlockDeviceQueue{BlockDeviceSchedulers: param.BlockDeviceSchedulers{SchedulerChoice: make(map[string]string)}, BlockDeviceNrRequests: param.BlockDeviceNrRequests{NrRequests: make(map[string]int)}, BlockDeviceReadAheadKB: param.BlockDeviceReadAheadKB{ReadAheadKB: make(map[string]int)}}
 
 	for _, param := range ini.AllValues {
 		if override && len(ow.KeyValue[param.Section]) != 0 {
-			param.Key, param.Value, param.Operator = vend.handleInitOverride(param.Key, param.Value, param.Section, param.Operator, ow)
+			param.Key, param.Value, param.Operator = vend.handleInitOverride(param, ow, legacyLimits)
+		}
+		// remember value expressions for the verify output
+		if param.Section != INISectionReminder {
+			if IsExpression(vend.OverrideParams[param.Key]) {
+				vend.Formulas[param.Key] = vend.OverrideParams[param.Key]
+			} else if vend.OverrideParams[param.Key] == "" && IsExpression(param.Value) {
+				vend.Formulas[param.Key] = param.Value
+			}
 		}
 
 		switch param.Section {
@@ -143,7 +177,11 @@ func (vend INISettings) Initialise() (Note, error) {
 		case INISectionBlock:
 			vend.SysctlParams[param.Key], vend.Inform[param.Key], _ = GetBlkVal(param.Key, &blck)
 		case INISectionLimits:
-			vend.SysctlParams[param.Key], _ = GetLimitsVal(param.Value)
+			if isSystemdLimits(param.Key) {
+				vend.SysctlParams[param.Key] = GetSystemdLimitsVal(param.Key, ini.KeyValue[INISectionLimits])
+			} else {
+				vend.SysctlParams[param.Key], _ = GetLimitsVal(param.Value)
+			}
 		case INISectionService:
 			vend.SysctlParams[param.Key] = GetServiceVal(param.Key)
 		case INISectionLogin:
@@ -152,12 +190,23 @@ func (vend INISettings) Initialise() (Note, error) {
 			vend.SysctlParams[param.Key] = GetMemVal(param.Key)
 		case INISectionCPU:
 			vend.SysctlParams[param.Key], flstates, vend.Inform[param.Key] = GetCPUVal(param.Key)
+		case INISectionModule:
+			vend.SysctlParams[param.Key], vend.Inform[param.Key] = GetModuleVal(param.Key)
 		case INISectionRpm:
 			vend.SysctlParams[param.Key] = GetRpmVal(param.Key)
 			continue
 		case INISectionGrub:
 			vend.SysctlParams[param.Key] = GetGrubVal(param.Key)
 			continue
+		case INISectionHardware, INISectionOS:
+			vend.SysctlParams[param.Key] = GetPrereqVal(param.Key)
+			continue
+		case INISectionFs:
+			vend.SysctlParams[param.Key] = GetFsVal(param.Key, param.Value)
+			if _, check := splitFsKey(param.Key); check != "remount" {
+				// only checked, no saved state needed
+				continue
+			}
 		case INISectionReminder:
 			vend.SysctlParams[param.Key] = param.Value
 			continue
@@ -166,7 +215,9 @@ func (vend INISettings) Initialise() (Note, error) {
 		case INISectionPagecache:
 			// page cache is special, has it's own config file
 			// so adjust path to pagecache config file, if needed
-			if override {
+			// the page cache settings are only read from the
+			// override file, not from the override drop-in files
+			if fi, err := os.Stat(path.Join(OverrideTuningSheets, vend.ID)); err == nil && !fi.IsDir() {
 				pc.PagingConfig = path.Join(OverrideTuningSheets, vend.ID)
 			} else {
 				pc.PagingConfig = vend.ConfFilePath
@@ -179,6 +230,19 @@ func (vend INISettings) Initialise() (Note, error) {
 		// create parameter saved state file, if NOT in 'verify'
 		vend.createParamSavedStates(param.Key, flstates)
 	}
+
+	// check, if the systemd resource limits differ from the pam limits
+	// and, if requested, if the running processes use the limits
+	pamLimits := OptSystemdLimitsVal(vend.SysctlParams)
+	for key, val := range vend.SysctlParams {
+		if isSystemdLimits(key) && !CmpSystemdLimitsVal(val, pamLimits) {
+			vend.Inform[key] = "pamDiffers"
+		}
+		if RuntimeLimitsCheck && strings.HasPrefix(key, "LIMIT_") {
+			// compare with the limits of the running processes
+			vend.Inform[key] = GetLimitsRuntimeInfo(val)
+		}
+	}
 	return vend, nil
 }
 
@@ -186,6 +250,7 @@ func (vend INISettings) Initialise() (Note, error) {
 func (vend INISettings) Optimise() (Note, error) {
 	blckOK := make(map[string][]string)
 	scheds := ""
+	sdLimits := []string{}
 
 	// read saved section data == config data from configuration file
 	ini, err := vend.getSectionInfo(false)
@@ -205,12 +270,12 @@ func (vend INISettings) Optimise() (Note, error) {
 
 	for _, param := range ini.AllValues {
 		// Compare current values against INI's definition
-		if len(vend.OverrideParams) != 0 && vend.ID == "1805750" {
-			// as note 1805750 does not set a limits domain, but
-			// the customer should be able to set the correct
+		if len(vend.OverrideParams) != 0 && placeholderLimitsKey(param) != "" {
+			// as the limits domain of the Note is a placeholder,
+			// but the customer should be able to set the correct
 			// domain using an override file we need to rewrite
 			// param.Key and param.Value to get a correct behaviour
-			param.Key, param.Value = vend.handleID1805750(param.Key, param.Value)
+			param.Key, param.Value = vend.handleLimitsPlaceholder(param)
 		}
 		if len(vend.OverrideParams[param.Key]) != 0 {
 			// use value from override file instead of the value
@@ -223,19 +288,41 @@ func (vend INISettings) Optimise() (Note, error) {
 			}
 			param.Value = vend.OverrideParams[param.Key]
 		}
+		if param.Section != INISectionReminder && IsExpression(param.Value) {
+			// resolve value expression
+			val, err := EvaluateExpression(param.Value)
+			if err != nil {
+				system.WarningLog("Note %s: %v. Leaving parameter '%s' untouched.", vend.ID, err, param.Key)
+			}
+			param.Value = val
+		}
 		switch param.Section {
 		case INISectionSysctl:
 			//optimisedValue, err := CalculateOptimumValue(param.Operator, vend.SysctlParams[param.Key], param.Value)
 			//vend.SysctlParams[param.Key] = optimisedValue
 			vend.SysctlParams[param.Key] = OptSysctlVal(param.Operator, param.Key, vend.SysctlParams[param.Key], param.Value)
 		case INISectionVM:
-			vend.SysctlParams[param.Key] = OptVMVal(param.Key, param.Value)
+			if strings.HasPrefix(param.Key, "HUGEPAGES_") {
+				hpPercent := vend.OverrideParams["HUGEPAGES_PERCENT"]
+				if hpPercent == "untouched" || hpPercent == "" {
+					hpPercent = ini.KeyValue["vm"]["HUGEPAGES_PERCENT"].Value
+				}
+				vend.SysctlParams[param.Key] = OptHugepageVal(param.Key, param.Value, hpPercent)
+			} else {
+				vend.SysctlParams[param.Key] = OptVMVal(param.Key, param.Value)
+			}
 		case INISectionBlock:
 			vend.SysctlParams[param.Key], vend.Inform[param.Key] = OptBlkVal(param.Key, param.Value, &blck, blckOK)
 			if isSched.MatchString(param.Key) {
 				scheds = param.Value
 			}
 		case INISectionLimits:
+			if isSystemdLimits(param.Key) {
+				// needs the optimised values of all limits
+				// entries, so handled after the loop
+				sdLimits = append(sdLimits, param.Key)
+				continue
+			}
 			vend.SysctlParams[param.Key] = OptLimitsVal(vend.SysctlParams[param.Key], param.Value)
 		case INISectionService:
 			vend.SysctlParams[param.Key] = OptServiceVal(param.Key, param.Value)
@@ -249,12 +336,22 @@ func (vend INISettings) Optimise() (Note, error) {
 			}
 		case INISectionCPU:
 			vend.SysctlParams[param.Key] = OptCPUVal(param.Key, vend.SysctlParams[param.Key], param.Value)
+		case INISectionModule:
+			vend.SysctlParams[param.Key] = OptModuleVal(param.Key, param.Value)
 		case INISectionRpm:
 			vend.SysctlParams[param.Key] = OptRpmVal(param.Key, param.Value)
 			continue
 		case INISectionGrub:
 			vend.SysctlParams[param.Key] = OptGrubVal(param.Key, param.Value)
 			continue
+		case INISectionHardware, INISectionOS:
+			vend.SysctlParams[param.Key] = OptPrereqVal(param.Key, param.Value)
+			continue
+		case INISectionFs:
+			vend.SysctlParams[param.Key] = OptFsVal(param.Key, param.Value)
+			if _, check := splitFsKey(param.Key); check != "remount" {
+				continue
+			}
 		case INISectionReminder:
 			vend.SysctlParams[param.Key] = param.Value
 			continue
@@ -270,6 +367,25 @@ func (vend INISettings) Optimise() (Note, error) {
 		vend.addParamSavedStates(param.Key)
 	}
 
+	// systemd resource limits matching the limits entries
+	for _, key := range sdLimits {
+		vend.SysctlParams[key] = OptSystemdLimitsVal(vend.SysctlParams)
+		vend.addParamSavedStates(key)
+	}
+
+	// check the memlock limits against the hugepage pool size
+	if _, ok := vend.SysctlParams["HUGEPAGES_SIZE_MB"]; ok {
+		chkHugepageMemlock(vend.SysctlParams["HUGEPAGES_SIZE_MB"], vend.SysctlParams)
+	}
+
+	// the standard ports of the HANA instances should not be used as
+	// local ports, check the current system values only during 'verify'
+	if _, ok := vend.ValuesToApply["verify"]; ok {
+		if _, ok := ini.KeyValue[INISectionSysctl][SysctlReservedPorts]; ok {
+			ChkHANAPortRange(vend.ID)
+		}
+	}
+
 	// print info about used block scheduler only during 'verify' to
 	// supress double prints in case of 'apply'
 	if _, ok := vend.ValuesToApply["verify"]; ok && scheds != "" {
@@ -324,22 +440,28 @@ func (vend INISettings) Apply() error {
 	}
 
 	for _, param := range ini.AllValues {
-		if len(vend.OverrideParams) != 0 && vend.ID == "1805750" {
-			// as note 1805750 does not set a limits domain, but
-			// the customer should be able to set the correct
+		if len(vend.OverrideParams) != 0 && placeholderLimitsKey(param) != "" {
+			// as the limits domain of the Note is a placeholder,
+			// but the customer should be able to set the correct
 			// domain using an override file we need to rewrite
 			// param.Key and param.Value to get a correct behaviour
-			param.Key, param.Value = vend.handleID1805750(param.Key, param.Value)
+			param.Key, param.Value = vend.handleLimitsPlaceholder(param)
 		}
 
 		switch param.Section {
-		case INISectionVersion, INISectionRpm, INISectionGrub, INISectionReminder:
+		case INISectionVersion, INISectionRpm, INISectionGrub, INISectionHardware, INISectionOS, INISectionReminder:
 			// These parameters are only checked, but not applied.
 			// So nothing to do during apply and no need for revert
 			continue
+		case INISectionFs:
+			if _, check := splitFsKey(param.Key); check != "remount" {
+				continue
+			}
 		}
 
-		if _, ok := vend.ValuesToApply[param.Key]; !ok && !revertValues {
+		if _, ok := vend.ValuesToApply[param.Key]; !ok && (!revertValues || len(vend.ValuesToApply) > 1) {
+			// if parameters are listed in addition to 'revert'
+			// only these parameters are reverted
 			continue
 		}
 
@@ -364,7 +486,11 @@ func (vend INISettings) Apply() error {
 		case INISectionBlock:
 			errs = append(errs, SetBlkVal(param.Key, vend.SysctlParams[param.Key], &blck, revertValues))
 		case INISectionLimits:
-			errs = append(errs, SetLimitsVal(param.Key, pvendID, vend.SysctlParams[param.Key], revertValues))
+			if isSystemdLimits(param.Key) {
+				errs = append(errs, SetSystemdLimitsVal(param.Key, vend.ID, vend.SysctlParams[param.Key], revertValues))
+			} else {
+				errs = append(errs, SetLimitsVal(param.Key, pvendID, vend.SysctlParams[param.Key], revertValues))
+			}
 		case INISectionService:
 			errs = append(errs, SetServiceVal(param.Key, vend.SysctlParams[param.Key]))
 		case INISectionLogin:
@@ -373,6 +499,10 @@ func (vend INISettings) Apply() error {
 			errs = append(errs, SetMemVal(param.Key, vend.SysctlParams[param.Key]))
 		case INISectionCPU:
 			errs = append(errs, SetCPUVal(param.Key, vend.SysctlParams[param.Key], vend.ID, flstates, vend.OverrideParams[param.Key], vend.Inform[param.Key], revertValues))
+		case INISectionModule:
+			errs = append(errs, SetModuleVal(param.Key, pvendID, vend.SysctlParams[param.Key], revertValues))
+		case INISectionFs:
+			errs = append(errs, SetFsVal(param.Key, vend.SysctlParams[param.Key], param.Value, revertValues))
 		case INISectionPagecache:
 			if revertValues {
 				switch param.Key {
@@ -392,6 +522,40 @@ func (vend INISettings) Apply() error {
 	return err
 }
 
+// TunedParameters returns the parameters, which are set by an optimised
+// Note, together with their optimised values. Parameters, which are only
+// checked or are 'untouched' because of an override file, are not part of
+// the result.
+func (vend INISettings) TunedParameters() (map[string]string, error) {
+	ini, err := vend.getSectionInfo(false)
+	if err != nil {
+		// fallback, reading info from config file
+		ini, err = txtparser.ParseINIFile(vend.ConfFilePath, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+	tuned := make(map[string]string)
+	for _, param := range ini.AllValues {
+		if len(vend.OverrideParams) != 0 && placeholderLimitsKey(param) != "" {
+			param.Key, param.Value = vend.handleLimitsPlaceholder(param)
+		}
+		switch param.Section {
+		case INISectionVersion, INISectionRpm, INISectionGrub, INISectionHardware, INISectionOS, INISectionReminder:
+			continue
+		case INISectionFs:
+			if _, check := splitFsKey(param.Key); check != "remount" {
+				continue
+			}
+		}
+		if vend.OverrideParams[param.Key] == "untouched" || vend.SysctlParams[param.Key] == "" {
+			continue
+		}
+		tuned[param.Key] = vend.SysctlParams[param.Key]
+	}
+	return tuned, nil
+}
+
 // SetValuesToApply fills the data structure for applying the changes
 func (vend INISettings) SetValuesToApply(values []string) Note {
 	vend.ValuesToApply = make(map[string]string)
@@ -536,23 +700,83 @@ func CleanUpRun() {
 	}
 }
 
-// handleID1805750 handles the special case of SAP Note 1805750
-func (vend INISettings) handleID1805750(key, val string) (string, string) {
-	// as note 1805750 does not set a limits domain, but
-	// the customer should be able to set the correct
-	// domain using an override file we need to rewrite
-	// param.Key and param.Value to get a correct behaviour
-	for owkey, owval := range vend.OverrideParams {
-		if (isLimitSoft.MatchString(key) && isLimitSoft.MatchString(owkey)) || (isLimitHard.MatchString(key) && isLimitHard.MatchString(owkey)) {
-			key = owkey
-			val = owval
+// handleLimitsPlaceholder handles limits entries of a Note, which use a
+// placeholder as domain (e.g. <DBMSuser> in SAP Note 1805750). The entry
+// from the override file with the same type and item is used instead, so
+// the domain of the override file wins, even if the placeholder was
+// resolved (e.g. from the discovered SAP users).
+func (vend INISettings) handleLimitsPlaceholder(param txtparser.INIEntry) (string, string) {
+	owkey := matchingLimitsKey(param, vend.OverrideParams)
+	if owkey == "" {
+		return param.Key, param.Value
+	}
+	return owkey, vend.OverrideParams[owkey]
+}
+
+// placeholderLimitsKey returns the limits key with the placeholder as
+// domain (e.g. LIMIT_<DBMSuser>_hard_memlock), if the domain of the limits
+// entry of the Note is a placeholder, resolved or not. Otherwise an empty
+// string is returned
+func placeholderLimitsKey(param txtparser.INIEntry) string {
+	if param.Section != INISectionLimits {
+		return ""
+	}
+	if isLimitsPlaceholder.MatchString(param.Key) {
+		return param.Key
+	}
+	lim := strings.Fields(param.Unresolved)
+	if len(lim) < 3 {
+		return ""
+	}
+	phKey := fmt.Sprintf("LIMIT_%s_%s_%s", lim[0], lim[1], lim[2])
+	if !isLimitsPlaceholder.MatchString(phKey) {
+		return ""
+	}
+	return phKey
+}
+
+// matchingLimitsKey returns the key of the override limits entry, which
+// replaces the limits entry of the Note with a placeholder as domain.
+// An override entry for exactly the same key wins, otherwise the entry
+// with the same type and item is used.
+// An empty string is returned, if no override entry matches
+func matchingLimitsKey(param txtparser.INIEntry, owKeys map[string]string) string {
+	phKey := placeholderLimitsKey(param)
+	if phKey == "" {
+		return ""
+	}
+	if _, ok := owKeys[param.Key]; ok && !txtparser.HasPlaceholder(param.Key) {
+		return param.Key
+	}
+	keys := make([]string, 0, len(owKeys))
+	for owkey := range owKeys {
+		keys = append(keys, owkey)
+	}
+	sort.Strings(keys)
+	for _, owkey := range keys {
+		if isSameLimit(phKey, owkey) {
+			return owkey
 		}
 	}
-	return key, val
+	return ""
+}
+
+// isSameLimit returns true, if the limits keys of Note and override
+// file describe the same type and item (LIMIT_<domain>_<type>_<item>)
+// the domain of the Note key is a placeholder
+func isSameLimit(key, owkey string) bool {
+	placeholder := isLimitsPlaceholder.FindString(key)
+	if placeholder == "" || !strings.HasPrefix(owkey, "LIMIT_") || txtparser.HasPlaceholder(owkey) {
+		return false
+	}
+	return strings.HasSuffix(owkey, strings.TrimPrefix(key, placeholder))
 }
 
 // handleInitOverride handles the override parameter settings
-func (vend INISettings) handleInitOverride(key, val, section string, op txtparser.Operator, over *txtparser.INIFile) (string, string, txtparser.Operator) {
+// legacyLimits is true, if the override file (not the drop-in files)
+// contains a limits section
+func (vend INISettings) handleInitOverride(param txtparser.INIEntry, over *txtparser.INIFile, legacyLimits bool) (string, string, txtparser.Operator) {
+	key, val, section, op := param.Key, param.Value, param.Section, param.Operator
 	chkKey := key
 	if section == "service" {
 		cKey := strings.TrimSuffix(chkKey, ".service")
@@ -560,30 +784,41 @@ func (vend INISettings) handleInitOverride(key, val, section string, op txtparse
 			chkKey = cKey
 		}
 	}
-	if vend.ID == "1805750" {
-		// as note 1805750 does not set a limits
-		// domain, but the customer should be able to
-		// set the correct domain using an override
-		// file we need to rewrite param.Key and
-		// param.Value to get a correct behaviour
+	if section == INISectionLimits && placeholderLimitsKey(param) != "" {
+		// as the limits domain of the Note is a placeholder, but the
+		// customer should be able to set the correct domain using an
+		// override file we need to rewrite param.Key and param.Value
+		// to get a correct behaviour
+		owKeys := make(map[string]string)
 		for owkey, owparam := range over.KeyValue[section] {
-			if (isLimitSoft.MatchString(key) && isLimitSoft.MatchString(owkey)) || (isLimitHard.MatchString(key) && isLimitHard.MatchString(owkey)) {
-				chkKey = owkey
-				key = owkey
-				val = owparam.Value
-			}
+			owKeys[owkey] = owparam.Value
+		}
+		if owkey := matchingLimitsKey(param, owKeys); owkey != "" {
+			chkKey = owkey
+			key = owkey
+			val = owKeys[owkey]
 		}
 	}
-	if over.KeyValue[section][chkKey].Value == "" && section != INISectionPagecache && (over.KeyValue[section][chkKey].Key != "" || (section == INISectionLimits && over.KeyValue[section][chkKey].Key == "")) {
+	ovEntry := over.KeyValue[section][chkKey]
+	if section == INISectionLimits && ovEntry.Key != "" && len(strings.Fields(ovEntry.Value)) == 3 {
+		// a limit definition without value ('domain type item')
+		// leaves this limit untouched
+		ovEntry.Value = ""
+	}
+	// limits missing in the override file are disabled as before, but
+	// not the limits missing in the override drop-in files, as they only
+	// contain the changed limits. An empty LIMITS parameter (LIMITS_NA)
+	// disables all limits of the Note
+	if ovEntry.Value == "" && section != INISectionPagecache && (ovEntry.Key != "" || (section == INISectionLimits && (legacyLimits || over.KeyValue[section]["LIMITS_NA"].Key != ""))) {
 		// disable parameter setting in override file
 		vend.OverrideParams[chkKey] = "untouched"
 	}
-	if over.KeyValue[section][chkKey].Value != "" {
-		vend.OverrideParams[chkKey] = over.KeyValue[section][chkKey].Value
-		if over.KeyValue[section][chkKey].Operator != op {
+	if ovEntry.Value != "" {
+		vend.OverrideParams[chkKey] = ovEntry.Value
+		if ovEntry.Operator != op {
 			// operator from override file will
 			// replace the operator from our note file
-			op = over.KeyValue[section][chkKey].Operator
+			op = ovEntry.Operator
 		}
 	}
 	return key, val, op
@@ -10,6 +10,7 @@ import (
 	"os"
 	"path"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -29,8 +30,14 @@ const (
 	INISectionRpm       = "rpm"
 	INISectionGrub      = "grub"
 	INISectionReminder  = "reminder"
+	INISectionModule    = "module"
+	INISectionHardware  = "hardware"
+	INISectionOS        = "os"
+	INISectionFs        = "fs"
 	SysKernelTHPEnabled = "kernel/mm/transparent_hugepage/enabled"
 	SysKSMRun           = "kernel/mm/ksm/run"
+	SysctlReservedPorts = "net.ipv4.ip_local_reserved_ports"
+	SysctlPortRange     = "net.ipv4.ip_local_port_range"
 
 	// LoginConfDir is the path to systemd's logind configuration directory under /etc.
 	LogindConfDir = "/etc/systemd/logind.conf.d"
@@ -38,12 +45,18 @@ const (
 	LogindSAPConfFile = "saptune-UserTasksMax.conf"
 )
 
+// INISections contains all supported sections of a Note definition file
+var INISections = []string{INISectionSysctl, INISectionVM, INISectionCPU, INISectionMEM, INISectionBlock, INISectionService, INISectionLimits, INISectionLogin, INISectionVersion, INISectionPagecache, INISectionRpm, INISectionGrub, INISectionReminder, INISectionModule, INISectionHardware, INISectionOS, INISectionFs}
+
 // section handling
 // section [sysctl]
 
 // OptSysctlVal optimises a sysctl parameter value
 // use exactly the value from the config file. No calculation any more
 func OptSysctlVal(operator txtparser.Operator, key, actval, cfgval string) string {
+	if key == SysctlReservedPorts {
+		return OptReservedPortsVal(actval, cfgval)
+	}
 	if actval == "" {
 		// sysctl parameter not available in system
 		return ""
@@ -91,6 +104,40 @@ func OptSysctlVal(operator txtparser.Operator, key, actval, cfgval string) strin
 	return strings.TrimSpace(allFieldsS)
 }
 
+// OptReservedPortsVal returns the reserved ports of the config file merged
+// with the ports already reserved on the system
+func OptReservedPortsVal(actval, cfgval string) string {
+	if cfgval == "" || txtparser.HasPlaceholder(cfgval) {
+		// leave untouched, if the placeholder for the HANA ports
+		// could not be resolved
+		return ""
+	}
+	return system.MergePortLists(actval, cfgval)
+}
+
+// ChkHANAPortRange warns, if standard ports of the HANA instances are part
+// of the current local port range of the system, but not reserved.
+// The unreserved ports are returned
+func ChkHANAPortRange(noteID string) string {
+	portRange, _ := system.GetSysctlString(SysctlPortRange)
+	reserved, _ := system.GetSysctlString(SysctlReservedPorts)
+	return chkHANAPortRange(noteID, portRange, reserved)
+}
+
+// chkHANAPortRange warns, if standard ports of the HANA instances are part
+// of the given local port range, but not part of the given reserved ports
+func chkHANAPortRange(noteID, portRange, reserved string) string {
+	hanaPorts := txtparser.GetHANAPorts()
+	if hanaPorts == "" {
+		return ""
+	}
+	unreserved := system.UnreservedPortsInRange(hanaPorts, portRange, reserved)
+	if unreserved != "" {
+		system.WarningLog("Note %s: the HANA standard ports '%s' are part of the local port range '%s' (%s), but not reserved (%s). Please check the HANA instance numbers (HANA_INSTANCE_NUMBERS in %s) or the configuration of the SAP Host Agent.", noteID, unreserved, strings.Join(strings.Fields(portRange), " "), SysctlPortRange, SysctlReservedPorts, txtparser.SaptuneSysconfig)
+	}
+	return unreserved
+}
+
 // section [block]
 
 var isSched = regexp.MustCompile(`^IO_SCHEDULER_\w+$`)
@@ -266,6 +313,13 @@ func SetLimitsVal(key, noteID, value string, revert bool) error {
 		lim := strings.Fields(limit)
 		// dom=[0], type=[1], item=[2], value=[3]
 
+		if txtparser.HasPlaceholder(lim[0]) {
+			// placeholder could not be resolved, no user to
+			// apply the limit for
+			system.WarningLog("limits domain '%s' of parameter '%s' is a placeholder, which could not be resolved. Skipping.", lim[0], key)
+			return nil
+		}
+
 		// /etc/security/limits.d/saptune-<domain>-<item>-<type>.conf
 		dropInFile := fmt.Sprintf("/etc/security/limits.d/saptune-%s-%s-%s.conf", lim[0], lim[2], lim[1])
 
@@ -293,6 +347,103 @@ func SetLimitsVal(key, noteID, value string, revert bool) error {
 	return err
 }
 
+// RuntimeLimitsCheck enables the check of the limits against the effective
+// limits of the running processes during verify
+var RuntimeLimitsCheck = false
+
+// GetLimitsRuntimeInfo returns the effective limit of the running processes
+// of the limits domain as information for the verify output
+// 'runtime:<value>' - the running processes use the configured limit
+// 'restart:<value>' - the processes need a restart to use the configured limit
+// an empty string is returned, if no process of the domain is running
+func GetLimitsRuntimeInfo(value string) string {
+	lim := strings.Fields(value)
+	// dom=[0], type=[1], item=[2], value=[3]
+	if len(lim) != 4 || lim[3] == "NA" || txtparser.HasPlaceholder(lim[0]) {
+		return ""
+	}
+	runtimeLimit := system.GetRuntimeLimit(lim[0], lim[1], lim[2])
+	if runtimeLimit == "" {
+		return ""
+	}
+	if system.CmpLimitValues(runtimeLimit, lim[3]) != 0 {
+		return "restart:" + runtimeLimit
+	}
+	return "runtime:" + runtimeLimit
+}
+
+// systemd resource limits of the [limits] section
+// key 'systemd-limits:<unit>' or 'systemd-limits:default'
+
+// isSystemdLimits returns true, if the key is a systemd resource limits key
+func isSystemdLimits(key string) bool {
+	return strings.HasPrefix(key, "systemd-limits:")
+}
+
+// systemdLimitsUnit returns the unit name of a systemd resource limits key
+func systemdLimitsUnit(key string) string {
+	return strings.TrimPrefix(key, "systemd-limits:")
+}
+
+// GetSystemdLimitsVal returns the current systemd resource limits of the
+// unit for all limits items used in the limits entries of the Note
+func GetSystemdLimitsVal(key string, limits map[string]txtparser.INIEntry) string {
+	props := []string{}
+	for _, entry := range limits {
+		for prop := range system.SystemdLimitProps(strings.Fields(entry.Value)) {
+			if !system.IsStringInList(prop, props) {
+				props = append(props, prop)
+			}
+		}
+	}
+	sort.Strings(props)
+	return system.SystemdLimitsToString(system.GetSystemdLimits(systemdLimitsUnit(key), props))
+}
+
+// OptSystemdLimitsVal returns the systemd resource limits matching the
+// limits entries (LIMIT_<domain>_<type>_<item>) of the parameter list
+// if more than one domain uses the same item, the largest value wins
+func OptSystemdLimitsVal(params map[string]string) string {
+	props := make(map[string]string)
+	for key, val := range params {
+		if !strings.HasPrefix(key, "LIMIT_") || txtparser.HasPlaceholder(key) {
+			continue
+		}
+		for prop, pval := range system.SystemdLimitProps(strings.Fields(val)) {
+			props[prop] = system.MaxSystemdLimit(props[prop], pval)
+		}
+	}
+	return system.SystemdLimitsToString(props)
+}
+
+// SetSystemdLimitsVal writes or removes the systemd resource limits drop-in
+// file of the Note for the unit or the systemd manager
+func SetSystemdLimitsVal(key, noteID, value string, revert bool) error {
+	unit := systemdLimitsUnit(key)
+	if revert || value == "" {
+		return system.RemoveSystemdLimitsDropIn(unit, noteID)
+	}
+	if unit != system.SystemdLimitsManager {
+		system.InfoLog("the resource limits of unit '%s' will be active after the next restart of the unit", unit)
+	}
+	return system.ApplySystemdLimitsDropIn(unit, noteID, system.SystemdLimitsFromString(value))
+}
+
+// CmpSystemdLimitsVal compares the systemd resource limits
+// all expected limits need to match, additional limits are ignored
+func CmpSystemdLimitsVal(actval, expval string) bool {
+	if actval == expval {
+		return true
+	}
+	act := system.SystemdLimitsFromString(actval)
+	for prop, val := range system.SystemdLimitsFromString(expval) {
+		if act[prop] != val {
+			return false
+		}
+	}
+	return true
+}
+
 // section [vm]
 // Manipulate /sys/kernel/mm switches.
 
@@ -306,10 +457,25 @@ func GetVMVal(key string) string {
 	case "KSM":
 		ksmval, _ := system.GetSysInt(SysKSMRun)
 		val = strconv.Itoa(ksmval)
+	case "HUGEPAGES_PERCENT":
+		// rounded value
+		percent := math.Floor(float64(getHugepagePoolMB())*100/float64(system.GetMainMemSizeMB()) + 0.5)
+		val = strconv.FormatFloat(percent, 'f', -1, 64)
+	case "HUGEPAGES_SIZE_MB":
+		val = strconv.FormatUint(getHugepagePoolMB(), 10)
+	case "HUGEPAGES_GROUP":
+		gid, _ := system.GetSysctlString("vm.hugetlb_shm_group")
+		val = system.GetGroupName(gid)
 	}
 	return val
 }
 
+// getHugepagePoolMB returns the size of the current hugepage pool in MB
+func getHugepagePoolMB() uint64 {
+	nr, _ := system.GetSysctlUint64("vm.nr_hugepages")
+	return nr * system.GetHugepageSizeKB() / 1024
+}
+
 // OptVMVal optimises the memory management structure with the settings
 // from the configuration file
 func OptVMVal(key, cfgval string) string {
@@ -338,10 +504,92 @@ func SetVMVal(key, value string) error {
 	case "KSM":
 		ksmval, _ := strconv.Atoi(value)
 		err = system.SetSysInt(SysKSMRun, ksmval)
+	case "HUGEPAGES_SIZE_MB":
+		size, _ := strconv.ParseUint(value, 10, 64)
+		hpSize := system.GetHugepageSizeKB()
+		if hpSize == 0 {
+			return fmt.Errorf("hugepages are not supported by the system")
+		}
+		err = system.SetSysctlUint64("vm.nr_hugepages", size*1024/hpSize)
+	case "HUGEPAGES_GROUP":
+		gid, gerr := system.GetGroupID(value)
+		if gerr != nil {
+			return fmt.Errorf("failed to resolve group '%s' - %v", value, gerr)
+		}
+		err = system.SetSysctlString("vm.hugetlb_shm_group", gid)
 	}
 	return err
 }
 
+// OptHugepageVal optimises the hugepage settings of the memory management
+// structure with the settings from the configuration file or with a
+// calculation
+// hpPercent is the value of HUGEPAGES_PERCENT from config or override file
+func OptHugepageVal(key, cfgval, hpPercent string) string {
+	val := strings.TrimSpace(cfgval)
+	switch key {
+	case "HUGEPAGES_PERCENT":
+		if percent, err := strconv.Atoi(val); val != "" && (err != nil || percent < 0 || percent > 100) {
+			system.WarningLog("wrong value '%s' for HUGEPAGES_PERCENT. Only values between 0 and 100 are supported, leaving hugepages untouched.", cfgval)
+			val = ""
+		}
+	case "HUGEPAGES_SIZE_MB":
+		if val != "" && val != "0" {
+			if _, err := strconv.ParseUint(val, 10, 64); err != nil {
+				system.WarningLog("wrong value '%s' for HUGEPAGES_SIZE_MB. Only integer values are supported, leaving hugepages untouched.", cfgval)
+				val = ""
+			}
+			break
+		}
+		val = ""
+		percent, err := strconv.ParseUint(strings.TrimSpace(hpPercent), 10, 64)
+		if err != nil || percent > 100 {
+			break
+		}
+		hpSize := system.GetHugepageSizeKB()
+		if hpSize == 0 {
+			system.WarningLog("hugepages are not supported by the system, leaving hugepages untouched.")
+			break
+		}
+		// Calculate hugepage pool size (MainMemSizeMB*HUGEPAGES_PERCENT/100)
+		// rounded down to a multiple of the hugepage size
+		size := system.GetMainMemSizeMB() * percent / 100 * 1024 / hpSize * hpSize / 1024
+		val = strconv.FormatUint(size, 10)
+	case "HUGEPAGES_GROUP":
+		if val == "" {
+			break
+		}
+		if _, err := system.GetGroupID(val); err != nil {
+			system.WarningLog("group '%s' for HUGEPAGES_GROUP not found on the system, leaving vm.hugetlb_shm_group untouched.", val)
+			val = ""
+		}
+	}
+	return val
+}
+
+// chkHugepageMemlock checks, if the memlock limits of the [limits] section
+// are large enough for the hugepage pool size
+func chkHugepageMemlock(sizeMB string, params map[string]string) {
+	size, err := strconv.ParseUint(sizeMB, 10, 64)
+	if err != nil || size == 0 {
+		return
+	}
+	for key, val := range params {
+		if !isLimitSoft.MatchString(key) && !isLimitHard.MatchString(key) {
+			continue
+		}
+		lim := strings.Fields(val)
+		if len(lim) != 4 || lim[3] == "unlimited" || lim[3] == "infinity" || lim[3] == "-1" {
+			continue
+		}
+		// memlock limit is in KB
+		memlock, err := strconv.ParseUint(lim[3], 10, 64)
+		if err == nil && memlock < size*1024 {
+			system.WarningLog("the %s memlock limit of '%s' (%d KB) is smaller than the hugepage pool size of %d MB. Hugepages can not be fully used, please check the [limits] section.", lim[1], lim[0], memlock, size)
+		}
+	}
+}
+
 // section [cpu]
 
 // GetCPUVal initialise the cpu performance structure with the current
@@ -558,6 +806,380 @@ func SetGrubVal(value string) error {
 	return nil
 }
 
+// section [hardware] and [os]
+
+// GetPrereqVal initialise the hardware and os prerequisite structure with
+// the current system settings
+func GetPrereqVal(key string) string {
+	val := ""
+	switch strings.SplitN(key, ":", 2)[1] {
+	case "MEM_MIN_MB":
+		val = strconv.FormatUint(system.GetMainMemSizeMB(), 10)
+	case "SWAP_MIN_MB":
+		val = strconv.FormatUint(system.GetSwapSizeMB(), 10)
+	case "CPU_VENDOR":
+		val = system.GetCPUVendor()
+	case "CPU_MODEL":
+		val = system.GetCPUModel()
+	case "VIRT":
+		val = system.GetVirtType()
+	case "OS_NAME":
+		val = system.GetOsName()
+	case "OS_VERSION":
+		val = system.GetOsVers()
+	case "KERNEL_MIN", "KERNEL_MAX":
+		val = system.GetKernelVersion()
+	default:
+		val = "NA"
+	}
+	return val
+}
+
+// OptPrereqVal returns the value from the configuration file
+func OptPrereqVal(key, cfgval string) string {
+	// nothing to do, only checking for 'verify'
+	val := strings.TrimSpace(cfgval)
+	switch strings.SplitN(key, ":", 2)[1] {
+	case "MEM_MIN_MB", "SWAP_MIN_MB":
+		if _, err := strconv.ParseUint(val, 10, 64); err != nil {
+			system.WarningLog("wrong value '%s' for prerequisite '%s'. Only integer values are supported, skipping the check.", cfgval, key)
+			val = ""
+		}
+	case "CPU_MODEL":
+		if _, err := regexp.Compile(val); err != nil {
+			system.WarningLog("wrong regular expression '%s' for prerequisite '%s' - %v, skipping the check.", cfgval, key, err)
+			val = ""
+		}
+	case "CPU_VENDOR", "VIRT", "OS_NAME", "OS_VERSION", "KERNEL_MIN", "KERNEL_MAX":
+	default:
+		system.WarningLog("unsupported prerequisite '%s', skipping the check.", key)
+		val = ""
+	}
+	return val
+}
+
+// CmpPrereqVal checks, if the current system setting fulfills the
+// prerequisite from the configuration file
+// MEM_MIN_MB and SWAP_MIN_MB are minimum sizes, KERNEL_MIN and KERNEL_MAX
+// are version limits, CPU_MODEL is a regular expression and all other
+// entries are comma separated lists of allowed values
+func CmpPrereqVal(key, actVal, expVal string) bool {
+	if actVal == "" || actVal == "NA" {
+		return false
+	}
+	switch strings.SplitN(key, ":", 2)[1] {
+	case "MEM_MIN_MB", "SWAP_MIN_MB":
+		act, _ := strconv.ParseUint(actVal, 10, 64)
+		exp, err := strconv.ParseUint(expVal, 10, 64)
+		return err == nil && act >= exp
+	case "KERNEL_MIN":
+		return system.CmpKernelVers(actVal, system.StripKernelFlavor(expVal)) >= 0
+	case "KERNEL_MAX":
+		return system.CmpKernelVers(actVal, system.StripKernelFlavor(expVal)) <= 0
+	case "CPU_MODEL":
+		re, err := regexp.Compile(expVal)
+		return err == nil && re.MatchString(actVal)
+	}
+	for _, val := range strings.Split(expVal, ",") {
+		if strings.EqualFold(strings.TrimSpace(val), actVal) {
+			return true
+		}
+	}
+	return false
+}
+
+// section [fs]
+
+// splitFsKey returns the mount point pattern and the check type of a key
+// from section [fs] (fs:<mount point pattern>:<check>)
+func splitFsKey(key string) (string, string) {
+	fsKey := strings.TrimPrefix(key, "fs:")
+	idx := strings.LastIndex(fsKey, ":")
+	if idx < 0 {
+		return fsKey, ""
+	}
+	return fsKey[:idx], fsKey[idx+1:]
+}
+
+// splitFsOptions returns the comma separated mount options as slice
+func splitFsOptions(options string) []string {
+	opts := make([]string, 0)
+	for _, opt := range strings.Split(options, ",") {
+		if opt = strings.TrimSpace(opt); opt != "" {
+			opts = append(opts, opt)
+		}
+	}
+	return opts
+}
+
+// GetFsVal initialise the file system structure with the current
+// system settings
+// for the check types 'options', 'fstab' and 'remount' the value contains
+// the expected mount options, which are set for all matching mount points,
+// or 'none', if none of the expected options is set
+func GetFsVal(key, cfgval string) string {
+	var mounts system.MountPoints
+	pattern, check := splitFsKey(key)
+	switch check {
+	case "type", "options", "remount":
+		mounts = system.ParseProcMounts().GetByMountPointGlob(pattern)
+	case "fstab":
+		if _, err := os.Stat("/etc/fstab"); err != nil {
+			return "NA"
+		}
+		mounts = system.ParseFstab().GetByMountPointGlob(pattern)
+	}
+	if len(mounts) == 0 {
+		return "NA"
+	}
+	if check == "type" {
+		types := make([]string, 0)
+		for _, mount := range mounts {
+			if !system.IsStringInList(mount.Type, types) {
+				types = append(types, mount.Type)
+			}
+		}
+		sort.Strings(types)
+		return strings.Join(types, ", ")
+	}
+	opts := make([]string, 0)
+	for _, opt := range splitFsOptions(cfgval) {
+		set := true
+		for _, mount := range mounts {
+			if !mount.HasOption(opt) {
+				set = false
+				break
+			}
+		}
+		if set {
+			opts = append(opts, opt)
+		}
+	}
+	if len(opts) == 0 {
+		return "none"
+	}
+	return strings.Join(opts, ",")
+}
+
+// OptFsVal returns the value from the configuration file
+func OptFsVal(key, cfgval string) string {
+	pattern, check := splitFsKey(key)
+	switch check {
+	case "type":
+		return strings.TrimSpace(cfgval)
+	case "options", "fstab":
+		return strings.Join(splitFsOptions(cfgval), ",")
+	case "remount":
+		opts := splitFsOptions(cfgval)
+		for _, opt := range opts {
+			if !system.IsRemountOption(opt) {
+				system.WarningLog("mount option '%s' for mount point '%s' can not be changed by a remount, so it is only checked.", opt, pattern)
+			}
+		}
+		return strings.Join(opts, ",")
+	}
+	system.WarningLog("unsupported file system check '%s' for mount point '%s'. Only 'type', 'options', 'fstab' or 'remount' are supported, skipping the check.", check, pattern)
+	return ""
+}
+
+// SetFsVal applies the settings to the system
+// only the check type 'remount' changes the mount options of the mounted
+// file systems, all other check types are only checked for 'verify'
+func SetFsVal(key, value, cfgval string, revert bool) error {
+	var err error
+	pattern, check := splitFsKey(key)
+	if check != "remount" {
+		// nothing to do, only checking for 'verify'
+		return nil
+	}
+	opts := make([]string, 0)
+	if revert {
+		// set the counterpart of all options, which were not
+		// set before
+		saved := splitFsOptions(value)
+		for _, opt := range splitFsOptions(cfgval) {
+			if system.IsRemountOption(opt) && !system.IsStringInList(opt, saved) {
+				opts = append(opts, system.RevertRemountOption(opt))
+			}
+		}
+	} else {
+		for _, opt := range splitFsOptions(value) {
+			if system.IsRemountOption(opt) {
+				opts = append(opts, opt)
+			}
+		}
+	}
+	if len(opts) == 0 {
+		return nil
+	}
+	for _, mount := range system.ParseProcMounts().GetByMountPointGlob(pattern) {
+		if rerr := system.RemountFS(mount.MountPoint, opts); rerr != nil {
+			system.WarningLog("failed to remount '%s' with options '%s' - %v", mount.MountPoint, strings.Join(opts, ","), rerr)
+			err = rerr
+		}
+	}
+	return err
+}
+
+// IsFsGlobKey returns true, if the mount point of a key from section [fs]
+// is a shell pattern (e.g. '/hana/data/*') and not an explicit mount point
+func IsFsGlobKey(key string) bool {
+	pattern, _ := splitFsKey(key)
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// CmpFsVal checks, if the current file system settings fulfill the
+// settings from the configuration file
+// a mount point pattern without matching mount points is not relevant
+// for the system and does not influence the compare result, but an
+// explicitly named mount point, which is not a separate file system
+// (e.g. /hana/log on the root file system), is not compliant
+func CmpFsVal(key, actVal, expVal string) bool {
+	if actVal == "NA" {
+		return IsFsGlobKey(key)
+	}
+	_, check := splitFsKey(key)
+	if check == "type" {
+		exp := strings.Split(expVal, ",")
+		for _, act := range strings.Split(actVal, ",") {
+			found := false
+			for _, val := range exp {
+				if strings.TrimSpace(val) == strings.TrimSpace(act) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	}
+	act := splitFsOptions(actVal)
+	for _, opt := range splitFsOptions(expVal) {
+		if !system.IsStringInList(opt, act) {
+			return false
+		}
+	}
+	return true
+}
+
+// section [module]
+
+// splitModuleKey splits the key of a [module] section entry into the
+// module name and the module parameter name
+// 'module:<module>' or 'module:<module>.<parameter>'
+func splitModuleKey(key string) (string, string) {
+	modKey := strings.TrimPrefix(key, "module:")
+	fields := strings.SplitN(modKey, ".", 2)
+	if len(fields) == 2 {
+		return fields[0], fields[1]
+	}
+	return modKey, ""
+}
+
+// GetModuleVal initialise the kernel module structure with the current
+// system settings
+// the second return value is set to 'reboot', if the persistent
+// configuration of the module differs from the runtime state and can only
+// be activated by a reboot or a reload of the module
+func GetModuleVal(key string) (string, string) {
+	val := ""
+	info := ""
+	module, param := splitModuleKey(key)
+	if param == "" {
+		switch {
+		case system.IsModuleLoaded(module):
+			val = "load"
+			if system.IsModuleBlacklisted(module) {
+				info = "reboot"
+			}
+		case system.IsModuleBlacklisted(module):
+			val = "blacklist"
+		case system.IsModuleAvailable(module):
+			val = "unload"
+		default:
+			val = "NA"
+		}
+		return val, info
+	}
+	val, err := system.GetModuleParam(module, param)
+	if err != nil {
+		return "NA", info
+	}
+	dropInVal := system.GetModuleDropInParam(module, param)
+	if dropInVal != "" && dropInVal != val && !system.ModuleParamIsWritable(module, param) {
+		info = "reboot"
+	}
+	return val, info
+}
+
+// OptModuleVal optimises the kernel module structure with the settings
+// from the configuration file
+func OptModuleVal(key, cfgval string) string {
+	_, param := splitModuleKey(key)
+	if param != "" {
+		return strings.TrimSpace(cfgval)
+	}
+	val := strings.ToLower(strings.TrimSpace(cfgval))
+	if val != "" && val != "load" && val != "blacklist" {
+		system.WarningLog("wrong selection '%s' for module '%s'. Only 'load' or 'blacklist' are supported, leaving the module untouched.", cfgval, strings.TrimPrefix(key, "module:"))
+		val = ""
+	}
+	return val
+}
+
+// SetModuleVal applies the settings to the system
+func SetModuleVal(key, noteID, value string, revert bool) error {
+	var err error
+	module, param := splitModuleKey(key)
+	if revert && IsLastNoteOfParameter(key) {
+		// revert - remove the module drop-in files of saptune
+		// and reset the runtime value of a module parameter
+		system.RemoveModuleDropIn(module, param)
+		if param != "" && value != "" && value != "NA" && system.ModuleParamIsWritable(module, param) {
+			err = system.SetModuleParam(module, param, value)
+		}
+		return err
+	}
+	if value == "" || value == "NA" {
+		return nil
+	}
+	if param != "" {
+		// revert with value from another former applied note
+		// or
+		// apply - prepare modprobe drop-in file
+		if err = system.WriteModuleDropIn(module, param, value, noteID); err != nil {
+			return err
+		}
+		if system.ModuleParamIsWritable(module, param) {
+			err = system.SetModuleParam(module, param, value)
+		} else {
+			system.InfoLog("module parameter '%s.%s' can not be changed at runtime. The new value will be active after the next reboot or reload of the module.", module, param)
+		}
+		return err
+	}
+	switch value {
+	case "load":
+		system.RemoveModuleDropIn(module, "")
+		if err = system.WriteModuleDropIn(module, "", value, noteID); err != nil {
+			return err
+		}
+		if !system.IsModuleLoaded(module) {
+			err = system.LoadModule(module)
+		}
+	case "blacklist":
+		system.RemoveModuleDropIn(module, "")
+		if err = system.WriteModuleDropIn(module, "", value, noteID); err != nil {
+			return err
+		}
+		if system.IsModuleLoaded(module) {
+			system.InfoLog("module '%s' is blacklisted, but still loaded. The blacklist will be active after the next reboot.", module)
+		}
+	}
+	return err
+}
+
 // section [service]
 
 // GetServiceVal initialise the systemd service structure with the current
@@ -4,6 +4,7 @@ import (
 	"github.com/SUSE/saptune/sap/param"
 	"github.com/SUSE/saptune/system"
 	"github.com/SUSE/saptune/txtparser"
+	"io/ioutil"
 	"os"
 	"path"
 	"strconv"
@@ -34,6 +35,42 @@ func TestGetServiceName(t *testing.T) {
 	}
 }
 
+func TestOptReservedPortsVal(t *testing.T) {
+	op := txtparser.Operator("=")
+	val := OptSysctlVal(op, SysctlReservedPorts, "30013,30015", "30014,50013-50014")
+	if val != "30013-30015,50013-50014" {
+		t.Error(val)
+	}
+	val = OptSysctlVal(op, SysctlReservedPorts, "", "30013")
+	if val != "30013" {
+		t.Error(val)
+	}
+	val = OptSysctlVal(op, SysctlReservedPorts, "30013", "<hanaports>")
+	if val != "" {
+		t.Error(val)
+	}
+	val = OptSysctlVal(op, SysctlReservedPorts, "30013", "")
+	if val != "" {
+		t.Error(val)
+	}
+}
+
+func TestChkHANAPortRange(t *testing.T) {
+	oldSysconfig := txtparser.SaptuneSysconfig
+	defer func() { txtparser.SaptuneSysconfig = oldSysconfig }()
+	txtparser.SaptuneSysconfig = path.Join(os.TempDir(), "saptune_sysconfig_hanaports")
+	defer os.Remove(txtparser.SaptuneSysconfig)
+	if err := ioutil.WriteFile(txtparser.SaptuneSysconfig, []byte("HANA_INSTANCE_NUMBERS=\"00\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if ports := chkHANAPortRange("2382421", "9000\t65499", "30000-30099"); ports != "50013-50014" {
+		t.Errorf("wrong unreserved ports '%s'", ports)
+	}
+	if ports := chkHANAPortRange("2382421", "9000\t65499", "4300,8000,30000-30099,50013-50014"); ports != "" {
+		t.Errorf("wrong unreserved ports '%s'", ports)
+	}
+}
+
 func TestOptSysctlVal(t *testing.T) {
 	// remember the change in saptune 2.0 (SAP and Alliance decision)
 	// use exactly the value from the config file. No calculation any more
@@ -202,6 +239,147 @@ func TestOptLimitsVal(t *testing.T) {
 
 //SetLimitsVal apply and revert
 
+func TestSplitModuleKey(t *testing.T) {
+	mod, param := splitModuleKey("module:sctp")
+	if mod != "sctp" || param != "" {
+		t.Errorf("got module '%s', param '%s'", mod, param)
+	}
+	mod, param = splitModuleKey("module:nf_conntrack.hashsize")
+	if mod != "nf_conntrack" || param != "hashsize" {
+		t.Errorf("got module '%s', param '%s'", mod, param)
+	}
+}
+
+func TestOptModuleVal(t *testing.T) {
+	val := OptModuleVal("module:sctp", " Load ")
+	if val != "load" {
+		t.Error(val)
+	}
+	val = OptModuleVal("module:floppy", "blacklist")
+	if val != "blacklist" {
+		t.Error(val)
+	}
+	val = OptModuleVal("module:floppy", "unload")
+	if val != "" {
+		t.Error(val)
+	}
+	val = OptModuleVal("module:nf_conntrack.hashsize", " 131072 ")
+	if val != "131072" {
+		t.Error(val)
+	}
+}
+
+func TestOptPrereqVal(t *testing.T) {
+	val := OptPrereqVal("hardware:MEM_MIN_MB", " 131072 ")
+	if val != "131072" {
+		t.Error(val)
+	}
+	val = OptPrereqVal("hardware:MEM_MIN_MB", "128G")
+	if val != "" {
+		t.Error(val)
+	}
+	val = OptPrereqVal("hardware:CPU_MODEL", "Xeon.*(Platinum")
+	if val != "" {
+		t.Error(val)
+	}
+	val = OptPrereqVal("os:OS_VERSION", "15-SP1, 15-SP2")
+	if val != "15-SP1, 15-SP2" {
+		t.Error(val)
+	}
+	val = OptPrereqVal("os:UNKNOWN", "value")
+	if val != "" {
+		t.Error(val)
+	}
+}
+
+func TestCmpPrereqVal(t *testing.T) {
+	if !CmpPrereqVal("hardware:MEM_MIN_MB", "262144", "131072") {
+		t.Error("memory size should match")
+	}
+	if CmpPrereqVal("hardware:SWAP_MIN_MB", "1024", "2048") {
+		t.Error("swap size should not match")
+	}
+	if !CmpPrereqVal("hardware:CPU_VENDOR", "GenuineIntel", "GenuineIntel, AuthenticAMD") {
+		t.Error("cpu vendor should match")
+	}
+	if CmpPrereqVal("hardware:VIRT", "xen", "none, kvm") {
+		t.Error("virtualization type should not match")
+	}
+	if !CmpPrereqVal("hardware:CPU_MODEL", "Intel(R) Xeon(R) Platinum 8280 CPU @ 2.70GHz", "Xeon.*(Platinum|Gold)") {
+		t.Error("cpu model should match")
+	}
+	if !CmpPrereqVal("os:KERNEL_MIN", "4.12.14-122.37", "4.12.14-95.48-default") {
+		t.Error("kernel version should match minimum")
+	}
+	if CmpPrereqVal("os:KERNEL_MAX", "4.12.14-122.37", "4.12.14-95.48") {
+		t.Error("kernel version should not match maximum")
+	}
+	if CmpPrereqVal("os:OS_NAME", "", "SLES") {
+		t.Error("empty value should not match")
+	}
+}
+
+func TestSplitFsKey(t *testing.T) {
+	pattern, check := splitFsKey("fs:/hana/data/*:options")
+	if pattern != "/hana/data/*" || check != "options" {
+		t.Errorf("got pattern '%s', check '%s'", pattern, check)
+	}
+}
+
+func TestOptFsVal(t *testing.T) {
+	val := OptFsVal("fs:/hana/data/*:type", " xfs ")
+	if val != "xfs" {
+		t.Error(val)
+	}
+	val = OptFsVal("fs:/hana/log/*:options", "inode64, logbsize=256k")
+	if val != "inode64,logbsize=256k" {
+		t.Error(val)
+	}
+	val = OptFsVal("fs:/hana/data/*:remount", "noatime,inode64")
+	if val != "noatime,inode64" {
+		t.Error(val)
+	}
+	val = OptFsVal("fs:/hana/data/*:unknown", "xfs")
+	if val != "" {
+		t.Error(val)
+	}
+}
+
+func TestCmpFsVal(t *testing.T) {
+	if !CmpFsVal("fs:/hana/data/*:type", "xfs", "xfs, ext4") {
+		t.Error("file system type should match")
+	}
+	if CmpFsVal("fs:/hana/data/*:type", "ext3, xfs", "xfs") {
+		t.Error("file system type should not match")
+	}
+	if !CmpFsVal("fs:/hana/log/*:options", "inode64,logbsize=256k", "inode64,logbsize=256k") {
+		t.Error("mount options should match")
+	}
+	if CmpFsVal("fs:/hana/log/*:fstab", "none", "inode64") {
+		t.Error("mount options should not match")
+	}
+	if !CmpFsVal("fs:/hana/log/*:options", "NA", "inode64") {
+		t.Error("not available mount points should not influence the result")
+	}
+	if CmpFsVal("fs:/hana/log:options", "NA", "inode64") || CmpFsVal("fs:/hana/log:type", "NA", "xfs") {
+		t.Error("a named mount point, which is not mounted, should not match")
+	}
+	if !IsFsGlobKey("fs:/hana/data/mnt0000?:type") || IsFsGlobKey("fs:/hana/log:type") {
+		t.Error("wrong glob detection")
+	}
+}
+
+func TestSetFsVal(t *testing.T) {
+	// only checked, nothing to set
+	if err := SetFsVal("fs:/hana/data/*:type", "xfs", "xfs", false); err != nil {
+		t.Error(err)
+	}
+	// no matching mount point, nothing to remount
+	if err := SetFsVal("fs:/not/available/*:remount", "noatime", "noatime", false); err != nil {
+		t.Error(err)
+	}
+}
+
 func TestGetVMVal(t *testing.T) {
 	val := GetVMVal("THP")
 	if val != "always" && val != "madvise" && val != "never" {
@@ -236,6 +414,41 @@ func TestOptVMVal(t *testing.T) {
 	}
 }
 
+func TestOptHugepageVal(t *testing.T) {
+	val := OptHugepageVal("HUGEPAGES_PERCENT", "50", "")
+	if val != "50" {
+		t.Error(val)
+	}
+	val = OptHugepageVal("HUGEPAGES_PERCENT", "150", "")
+	if val != "" {
+		t.Error(val)
+	}
+	val = OptHugepageVal("HUGEPAGES_SIZE_MB", "4096", "50")
+	if val != "4096" {
+		t.Error(val)
+	}
+	val = OptHugepageVal("HUGEPAGES_SIZE_MB", "", "")
+	if val != "" {
+		t.Error(val)
+	}
+	hpSize := system.GetHugepageSizeKB()
+	if hpSize != 0 {
+		expSize := system.GetMainMemSizeMB() * 10 / 100 * 1024 / hpSize * hpSize / 1024
+		val = OptHugepageVal("HUGEPAGES_SIZE_MB", "0", "10")
+		if val != strconv.FormatUint(expSize, 10) {
+			t.Errorf("expected '%d', got '%s'", expSize, val)
+		}
+	}
+	val = OptHugepageVal("HUGEPAGES_GROUP", "root", "")
+	if val != "root" {
+		t.Error(val)
+	}
+	val = OptHugepageVal("HUGEPAGES_GROUP", "no_such_group_xyz", "")
+	if val != "" {
+		t.Error(val)
+	}
+}
+
 func TestSetVMVal(t *testing.T) {
 	newval := ""
 	oldval := GetVMVal("THP")
@@ -796,3 +1009,46 @@ func TestSetPagecacheVal(t *testing.T) {
 		t.Error(val)
 	}
 }
+
+func TestOptSystemdLimitsVal(t *testing.T) {
+	params := map[string]string{
+		"LIMIT_sybha0_hard_memlock":  "sybha0 hard memlock unlimited",
+		"LIMIT_@sapsys_soft_nofile":  "@sapsys soft nofile 65536",
+		"LIMIT_@sdba_soft_nofile":    "@sdba soft nofile 32800",
+		"LIMIT_<sidadm>_hard_nofile": "<sidadm> hard nofile 1048576",
+		"LIMIT_@dba_hard_nproc":      "@dba hard nproc NA",
+		"vm.nr_hugepages":            "128",
+	}
+	if val := OptSystemdLimitsVal(params); val != "LimitMEMLOCK=infinity LimitNOFILESoft=65536" {
+		t.Error(val)
+	}
+	if val := OptSystemdLimitsVal(map[string]string{}); val != "" {
+		t.Error(val)
+	}
+}
+
+func TestGetLimitsRuntimeInfo(t *testing.T) {
+	for _, val := range []string{"@sapsys soft nofile NA", "<sidadm> soft nofile 1048576", "saptune_no_user soft nofile 1048576", "nofile"} {
+		if info := GetLimitsRuntimeInfo(val); info != "" {
+			t.Errorf("'%s': expected empty info, got '%s'", val, info)
+		}
+	}
+}
+
+func TestCmpSystemdLimitsVal(t *testing.T) {
+	if !CmpSystemdLimitsVal("LimitMEMLOCK=infinity LimitNOFILE=4096", "LimitMEMLOCK=infinity") {
+		t.Error("limits should match")
+	}
+	if CmpSystemdLimitsVal("LimitMEMLOCK=65536 LimitNOFILE=4096", "LimitMEMLOCK=infinity") {
+		t.Error("limits should not match")
+	}
+	if CmpSystemdLimitsVal("", "LimitMEMLOCK=infinity") {
+		t.Error("missing limits should not match")
+	}
+	if !isSystemdLimits("systemd-limits:default") || isSystemdLimits("systemd:sysstat") {
+		t.Error("wrong systemd limits key detection")
+	}
+	if unit := systemdLimitsUnit("systemd-limits:SAPHA0_00.service"); unit != "SAPHA0_00.service" {
+		t.Error(unit)
+	}
+}
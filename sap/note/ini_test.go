@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"github.com/SUSE/saptune/system"
 	"github.com/SUSE/saptune/txtparser"
+	"io/ioutil"
 	"os"
 	"path"
+	"reflect"
 	"runtime"
 	"strconv"
 	"strings"
@@ -509,3 +511,89 @@ func TestPageCacheSettings(t *testing.T) {
 	}
 	cleanUp()
 }
+
+func TestIsSameLimit(t *testing.T) {
+	if !isSameLimit("LIMIT_<DBMSuser>_hard_memlock", "LIMIT_sybha0_hard_memlock") {
+		t.Error("limits should match")
+	}
+	if isSameLimit("LIMIT_<DBMSuser>_hard_memlock", "LIMIT_sybha0_soft_memlock") {
+		t.Error("limits should not match")
+	}
+	if isSameLimit("LIMIT_sybha0_hard_memlock", "LIMIT_sybha1_hard_memlock") {
+		t.Error("limits without placeholder should not match")
+	}
+	if isSameLimit("LIMIT_<DBMSuser>_hard_memlock", "LIMIT_<sybsid>_hard_memlock") {
+		t.Error("override limits with placeholder should not match")
+	}
+}
+
+func TestResolvedLimitsPlaceholder(t *testing.T) {
+	ovDir := path.Join(os.TempDir(), "saptune_override_placeholder")
+	defer os.RemoveAll(ovDir)
+	if err := os.MkdirAll(ovDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	// the override file uses a different domain than the discovered
+	// user, which resolved the placeholder of the Note
+	if err := ioutil.WriteFile(path.Join(ovDir, "1805750"), []byte("[limits]\nLIMITS=sybuser hard memlock unlimited, sybuser soft memlock unlimited\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ow, err := ParseOverrideFiles(ovDir, "1805750")
+	if err != nil {
+		t.Fatal(err)
+	}
+	param := txtparser.INIEntry{Section: INISectionLimits, Key: "LIMIT_sybha0_hard_memlock", Operator: "=", Value: "sybha0 hard memlock unlimited", Unresolved: "<DBMSuser> hard memlock unlimited"}
+	if phKey := placeholderLimitsKey(param); phKey != "LIMIT_<DBMSuser>_hard_memlock" {
+		t.Errorf("wrong placeholder key '%s'", phKey)
+	}
+	vend := INISettings{ID: "1805750", OverrideParams: make(map[string]string)}
+	key, val, _ := vend.handleInitOverride(param, ow, true)
+	if key != "LIMIT_sybuser_hard_memlock" || val != "sybuser hard memlock unlimited" {
+		t.Errorf("wrong limits entry '%s' - '%s'", key, val)
+	}
+	if vend.OverrideParams["LIMIT_sybuser_hard_memlock"] != "sybuser hard memlock unlimited" || vend.OverrideParams["LIMIT_sybha0_hard_memlock"] != "" {
+		t.Errorf("wrong override parameters '%v'", vend.OverrideParams)
+	}
+	// the later steps (verify, apply) use the same override entry
+	if key, val = vend.handleLimitsPlaceholder(param); key != "LIMIT_sybuser_hard_memlock" || val != "sybuser hard memlock unlimited" {
+		t.Errorf("wrong limits entry '%s' - '%s'", key, val)
+	}
+
+	// an override entry for the discovered user itself wins
+	vend.OverrideParams["LIMIT_sybha0_hard_memlock"] = "sybha0 hard memlock 4096"
+	if key, _ = vend.handleLimitsPlaceholder(param); key != "LIMIT_sybha0_hard_memlock" {
+		t.Errorf("wrong limits entry '%s'", key)
+	}
+	// limits entries without placeholder keep their domain
+	param = txtparser.INIEntry{Section: INISectionLimits, Key: "LIMIT_@sapsys_hard_memlock", Operator: "=", Value: "@sapsys hard memlock unlimited"}
+	if key, _ = vend.handleLimitsPlaceholder(param); key != "LIMIT_@sapsys_hard_memlock" {
+		t.Errorf("wrong limits entry '%s'", key)
+	}
+}
+
+func TestTunedParameters(t *testing.T) {
+	iniPath := path.Join(os.TempDir(), "saptune_tuned_params")
+	defer os.Remove(iniPath)
+	content := "[version]\n# SAP-NOTE=4711 VERSION=1\n\n[sysctl]\nvm.swappiness = 10\nvm.dirty_ratio = 10\nkernel.shmmni = 4096\n\n[rpm]\nglibc all 2.22\n\n[reminder]\n# remember me\n"
+	if err := ioutil.WriteFile(iniPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	vend := INISettings{
+		ConfFilePath:   iniPath,
+		ID:             "4711tuned",
+		SysctlParams:   map[string]string{"vm.swappiness": "60", "vm.dirty_ratio": "10", "kernel.shmmni": "", "glibc": "2.22"},
+		OverrideParams: map[string]string{"vm.swappiness": "untouched"},
+	}
+	tuned, err := vend.TunedParameters()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := map[string]string{"vm.dirty_ratio": "10"}
+	if !reflect.DeepEqual(tuned, exp) {
+		t.Errorf("got: %+v, expected: %+v\n", tuned, exp)
+	}
+	vend.ConfFilePath = path.Join(os.TempDir(), "saptune_tuned_params_missing")
+	if _, err := vend.TunedParameters(); err == nil {
+		t.Error("should return an error for a missing Note definition file")
+	}
+}
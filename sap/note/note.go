@@ -64,6 +64,10 @@ func GetTuningOptions(saptuneTuningDir, thirdPartyTuningDir string) TuningOption
 			system.WarningLog("For more information refer to the man page saptune-migrate(7)")
 			continue
 		}
+		if strings.HasSuffix(fileName, ".sol") {
+			// custom solution definition, handled by the solution package
+			continue
+		}
 		if !strings.HasSuffix(fileName, ".conf") {
 			// skip filenames without .conf suffix
 			system.WarningLog("skip file \"%s\", wrong filename syntax, missing '.conf' suffix", fileName)
@@ -283,6 +287,15 @@ func cmpMapValue(fieldName string, key reflect.Value, actVal, expVal interface{}
 	if strings.Split(key.String(), ":")[0] == "rpm" {
 		match = system.CmpRpmVers(actVal.(string), expVal.(string))
 	}
+	if strings.Split(key.String(), ":")[0] == "hardware" || strings.Split(key.String(), ":")[0] == "os" {
+		match = CmpPrereqVal(key.String(), actVal.(string), expVal.(string))
+	}
+	if strings.Split(key.String(), ":")[0] == "fs" {
+		match = CmpFsVal(key.String(), actVal.(string), expVal.(string))
+	}
+	if strings.Split(key.String(), ":")[0] == "systemd-limits" {
+		match = CmpSystemdLimitsVal(actVal.(string), expVal.(string))
+	}
 	if strings.Split(key.String(), ":")[0] == "systemd" {
 		match = system.CmpServiceStates(actVal.(string), expVal.(string))
 	}
@@ -0,0 +1,76 @@
+package note
+
+import (
+	"fmt"
+	"github.com/SUSE/saptune/system"
+	"github.com/SUSE/saptune/txtparser"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// OverrideDropInDir returns the directory of the override drop-in files
+// of a Note (<override directory>/<NoteID>.d)
+func OverrideDropInDir(overrideDir, noteID string) string {
+	return path.Join(overrideDir, noteID+".d")
+}
+
+// GetOverrideFiles returns the override file and the override drop-in files
+// (<NoteID>.d/*.conf) of a Note in the order they are merged.
+// The override file comes first, followed by the drop-in files in
+// lexical order.
+func GetOverrideFiles(overrideDir, noteID string) []string {
+	files := []string{}
+	ovFile := path.Join(overrideDir, noteID)
+	if fi, err := os.Stat(ovFile); err == nil && !fi.IsDir() {
+		files = append(files, ovFile)
+	}
+	dropInDir := OverrideDropInDir(overrideDir, noteID)
+	_, dropIns := system.ListDir(dropInDir, "")
+	sort.Strings(dropIns)
+	for _, dropIn := range dropIns {
+		if strings.HasSuffix(dropIn, ".conf") {
+			files = append(files, path.Join(dropInDir, dropIn))
+		}
+	}
+	return files
+}
+
+// ParseOverrideFiles parses the override file and the override drop-in
+// files of a Note and merges them into one INIFile. Later files overwrite
+// the values of the same parameter from earlier files.
+// An error is returned, if the Note has no override files.
+func ParseOverrideFiles(overrideDir, noteID string) (*txtparser.INIFile, error) {
+	files := GetOverrideFiles(overrideDir, noteID)
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no override files found for Note '%s'", noteID)
+	}
+	ow := &txtparser.INIFile{
+		AllValues: make([]txtparser.INIEntry, 0, 64),
+		KeyValue:  make(map[string]map[string]txtparser.INIEntry),
+	}
+	for _, file := range files {
+		ini, err := txtparser.ParseINIFile(file, false)
+		if err != nil {
+			return nil, err
+		}
+		ow.Merge(ini)
+	}
+	return ow, nil
+}
+
+// overrideFileHasSection returns true, if the override file of a Note
+// (<override directory>/<NoteID>, not the drop-in files) contains
+// parameters of the given section
+func overrideFileHasSection(overrideDir, noteID, section string) bool {
+	ovFile := path.Join(overrideDir, noteID)
+	if fi, err := os.Stat(ovFile); err != nil || fi.IsDir() {
+		return false
+	}
+	ini, err := txtparser.ParseINIFile(ovFile, false)
+	if err != nil {
+		return false
+	}
+	return len(ini.KeyValue[section]) != 0
+}
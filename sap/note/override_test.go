@@ -0,0 +1,155 @@
+package note
+
+import (
+	"github.com/SUSE/saptune/txtparser"
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+)
+
+func TestParseOverrideFiles(t *testing.T) {
+	ovDir := path.Join(os.TempDir(), "saptune_override")
+	defer os.RemoveAll(ovDir)
+	dropInDir := OverrideDropInDir(ovDir, "4711")
+	if err := os.MkdirAll(dropInDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseOverrideFiles(ovDir, "4711"); err == nil {
+		t.Error("should return an error without override files")
+	}
+	files := map[string]string{
+		path.Join(ovDir, "4711"):              "[sysctl]\nvm.swappiness = 10\nvm.dirty_ratio = 10\n",
+		path.Join(dropInDir, "20-dirty.conf"): "[sysctl]\nvm.dirty_ratio = 30\n",
+		path.Join(dropInDir, "10-dirty.conf"): "[sysctl]\nvm.dirty_ratio = 20\n",
+		path.Join(dropInDir, "README"):        "[sysctl]\nvm.dirty_ratio = 40\n",
+	}
+	for file, content := range files {
+		if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	exp := []string{path.Join(ovDir, "4711"), path.Join(dropInDir, "10-dirty.conf"), path.Join(dropInDir, "20-dirty.conf")}
+	if ovFiles := GetOverrideFiles(ovDir, "4711"); !reflect.DeepEqual(ovFiles, exp) {
+		t.Errorf("wrong override files '%v'", ovFiles)
+	}
+	ow, err := ParseOverrideFiles(ovDir, "4711")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := ow.KeyValue["sysctl"]["vm.dirty_ratio"]
+	if entry.Value != "30" || entry.File != path.Join(dropInDir, "20-dirty.conf") {
+		t.Errorf("wrong entry '%+v'", entry)
+	}
+	entry = ow.KeyValue["sysctl"]["vm.swappiness"]
+	if entry.Value != "10" || entry.File != path.Join(ovDir, "4711") {
+		t.Errorf("wrong entry '%+v'", entry)
+	}
+}
+
+func TestOverrideDropInLimits(t *testing.T) {
+	ovDir := path.Join(os.TempDir(), "saptune_override_limits")
+	defer os.RemoveAll(ovDir)
+	dropInDir := OverrideDropInDir(ovDir, "4711")
+	if err := os.MkdirAll(dropInDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	// the drop-in file changes only one of the limits of the Note and
+	// leaves a second one untouched
+	if err := ioutil.WriteFile(path.Join(dropInDir, "10-nofile.conf"), []byte("[limits]\nLIMITS=@sapsys soft nofile 32800, @sdba soft nofile\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ow, err := ParseOverrideFiles(ovDir, "4711")
+	if err != nil {
+		t.Fatal(err)
+	}
+	vend := INISettings{ID: "4711", OverrideParams: make(map[string]string)}
+	noteLimits := map[string]string{
+		"LIMIT_@sapsys_soft_nofile": "@sapsys soft nofile 1048576",
+		"LIMIT_@sapsys_hard_nofile": "@sapsys hard nofile 1048576",
+		"LIMIT_@sdba_soft_nofile":   "@sdba soft nofile 1048576",
+	}
+	for key, val := range noteLimits {
+		vend.handleInitOverride(txtparser.INIEntry{Section: INISectionLimits, Key: key, Value: val, Operator: "="}, ow, overrideFileHasSection(ovDir, "4711", INISectionLimits))
+	}
+	if !reflect.DeepEqual(vend.OverrideParams, map[string]string{"LIMIT_@sapsys_soft_nofile": "@sapsys soft nofile 32800", "LIMIT_@sdba_soft_nofile": "untouched"}) {
+		t.Errorf("wrong override parameters '%v'", vend.OverrideParams)
+	}
+
+	// an empty LIMITS parameter disables all limits of the Note
+	if err := ioutil.WriteFile(path.Join(dropInDir, "00-off.conf"), []byte("[limits]\nLIMITS=\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if ow, err = ParseOverrideFiles(ovDir, "4711"); err != nil {
+		t.Fatal(err)
+	}
+	vend.OverrideParams = make(map[string]string)
+	for key, val := range noteLimits {
+		vend.handleInitOverride(txtparser.INIEntry{Section: INISectionLimits, Key: key, Value: val, Operator: "="}, ow, overrideFileHasSection(ovDir, "4711", INISectionLimits))
+	}
+	if vend.OverrideParams["LIMIT_@sapsys_hard_nofile"] != "untouched" || vend.OverrideParams["LIMIT_@sdba_soft_nofile"] != "untouched" || vend.OverrideParams["LIMIT_@sapsys_soft_nofile"] != "@sapsys soft nofile 32800" {
+		t.Errorf("wrong override parameters '%v'", vend.OverrideParams)
+	}
+}
+
+func TestOverrideFileLimits(t *testing.T) {
+	ovDir := path.Join(os.TempDir(), "saptune_override_legacy_limits")
+	defer os.RemoveAll(ovDir)
+	if err := os.MkdirAll(ovDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	// the override file lists only one of the limits of the Note, so
+	// the missing limits are left untouched
+	if err := ioutil.WriteFile(path.Join(ovDir, "4711"), []byte("[limits]\nLIMITS=@sapsys soft nofile 32800\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ow, err := ParseOverrideFiles(ovDir, "4711")
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacyLimits := overrideFileHasSection(ovDir, "4711", INISectionLimits)
+	if !legacyLimits {
+		t.Error("limits section of the override file not found")
+	}
+	vend := INISettings{ID: "4711", OverrideParams: make(map[string]string)}
+	noteLimits := map[string]string{
+		"LIMIT_@sapsys_soft_nofile": "@sapsys soft nofile 1048576",
+		"LIMIT_@sapsys_hard_nofile": "@sapsys hard nofile 1048576",
+		"LIMIT_@sdba_soft_nofile":   "@sdba soft nofile 1048576",
+	}
+	for key, val := range noteLimits {
+		vend.handleInitOverride(txtparser.INIEntry{Section: INISectionLimits, Key: key, Value: val, Operator: "="}, ow, legacyLimits)
+	}
+	if !reflect.DeepEqual(vend.OverrideParams, map[string]string{"LIMIT_@sapsys_soft_nofile": "@sapsys soft nofile 32800", "LIMIT_@sapsys_hard_nofile": "untouched", "LIMIT_@sdba_soft_nofile": "untouched"}) {
+		t.Errorf("wrong override parameters '%v'", vend.OverrideParams)
+	}
+
+	// a drop-in file changing a limit does not enable the limits
+	// missing in the override file
+	dropInDir := OverrideDropInDir(ovDir, "4711")
+	if err := os.MkdirAll(dropInDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path.Join(dropInDir, "10-nofile.conf"), []byte("[limits]\nLIMITS=@sapsys soft nofile 65536\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if ow, err = ParseOverrideFiles(ovDir, "4711"); err != nil {
+		t.Fatal(err)
+	}
+	vend.OverrideParams = make(map[string]string)
+	for key, val := range noteLimits {
+		vend.handleInitOverride(txtparser.INIEntry{Section: INISectionLimits, Key: key, Value: val, Operator: "="}, ow, overrideFileHasSection(ovDir, "4711", INISectionLimits))
+	}
+	if !reflect.DeepEqual(vend.OverrideParams, map[string]string{"LIMIT_@sapsys_soft_nofile": "@sapsys soft nofile 65536", "LIMIT_@sapsys_hard_nofile": "untouched", "LIMIT_@sdba_soft_nofile": "untouched"}) {
+		t.Errorf("wrong override parameters '%v'", vend.OverrideParams)
+	}
+
+	// an override file without limits section
+	if err := ioutil.WriteFile(path.Join(ovDir, "4711"), []byte("[sysctl]\nvm.swappiness=10\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if overrideFileHasSection(ovDir, "4711", INISectionLimits) {
+		t.Error("unexpected limits section in the override file")
+	}
+}
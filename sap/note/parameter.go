@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+	"reflect"
 )
 
 // ParameterNoteEntry stores the parameter values set by a Note
@@ -236,3 +237,87 @@ func IsLastNoteOfParameter(param string) bool {
 	}
 	return false
 }
+
+// ReorderParameterNotes sorts the note entries of a parameter state chain
+// according to the given note order. The 'start' entry and entries of
+// notes, which are not part of the given order, are kept in front of the
+// chain in their previous order.
+func ReorderParameterNotes(pEntries ParameterNotes, noteOrder []string) ParameterNotes {
+	reordered := ParameterNotes{
+		AllNotes: make([]ParameterNoteEntry, 0, len(pEntries.AllNotes)),
+	}
+	for _, entry := range pEntries.AllNotes {
+		if !system.IsStringInList(entry.NoteID, noteOrder) {
+			reordered.AllNotes = append(reordered.AllNotes, entry)
+		}
+	}
+	for _, noteID := range noteOrder {
+		for _, entry := range pEntries.AllNotes {
+			if entry.NoteID == noteID {
+				reordered.AllNotes = append(reordered.AllNotes, entry)
+			}
+		}
+	}
+	return reordered
+}
+
+// ReorderParameterStates re-arranges the chains of all parameter state
+// files according to the given note order.
+// Returns the parameters, whose value set on the system changes because of
+// the new order, together with the note, which now sets the value
+func ReorderParameterStates(noteOrder []string) map[string]string {
+	changed := make(map[string]string)
+	for param, pEntries := range GetAllSavedParameters() {
+		reordered := ReorderParameterNotes(pEntries, noteOrder)
+		if reflect.DeepEqual(pEntries, reordered) {
+			continue
+		}
+		if err := StoreParameter(param, reordered, true); err != nil {
+			system.WarningLog("Failed to store the new order of the parameter file '%s' for parameter '%s'", GetPathToParameter(param), param)
+			continue
+		}
+		oldLast := pEntries.AllNotes[len(pEntries.AllNotes)-1]
+		newLast := reordered.AllNotes[len(reordered.AllNotes)-1]
+		if oldLast.Value != newLast.Value {
+			changed[param] = newLast.NoteID
+		}
+	}
+	return changed
+}
+
+// GetNoteParameterValues returns the parameters, which have an entry of the
+// given note in their parameter state file, together with the value set by
+// the note
+func GetNoteParameterValues(noteID string) map[string]string {
+	vals := make(map[string]string)
+	for param, pEntries := range GetAllSavedParameters() {
+		for _, entry := range pEntries.AllNotes {
+			if entry.NoteID == noteID {
+				vals[param] = entry.Value
+			}
+		}
+	}
+	return vals
+}
+
+// UpdateParameterNoteValue sets the value of the note in the parameter state
+// file of the parameter. If the note has no entry yet, the entry is added
+// and the chain is sorted according to the given note order.
+// Returns true, if the note is the last one in the chain, which means that
+// the value of the note is the one to set on the system
+func UpdateParameterNoteValue(param, noteID, value string, noteOrder []string) bool {
+	pEntries := GetSavedParameterNotes(param)
+	if len(pEntries.AllNotes) == 0 {
+		return false
+	}
+	if IDInParameterList(noteID, pEntries.AllNotes) {
+		pEntries.AllNotes[PositionInParameterList(noteID, pEntries.AllNotes)].Value = value
+	} else {
+		pEntries.AllNotes = append(pEntries.AllNotes, ParameterNoteEntry{NoteID: noteID, Value: value})
+	}
+	pEntries = ReorderParameterNotes(pEntries, noteOrder)
+	if err := StoreParameter(param, pEntries, true); err != nil {
+		system.WarningLog("Failed to store note '%s' values for parameter file '%s' for parameter '%s'", noteID, GetPathToParameter(param), param)
+	}
+	return pEntries.AllNotes[len(pEntries.AllNotes)-1].NoteID == noteID
+}
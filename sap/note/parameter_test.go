@@ -1,6 +1,7 @@
 package note
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -247,3 +248,70 @@ func TestRevertParameter(t *testing.T) {
 	}
 	CleanUpParamFile("TEST_PARAMETER_1")
 }
+
+func TestReorderParameterNotes(t *testing.T) {
+	pNotes := ParameterNotes{
+		AllNotes: []ParameterNoteEntry{paramNote1, paramNote2, {NoteID: "other", Value: "OtherValue"}, paramNote3},
+	}
+	reordered := ReorderParameterNotes(pNotes, []string{"entry3", "entry4", "entry2"})
+	exp := []ParameterNoteEntry{paramNote1, {NoteID: "other", Value: "OtherValue"}, paramNote3, paramNote2}
+	if !reflect.DeepEqual(reordered.AllNotes, exp) {
+		t.Errorf("got: %+v, expected: %+v\n", reordered.AllNotes, exp)
+	}
+}
+
+func TestReorderParameterStates(t *testing.T) {
+	defer CleanUpParamFile("TEST_REORDER_PARAMETER")
+	defer CleanUpParamFile("TEST_REORDER_SAME_VALUE")
+	pNotes := ParameterNotes{AllNotes: []ParameterNoteEntry{paramNote1, paramNote2, paramNote3}}
+	if err := StoreParameter("TEST_REORDER_PARAMETER", pNotes, true); err != nil {
+		t.Fatal(err)
+	}
+	sameVal := ParameterNotes{AllNotes: []ParameterNoteEntry{paramNote1, {NoteID: "entry2", Value: "SameValue"}, {NoteID: "entry3", Value: "SameValue"}}}
+	if err := StoreParameter("TEST_REORDER_SAME_VALUE", sameVal, true); err != nil {
+		t.Fatal(err)
+	}
+	changed := ReorderParameterStates([]string{"entry3", "entry2"})
+	if len(changed) != 1 || changed["TEST_REORDER_PARAMETER"] != "entry2" {
+		t.Errorf("wrong changed parameters: %+v\n", changed)
+	}
+	val := GetSavedParameterNotes("TEST_REORDER_PARAMETER")
+	if !reflect.DeepEqual(val.AllNotes, []ParameterNoteEntry{paramNote1, paramNote3, paramNote2}) {
+		t.Errorf("wrong order stored: %+v\n", val.AllNotes)
+	}
+	val = GetSavedParameterNotes("TEST_REORDER_SAME_VALUE")
+	if val.AllNotes[len(val.AllNotes)-1].NoteID != "entry2" {
+		t.Errorf("wrong order stored: %+v\n", val.AllNotes)
+	}
+}
+
+func TestUpdateParameterNoteValue(t *testing.T) {
+	defer CleanUpParamFile("TEST_UPDATE_PARAMETER")
+	if UpdateParameterNoteValue("TEST_UPDATE_PARAMETER", "entry2", "NewValue", []string{"entry2"}) {
+		t.Error("parameter without state file should not be updated")
+	}
+	pNotes := ParameterNotes{AllNotes: []ParameterNoteEntry{paramNote1, paramNote2, paramNote3}}
+	if err := StoreParameter("TEST_UPDATE_PARAMETER", pNotes, true); err != nil {
+		t.Fatal(err)
+	}
+	noteOrder := []string{"entry2", "entry4", "entry3"}
+	// value of a note, which is not the last one
+	if UpdateParameterNoteValue("TEST_UPDATE_PARAMETER", "entry2", "NewValue", noteOrder) {
+		t.Error("'entry2' should not be the last note of the parameter")
+	}
+	// new note in the middle of the order
+	if UpdateParameterNoteValue("TEST_UPDATE_PARAMETER", "entry4", "Value4", noteOrder) {
+		t.Error("'entry4' should not be the last note of the parameter")
+	}
+	if !UpdateParameterNoteValue("TEST_UPDATE_PARAMETER", "entry3", "NewLastValue", noteOrder) {
+		t.Error("'entry3' should be the last note of the parameter")
+	}
+	exp := []ParameterNoteEntry{paramNote1, {NoteID: "entry2", Value: "NewValue"}, {NoteID: "entry4", Value: "Value4"}, {NoteID: "entry3", Value: "NewLastValue"}}
+	if val := GetSavedParameterNotes("TEST_UPDATE_PARAMETER"); !reflect.DeepEqual(val.AllNotes, exp) {
+		t.Errorf("got: %+v, expected: %+v\n", val.AllNotes, exp)
+	}
+	vals := GetNoteParameterValues("entry4")
+	if len(vals) != 1 || vals["TEST_UPDATE_PARAMETER"] != "Value4" {
+		t.Errorf("wrong parameter values of 'entry4': %+v\n", vals)
+	}
+}
@@ -6,9 +6,11 @@ Solutions are collections of relevant SAP notes, all of which are applicable to
 
 import (
 	"fmt"
+	"github.com/SUSE/saptune/sap/note"
 	"github.com/SUSE/saptune/system"
 	"github.com/SUSE/saptune/txtparser"
 	"os"
+	"path"
 	"sort"
 	"strings"
 )
@@ -19,6 +21,8 @@ const (
 	OverrideSolutionSheet = "/etc/saptune/override/solutions"
 	DeprecSolutionSheet   = "/usr/share/saptune/solsdeprecated"
 	NoteTuningSheets      = "/var/lib/saptune/working/notes/"
+	CustomSolutionSheets  = "/etc/saptune/extra/"
+	CustomSolutionSuffix  = ".sol"
 	ArchX86               = "amd64"      // ArchX86 is the GOARCH value for x86 platform.
 	ArchPPC64LE           = "ppc64le"    // ArchPPC64LE is the GOARCH for 64-bit PowerPC little endian platform.
 	ArchX86PC             = "amd64_PC"   // ArchX86 is the GOARCH value for x86 platform. PC indicates PageCache is available
@@ -28,6 +32,14 @@ const (
 // Solution is identified by set of note numbers.
 type Solution []string
 
+// SolutionInfo contains the metadata of a solution
+type SolutionInfo struct {
+	Description   string   // from section [description]
+	Version       string   // from section [version]
+	OptionalNotes []string // notes marked with '?', not applied with the solution
+	Includes      []string // solutions included by '@SolutionName'
+}
+
 // Architecture VS solution ID VS note numbers
 // AllSolutions = map[string]map[string]Solution
 
@@ -35,10 +47,20 @@ type Solution []string
 // SAP Notes for all supported architectures
 var AllSolutions = GetSolutionDefintion(SolutionSheet)
 
+// SolutionInfos contains the metadata of all available solutions for all
+// supported architectures
+var SolutionInfos = GetSolutionInfo(SolutionSheet)
+
 // OverrideSolutions contains a list of all available override solutions with
 // their related SAP Notes for all supported architectures
 var OverrideSolutions = GetOverrideSolution(OverrideSolutionSheet, NoteTuningSheets)
 
+// CustomSolutions contains a list of all custom solutions defined in the
+// extra directory with their related SAP Notes for all supported architectures
+// It is filled by GetCustomSolutions, as the custom solutions need to be
+// validated against the available tuning options
+var CustomSolutions = make(map[string]map[string]Solution)
+
 // DeprecSolutions contains a list of all solutions witch are deprecated
 var DeprecSolutions = GetDeprecatedSolution(DeprecSolutionSheet)
 
@@ -46,61 +68,134 @@ var DeprecSolutions = GetDeprecatedSolution(DeprecSolutionSheet)
 // build same structure for AllSolutions as before
 // can be simplyfied later
 func GetSolutionDefintion(fileName string) map[string]map[string]Solution {
+	sols, _ := readSolutionDefinition(fileName, nil, nil)
+	return sols
+}
+
+// GetSolutionInfo reads the metadata of the solutions (description, version,
+// optional notes and included solutions) from the solution definition file
+func GetSolutionInfo(fileName string) map[string]map[string]SolutionInfo {
+	_, infos := readSolutionDefinition(fileName, nil, nil)
+	return infos
+}
+
+// readSolutionDefinition reads the solutions and their metadata from the
+// solution definition file for all supported architectures.
+// Solutions included by '@SolutionName', which are not defined in the file,
+// are looked up in baseSols and baseInfos
+func readSolutionDefinition(fileName string, baseSols map[string]map[string]Solution, baseInfos map[string]map[string]SolutionInfo) (map[string]map[string]Solution, map[string]map[string]SolutionInfo) {
 	sols := make(map[string]map[string]Solution)
-	sol := make(map[string]Solution)
-	currentArch := ""
-	arch := ""
-	pcarch := ""
+	infos := make(map[string]map[string]SolutionInfo)
 	content, err := txtparser.ParseINIFile(fileName, false)
 	if err != nil {
 		_ = system.ErrorLog("Failed to read solution definition from file '%s'", fileName)
-		return sols
+		return sols, infos
 	}
-
-	for _, param := range content.AllValues {
-		if param.Section == "reminder" || param.Section == "version" {
+	for _, archSection := range []string{"ArchX86", "ArchPPC64LE"} {
+		entries, ok := content.KeyValue[archSection]
+		if !ok {
 			continue
 		}
-		if param.Section != currentArch {
-			// start a new arch
-			if currentArch != "" {
-				// save previous arch settings
-				if system.IsPagecacheAvailable() {
-					sols[pcarch] = sol
-				}
-				sols[arch] = sol
+		arch := ArchX86
+		pcarch := ArchX86PC
+		if archSection == "ArchPPC64LE" {
+			arch = ArchPPC64LE
+			pcarch = ArchPPC64LEPC
+		}
+		rawSols := make(map[string][]string)
+		for solName, entry := range entries {
+			// looking for override solution
+			if len(OverrideSolutions[arch]) != 0 && len(OverrideSolutions[arch][solName]) != 0 {
+				rawSols[solName] = OverrideSolutions[arch][solName]
+			} else {
+				rawSols[solName] = strings.Fields(entry.Value)
 			}
-			currentArch = param.Section
-			sol = make(map[string]Solution)
-			switch currentArch {
-			case "ArchPPC64LE":
-				arch = "ppc64le"
-				pcarch = "ppc64le_PC"
-			case "ArchX86":
-				arch = "amd64"
-				pcarch = "amd64_PC"
+		}
+		sol := make(map[string]Solution)
+		info := make(map[string]SolutionInfo)
+		for solName, tokens := range rawSols {
+			notes, optNotes, err := expandSolution(solName, rawSols, baseSols[arch], baseInfos[arch], []string{})
+			if err != nil {
+				_ = system.ErrorLog("skipping solution '%s' in file '%s' - %v", solName, fileName, err)
+				continue
 			}
+			sol[solName] = notes
+			solInfo := SolutionInfo{
+				Description:   strings.Replace(content.KeyValue["description"][solName].Value, "\t", " ", -1),
+				Version:       content.KeyValue["version"][solName].Value,
+				OptionalNotes: optNotes,
+				Includes:      []string{},
+			}
+			for _, token := range tokens {
+				if strings.HasPrefix(token, "@") {
+					solInfo.Includes = append(solInfo.Includes, strings.TrimPrefix(token, "@"))
+				}
+			}
+			info[solName] = solInfo
 		}
+		if system.IsPagecacheAvailable() {
+			sols[pcarch] = sol
+			infos[pcarch] = info
+		}
+		sols[arch] = sol
+		infos[arch] = info
+	}
+	return sols, infos
+}
 
-		// looking for override solution
-		if len(OverrideSolutions[arch]) != 0 && len(OverrideSolutions[arch][param.Key]) != 0 {
-			param.Value = strings.Join(OverrideSolutions[arch][param.Key], " ")
+// expandSolution returns the mandatory and the optional notes of a solution.
+// Included solutions ('@SolutionName') are expanded recursively, optional
+// notes are marked with a leading '?'. A note, which is mandatory in one of
+// the included solutions, is mandatory for the whole solution.
+// The order of the notes is preserved, duplicates are removed
+func expandSolution(solName string, rawSols map[string][]string, baseSols map[string]Solution, baseInfos map[string]SolutionInfo, including []string) (notes, optNotes []string, err error) {
+	notes = []string{}
+	optNotes = []string{}
+	if system.IsStringInList(solName, including) {
+		return notes, optNotes, fmt.Errorf("cyclic inclusion of solution '%s' (%s -> %s)", solName, strings.Join(including, " -> "), solName)
+	}
+	if baseNotes, ok := baseSols[solName]; ok && len(including) != 0 {
+		// included solution defined outside of the file. Such a
+		// solution can not be redefined by the file
+		return append(notes, baseNotes...), append(optNotes, baseInfos[solName].OptionalNotes...), nil
+	}
+	tokens, ok := rawSols[solName]
+	if !ok {
+		return notes, optNotes, fmt.Errorf("included solution '%s' not defined", solName)
+	}
+	for _, token := range tokens {
+		switch {
+		case strings.HasPrefix(token, "@"):
+			incNotes, incOptNotes, err := expandSolution(strings.TrimPrefix(token, "@"), rawSols, baseSols, baseInfos, append(including, solName))
+			if err != nil {
+				return notes, optNotes, err
+			}
+			notes = appendNotes(notes, incNotes...)
+			optNotes = appendNotes(optNotes, incOptNotes...)
+		case strings.HasPrefix(token, "?"):
+			optNotes = appendNotes(optNotes, strings.TrimPrefix(token, "?"))
+		default:
+			notes = appendNotes(notes, token)
 		}
-		sol[param.Key] = strings.Split(param.Value, "\t")
 	}
-	switch currentArch {
-	case "ArchPPC64LE":
-		if system.IsPagecacheAvailable() {
-			sols[ArchPPC64LEPC] = sol
+	optOnly := []string{}
+	for _, noteID := range optNotes {
+		if !system.IsStringInList(noteID, notes) {
+			optOnly = append(optOnly, noteID)
 		}
-		sols[ArchPPC64LE] = sol
-	case "ArchX86":
-		if system.IsPagecacheAvailable() {
-			sols[ArchX86PC] = sol
+	}
+	return notes, optOnly, nil
+}
+
+// appendNotes appends the notes to the list, if they are not already part
+// of the list
+func appendNotes(list []string, noteIDs ...string) []string {
+	for _, noteID := range noteIDs {
+		if noteID != "" && !system.IsStringInList(noteID, list) {
+			list = append(list, noteID)
 		}
-		sols[ArchX86] = sol
 	}
-	return sols
+	return list
 }
 
 // GetOverrideSolution reads solution override definition from file
@@ -125,6 +220,11 @@ func GetOverrideSolution(fileName, noteFiles string) map[string]map[string]Solut
 		// the solution, but the package store (and/or staging area) does.
 		notesOK := true
 		for _, noteID := range strings.Split(content.KeyValue[param.Section][param.Key].Value, "\t") {
+			if strings.HasPrefix(noteID, "@") {
+				// included solution, no note
+				continue
+			}
+			noteID = strings.TrimPrefix(noteID, "?")
 			if _, err := os.Stat(fmt.Sprintf("%s%s", noteFiles, noteID)); err != nil {
 				system.WarningLog("Definition for note '%s' used for solution '%s' in override file '%s' not found in %s", noteID, param.Key, fileName, noteFiles)
 				notesOK = false
@@ -231,6 +331,184 @@ func GetDeprecatedSolution(fileName string) map[string]map[string]string {
 	return sols
 }
 
+// GetDeprecatedSuccessor returns the successor of a deprecated solution.
+// The successor is the second field of the entry in the deprecated solution
+// definition (e.g. 'MAXDB = deprecated NETWEAVER')
+func GetDeprecatedSuccessor(archName, solName string) string {
+	fields := strings.Fields(DeprecSolutions[archName][solName])
+	if len(fields) < 2 {
+		return ""
+	}
+	return fields[1]
+}
+
+// GetCustomSolutions reads the custom solution definitions and their
+// metadata from all files with suffix '.sol' in the given directory. The
+// syntax of the files is the same as for the shipped solution definitions,
+// custom solutions can include shipped solutions by '@SolutionName'.
+// Custom solutions using the name of a shipped solution or referencing
+// notes, which are not available in the tuning options, are skipped
+func GetCustomSolutions(extraDir string, tuningOptions note.TuningOptions) (map[string]map[string]Solution, map[string]map[string]SolutionInfo) {
+	sols := make(map[string]map[string]Solution)
+	infos := make(map[string]map[string]SolutionInfo)
+	// the PageCache architectures share the solutions with their base
+	// architecture, so log each warning only once
+	logged := make(map[string]bool)
+	warning := func(format string, v ...interface{}) {
+		msg := fmt.Sprintf(format, v...)
+		if !logged[msg] {
+			system.WarningLog(msg)
+			logged[msg] = true
+		}
+	}
+	_, files := system.ListDir(extraDir, "")
+	for _, fileName := range files {
+		if !strings.HasSuffix(fileName, CustomSolutionSuffix) {
+			continue
+		}
+		solFile := path.Join(extraDir, fileName)
+		fileSols, fileInfos := readSolutionDefinition(solFile, AllSolutions, SolutionInfos)
+		for arch, archSols := range fileSols {
+			for solName, solNotes := range archSols {
+				if _, exists := AllSolutions[arch][solName]; exists {
+					warning("custom solution '%s' in file '%s' will not override the shipped solution", solName, solFile)
+					continue
+				}
+				if _, exists := sols[arch][solName]; exists {
+					warning("custom solution '%s' in file '%s' is already defined in another file, skipping", solName, solFile)
+					continue
+				}
+				notesOK := true
+				for _, noteID := range append(append([]string{}, solNotes...), fileInfos[arch][solName].OptionalNotes...) {
+					if _, exists := tuningOptions[noteID]; !exists {
+						warning("Definition for note '%s' used for custom solution '%s' in file '%s' not found", noteID, solName, solFile)
+						notesOK = false
+					}
+				}
+				if !notesOK {
+					continue
+				}
+				if sols[arch] == nil {
+					sols[arch] = make(map[string]Solution)
+					infos[arch] = make(map[string]SolutionInfo)
+				}
+				sols[arch][solName] = solNotes
+				infos[arch][solName] = fileInfos[arch][solName]
+			}
+		}
+	}
+	return sols, infos
+}
+
+// AddCustomSolutions adds the custom solutions and their metadata to the
+// available solutions of all architectures
+func AddCustomSolutions(customSols map[string]map[string]Solution, customInfos map[string]map[string]SolutionInfo) {
+	for arch, archSols := range customSols {
+		if AllSolutions[arch] == nil {
+			AllSolutions[arch] = make(map[string]Solution)
+		}
+		if SolutionInfos[arch] == nil {
+			SolutionInfos[arch] = make(map[string]SolutionInfo)
+		}
+		for solName, solNotes := range archSols {
+			AllSolutions[arch][solName] = solNotes
+			SolutionInfos[arch][solName] = customInfos[arch][solName]
+		}
+	}
+}
+
+// IsCustomSolution returns true, if the solution is a custom solution
+// defined in the extra directory
+func IsCustomSolution(archName, solName string) bool {
+	_, exists := CustomSolutions[archName][solName]
+	return exists
+}
+
+// suggestRules map the found SAP component types to a solution name in the
+// order they are checked. The first rule, whose component types are all
+// found on the system, wins
+var suggestRules = []struct {
+	compTypes []string
+	solName   string
+}{
+	{[]string{system.SAPCompHANA, system.SAPCompNetWeaver}, "NETWEAVER+HANA"},
+	{[]string{system.SAPCompHANA}, "HANA"},
+	{[]string{system.SAPCompASE}, "SAP-ASE"},
+	{[]string{system.SAPCompMaxDB}, "MAXDB"},
+	{[]string{system.SAPCompBOBJ}, "BOBJ"},
+	{[]string{system.SAPCompNetWeaver}, "NETWEAVER"},
+}
+
+// SuggestSolution returns the solution recommended for the SAP components
+// found on the system together with the explanation of the recommendation.
+// A deprecated solution is replaced by its successor.
+// If no solution matches, an empty solution name is returned
+func SuggestSolution(archName string, comps []system.SAPComponent) (string, []string) {
+	reasons := []string{}
+	found := []string{}
+	for _, comp := range comps {
+		if !system.IsStringInList(comp.Type, found) {
+			found = append(found, comp.Type)
+		}
+	}
+	for _, rule := range suggestRules {
+		matches := true
+		for _, compType := range rule.compTypes {
+			if !system.IsStringInList(compType, found) {
+				matches = false
+				break
+			}
+		}
+		if !matches {
+			continue
+		}
+		solName := rule.solName
+		reasons = append(reasons, fmt.Sprintf("solution '%s' matches the found SAP components '%s'", solName, strings.Join(rule.compTypes, "' and '")))
+		if successor := GetDeprecatedSuccessor(archName, solName); successor != "" {
+			reasons = append(reasons, fmt.Sprintf("solution '%s' is deprecated, using its successor '%s'", solName, successor))
+			solName = successor
+		}
+		if _, ok := AllSolutions[archName][solName]; !ok {
+			reasons = append(reasons, fmt.Sprintf("solution '%s' is not available on this system", solName))
+			return "", reasons
+		}
+		// check, if the notes of the other found SAP components are
+		// part of the solution
+		for _, compType := range found {
+			if !system.IsStringInList(compType, rule.compTypes) && !solutionCoversComponent(archName, solName, compType) {
+				reasons = append(reasons, fmt.Sprintf("the notes for the found SAP component '%s' are not part of the solution '%s', please check, if additional notes are needed", compType, solName))
+			}
+		}
+		return solName, reasons
+	}
+	return "", reasons
+}
+
+// solutionCoversComponent returns true, if all notes of the solution
+// suggested for the SAP component type alone are part of the solution
+func solutionCoversComponent(archName, solName, compType string) bool {
+	for _, rule := range suggestRules {
+		if len(rule.compTypes) != 1 || rule.compTypes[0] != compType {
+			continue
+		}
+		compSolName := rule.solName
+		if successor := GetDeprecatedSuccessor(archName, compSolName); successor != "" {
+			compSolName = successor
+		}
+		compNotes, ok := AllSolutions[archName][compSolName]
+		if !ok {
+			return false
+		}
+		for _, noteID := range compNotes {
+			if !system.IsStringInList(noteID, AllSolutions[archName][solName]) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
 // GetSortedSolutionNames returns all solution names, sorted alphabetically.
 func GetSortedSolutionNames(archName string) (ret []string) {
 	ret = make([]string, 0, len(AllSolutions))
@@ -1,6 +1,7 @@
 package solution
 
 import (
+	"github.com/SUSE/saptune/sap/note"
 	"github.com/SUSE/saptune/system"
 	"os"
 	"path"
@@ -34,6 +35,46 @@ func TestGetSolutionDefintion(t *testing.T) {
 	}
 }
 
+func TestGetSolutionInfo(t *testing.T) {
+	solutionFile := path.Join(TstFilesInGOPATH, "saptune-test-composed-sols")
+	solcount := 2
+	if system.IsPagecacheAvailable() {
+		solcount = 4
+	}
+
+	solutions := GetSolutionDefintion(solutionFile)
+	infos := GetSolutionInfo(solutionFile)
+	if len(solutions) != solcount || len(infos) != solcount {
+		t.Fatalf("'%+v' has len '%+v'\n", solutions, len(solutions))
+	}
+	if strings.Join(solutions[runtime.GOARCH]["BASE"], " ") != "941735 1771258" || strings.Join(infos[runtime.GOARCH]["BASE"].OptionalNotes, " ") != "2578899" {
+		t.Fatal(solutions, infos)
+	}
+	// included solutions are expanded in order without duplicates,
+	// a note mandatory in one part is not optional
+	if strings.Join(solutions[runtime.GOARCH]["BOTH"], " ") != "941735 1980196 1771258 2382421" {
+		t.Fatal(solutions)
+	}
+	info := infos[runtime.GOARCH]["BOTH"]
+	if strings.Join(info.OptionalNotes, " ") != "2578899" || strings.Join(info.Includes, " ") != "DB BASE" {
+		t.Fatal(info)
+	}
+	if info.Description != "database and application server" || info.Version != "2" {
+		t.Fatal(info)
+	}
+	// cyclic and unknown includes
+	for _, solName := range []string{"CYCLE1", "CYCLE2", "MISSING"} {
+		if _, ok := solutions[runtime.GOARCH][solName]; ok {
+			t.Fatal(solutions)
+		}
+	}
+
+	infos = GetSolutionInfo("/saptune_file_not_avail")
+	if len(infos) != 0 {
+		t.Fatal(infos)
+	}
+}
+
 func TestGetOverrideSolution(t *testing.T) {
 	ovsolutionFile := path.Join(TstFilesInGOPATH, "saptune-test-override-sols")
 	noteFiles := TstFilesInGOPATH + "/"
@@ -81,14 +122,159 @@ func TestGetDeprecatedSolution(t *testing.T) {
 		t.Fatal(solutions)
 	}
 
+	oldDeprecSolutions := DeprecSolutions
+	defer func() { DeprecSolutions = oldDeprecSolutions }()
+	DeprecSolutions = solutions
+	if GetDeprecatedSuccessor(runtime.GOARCH, "BWA") != "NETW" || GetDeprecatedSuccessor(runtime.GOARCH, "MAXDB") != "" {
+		t.Fatal(solutions)
+	}
+
 	sols := GetDeprecatedSolution("/saptune_file_not_avail")
 	if len(sols) != 0 {
 		t.Fatal(sols)
 	}
 }
 
+func TestGetCustomSolutions(t *testing.T) {
+	extraDir := path.Join(TstFilesInGOPATH, "extra")
+	oldAllSolutions := AllSolutions
+	oldCustomSolutions := CustomSolutions
+	defer func() {
+		AllSolutions = oldAllSolutions
+		CustomSolutions = oldCustomSolutions
+	}()
+	AllSolutions = GetSolutionDefintion(path.Join(TstFilesInGOPATH, "saptune-test-solutions"))
+	solcount := 2
+	if system.IsPagecacheAvailable() {
+		solcount = 4
+	}
+
+	customInfos := make(map[string]map[string]SolutionInfo)
+	CustomSolutions, customInfos = GetCustomSolutions(extraDir, note.GetTuningOptions("", extraDir))
+	if len(CustomSolutions) != solcount {
+		t.Fatalf("'%+v' has len '%+v'\n", CustomSolutions, len(CustomSolutions))
+	}
+	if strings.Join(CustomSolutions[runtime.GOARCH]["MYCORP-HANA-DR"], " ") != "simpleNote extraNote" {
+		t.Fatal(CustomSolutions)
+	}
+	// note not available
+	if _, ok := CustomSolutions[runtime.GOARCH]["MYCORP-BROKEN"]; ok {
+		t.Fatal(CustomSolutions)
+	}
+	// shipped solution
+	if _, ok := CustomSolutions[runtime.GOARCH]["NETW"]; ok {
+		t.Fatal(CustomSolutions)
+	}
+
+	// optional note
+	if strings.Join(CustomSolutions[runtime.GOARCH]["MYCORP-SIMPLE"], " ") != "simpleNote" || strings.Join(customInfos[runtime.GOARCH]["MYCORP-SIMPLE"].OptionalNotes, " ") != "extraNote" {
+		t.Fatal(CustomSolutions, customInfos)
+	}
+	// includes a shipped solution with notes not available
+	if _, ok := CustomSolutions[runtime.GOARCH]["MYCORP-ALL"]; ok {
+		t.Fatal(CustomSolutions)
+	}
+
+	AddCustomSolutions(CustomSolutions, customInfos)
+	if strings.Join(AllSolutions[runtime.GOARCH]["MYCORP-HANA-DR"], " ") != "simpleNote extraNote" {
+		t.Fatal(AllSolutions)
+	}
+	if strings.Join(AllSolutions[runtime.GOARCH]["NETW"], " ") != "941735 1771258 1980196 1984787 2534844" {
+		t.Fatal(AllSolutions)
+	}
+	if !IsCustomSolution(runtime.GOARCH, "MYCORP-HANA-DR") || IsCustomSolution(runtime.GOARCH, "NETW") {
+		t.Fatal(CustomSolutions)
+	}
+
+	sols, infos := GetCustomSolutions("/saptune_dir_not_avail", note.GetTuningOptions("", extraDir))
+	if len(sols) != 0 || len(infos) != 0 {
+		t.Fatal(sols, infos)
+	}
+}
+
 func TestGetSortedSolutionIDs(t *testing.T) {
 	if len(GetSortedSolutionNames(runtime.GOARCH)) != len(AllSolutions[runtime.GOARCH]) {
 		t.Fatal(GetSortedSolutionNames(runtime.GOARCH))
 	}
 }
+
+func TestSuggestSolution(t *testing.T) {
+	oldAllSolutions := AllSolutions
+	oldDeprecSolutions := DeprecSolutions
+	defer func() {
+		AllSolutions = oldAllSolutions
+		DeprecSolutions = oldDeprecSolutions
+	}()
+	AllSolutions = map[string]map[string]Solution{runtime.GOARCH: {
+		"NETWEAVER":      {"941735", "1771258"},
+		"HANA":           {"941735", "1771258", "1980196"},
+		"NETWEAVER+HANA": {"941735", "1771258", "1980196"},
+		"SAP-ASE":        {"941735", "1410736", "1771258"},
+		"MAXDB":          {"941735", "1771258"},
+		"BOBJ":           {"941735", "1771258", "SAP_BOBJ"},
+	}}
+	DeprecSolutions = map[string]map[string]string{runtime.GOARCH: {"MAXDB": "deprecated\tNETWEAVER"}}
+	hana := system.SAPComponent{Type: system.SAPCompHANA, SID: "HA0", Instance: "HDB00"}
+	netw := system.SAPComponent{Type: system.SAPCompNetWeaver, SID: "NW1", Instance: "ASCS01"}
+	ase := system.SAPComponent{Type: system.SAPCompASE, SID: "AS1"}
+	maxdb := system.SAPComponent{Type: system.SAPCompMaxDB, SID: "MD1"}
+	bobj := system.SAPComponent{Type: system.SAPCompBOBJ, SID: "BO1"}
+
+	for _, tst := range []struct {
+		comps      []system.SAPComponent
+		solName    string
+		reasonsLen int
+	}{
+		{[]system.SAPComponent{hana}, "HANA", 1},
+		{[]system.SAPComponent{netw, hana}, "NETWEAVER+HANA", 1},
+		{[]system.SAPComponent{netw}, "NETWEAVER", 1},
+		// NETWEAVER notes are part of SAP-ASE
+		{[]system.SAPComponent{netw, ase}, "SAP-ASE", 1},
+		// deprecated solution replaced by the successor
+		{[]system.SAPComponent{maxdb}, "NETWEAVER", 2},
+		// BOBJ notes are not part of HANA
+		{[]system.SAPComponent{hana, bobj}, "HANA", 2},
+		{[]system.SAPComponent{}, "", 0},
+	} {
+		solName, reasons := SuggestSolution(runtime.GOARCH, tst.comps)
+		if solName != tst.solName || len(reasons) != tst.reasonsLen {
+			t.Errorf("'%+v': expected '%s', got '%s' - '%v'", tst.comps, tst.solName, solName, reasons)
+		}
+	}
+
+	// suggested solution not available
+	delete(AllSolutions[runtime.GOARCH], "HANA")
+	if solName, reasons := SuggestSolution(runtime.GOARCH, []system.SAPComponent{hana}); solName != "" || len(reasons) != 2 {
+		t.Errorf("got '%s' - '%v'", solName, reasons)
+	}
+}
+
+func TestSuggestSolutionShipped(t *testing.T) {
+	oldAllSolutions := AllSolutions
+	oldDeprecSolutions := DeprecSolutions
+	defer func() {
+		AllSolutions = oldAllSolutions
+		DeprecSolutions = oldDeprecSolutions
+	}()
+	shippedDir := path.Join(os.Getenv("GOPATH"), "/src/github.com/SUSE/saptune/ospackage/usr/share/saptune")
+	AllSolutions = GetSolutionDefintion(path.Join(shippedDir, "solutions"))
+	DeprecSolutions = GetDeprecatedSolution(path.Join(shippedDir, "solsdeprecated"))
+
+	// a deprecated solution is never suggested, whatever SAP components
+	// are found on the system
+	for arch := range AllSolutions {
+		for _, rule := range suggestRules {
+			comps := []system.SAPComponent{}
+			for _, compType := range rule.compTypes {
+				comps = append(comps, system.SAPComponent{Type: compType})
+			}
+			solName, reasons := SuggestSolution(arch, comps)
+			if solName == "" {
+				t.Errorf("%s: no solution suggested for '%+v' - '%v'", arch, rule.compTypes, reasons)
+			}
+			if _, deprecated := DeprecSolutions[arch][solName]; deprecated {
+				t.Errorf("%s: deprecated solution '%s' suggested for '%+v'", arch, solName, rule.compTypes)
+			}
+		}
+	}
+}
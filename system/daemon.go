@@ -87,6 +87,24 @@ func SystemctlDisableStop(thing string) error {
 	return err
 }
 
+// SystemctlDaemonReload call systemctl daemon-reload to reload the unit
+// configuration.
+func SystemctlDaemonReload() error {
+	if out, err := exec.Command(systemctlCmd, "daemon-reload").CombinedOutput(); err != nil {
+		return ErrorLog("%v - Failed to call systemctl daemon-reload - %s", err, string(out))
+	}
+	return nil
+}
+
+// SystemctlDaemonReexec call systemctl daemon-reexec to re-execute the
+// systemd manager, which is needed to activate changes in system.conf.d.
+func SystemctlDaemonReexec() error {
+	if out, err := exec.Command(systemctlCmd, "daemon-reexec").CombinedOutput(); err != nil {
+		return ErrorLog("%v - Failed to call systemctl daemon-reexec - %s", err, string(out))
+	}
+	return nil
+}
+
 // SystemctlIsEnabled return true only if systemctl suggests that the thing is
 // enabled.
 func SystemctlIsEnabled(thing string) bool {
@@ -105,6 +123,41 @@ func SystemctlIsRunning(thing string) bool {
 	return false
 }
 
+// SystemctlIsFailed return true only if systemctl suggests that the thing is
+// in a failed state.
+func SystemctlIsFailed(thing string) bool {
+	if _, err := exec.Command(systemctlCmd, "is-failed", thing).CombinedOutput(); err == nil {
+		return true
+	}
+	return false
+}
+
+// GetSystemState returns the overall state of the system as reported by
+// 'systemctl is-system-running' (e.g. 'running', 'degraded', 'maintenance')
+// Return empty string if it cannot be determined.
+func GetSystemState() string {
+	// is-system-running returns a non-zero exit code for all states
+	// except 'running', so only the output is of interest
+	out, _ := exec.Command(systemctlCmd, "is-system-running").CombinedOutput()
+	return strings.TrimSpace(string(out))
+}
+
+// GetFailedUnits returns the names of the systemd units in a failed state
+func GetFailedUnits() []string {
+	units := []string{}
+	out, err := exec.Command(systemctlCmd, "--failed", "--no-legend", "--plain", "--no-pager").CombinedOutput()
+	if err != nil {
+		_ = ErrorLog("Failed to call systemctl to get the failed units - %v %s", err, string(out))
+		return units
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			units = append(units, fields[0])
+		}
+	}
+	return units
+}
+
 // IsSystemRunning returns true, if 'is-system-running' reports 'running'
 // or 'starting'. In all other cases it returns false, which means: do not
 // call 'start' or 'restart' to prevent 'Transaction is destructive' messages
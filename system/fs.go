@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os/exec"
+	"path"
 	"reflect"
 	"regexp"
 	"strconv"
@@ -13,6 +14,25 @@ import (
 
 var mountOptionSeparator = regexp.MustCompile("[[:space:]]*,[[:space:]]*")
 
+// mount options, which can be changed by a remount of the file system
+// and their counterparts, which are used for revert
+var remountOptions = map[string]string{
+	"noatime":     "relatime",
+	"relatime":    "norelatime",
+	"norelatime":  "relatime",
+	"strictatime": "relatime",
+	"nodiratime":  "diratime",
+	"diratime":    "nodiratime",
+	"lazytime":    "nolazytime",
+	"nolazytime":  "lazytime",
+	"nosuid":      "suid",
+	"suid":        "nosuid",
+	"nodev":       "dev",
+	"dev":         "nodev",
+	"noexec":      "exec",
+	"exec":        "noexec",
+}
+
 // MountPoint Represent a mount point entry in /proc/mounts or /etc/fstab
 type MountPoint struct {
 	Device     string
@@ -39,6 +59,16 @@ func (mount MountPoint) GetFileSystemSizeMB() uint64 {
 	return uint64(fs.Bsize) * fs.Blocks / 1048576
 }
 
+// HasOption return true, if the mount point is mounted with the given option.
+func (mount MountPoint) HasOption(option string) bool {
+	for _, opt := range mount.Options {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
 // MountPoints contains a list of mount points.
 type MountPoints []MountPoint
 
@@ -52,6 +82,17 @@ func (mounts MountPoints) GetByMountPoint(mountPoint string) (MountPoint, bool)
 	return MountPoint{}, false
 }
 
+// GetByMountPointGlob find all mount points matching the shell pattern.
+func (mounts MountPoints) GetByMountPointGlob(pattern string) MountPoints {
+	found := make([]MountPoint, 0, 0)
+	for _, mount := range mounts {
+		if match, _ := path.Match(pattern, mount.MountPoint); match {
+			found = append(found, mount)
+		}
+	}
+	return found
+}
+
 // ParseMounts return all mount points defined in the input text.
 // Panic on malformed entry.
 func ParseMounts(txt string) (mounts MountPoints) {
@@ -121,6 +162,29 @@ func RemountSHM(newSizeMB uint64) error {
 	return nil
 }
 
+// IsRemountOption return true, if the mount option can be changed by
+// remounting the file system.
+func IsRemountOption(option string) bool {
+	_, ok := remountOptions[option]
+	return ok
+}
+
+// RevertRemountOption return the mount option needed to revert the
+// given mount option by a remount of the file system.
+func RevertRemountOption(option string) string {
+	return remountOptions[option]
+}
+
+// RemountFS invoke mount command to change the mount options of the
+// file system mounted on the mount point.
+func RemountFS(mountPoint string, options []string) error {
+	cmd := exec.Command("mount", "-o", "remount,"+strings.Join(options, ","), mountPoint)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to invoke external command mount: %v, output: %s", err, out)
+	}
+	return nil
+}
+
 // ListDir list directory content.
 func ListDir(dirPath, logMsg string) (dirNames, fileNames []string) {
 	entries, err := ioutil.ReadDir(dirPath)
@@ -229,3 +229,35 @@ func TestListDir(t *testing.T) {
 		t.Fatal(files)
 	}
 }
+
+func TestGetByMountPointGlob(t *testing.T) {
+	mountPoints := ParseMounts(`/dev/sdb1 /hana/data/HDB xfs rw,noatime,inode64 0 0
+/dev/sdc1 /hana/log/HDB xfs rw,relatime,inode64,logbsize=256k 0 0
+/dev/sdd1 /hana/shared xfs rw,relatime 0 0
+`)
+	found := mountPoints.GetByMountPointGlob("/hana/*/HDB")
+	if len(found) != 2 || found[0].MountPoint != "/hana/data/HDB" || found[1].MountPoint != "/hana/log/HDB" {
+		t.Fatal(found)
+	}
+	if !found[0].HasOption("noatime") || found[1].HasOption("noatime") {
+		t.Fatal(found)
+	}
+	if !found[1].HasOption("logbsize=256k") {
+		t.Fatal(found[1])
+	}
+	if found := mountPoints.GetByMountPointGlob("/usr/sap/*"); len(found) != 0 {
+		t.Fatal(found)
+	}
+}
+
+func TestRemountOption(t *testing.T) {
+	if !IsRemountOption("noatime") || RevertRemountOption("noatime") != "relatime" {
+		t.Error("'noatime' should be a remount option with counterpart 'relatime'")
+	}
+	if !IsRemountOption("nodev") || RevertRemountOption("nodev") != "dev" {
+		t.Error("'nodev' should be a remount option with counterpart 'dev'")
+	}
+	if IsRemountOption("inode64") || RevertRemountOption("inode64") != "" {
+		t.Error("'inode64' should not be a remount option")
+	}
+}
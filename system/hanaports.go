@@ -0,0 +1,172 @@
+package system
+
+// Calculate the standard ports of the HANA instances of the system
+// (see SAP Note 2477204) and handle port lists in the format of
+// net.ipv4.ip_local_reserved_ports (e.g. '30013,30015,30040-30099')
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// HANAStandardPorts are the standard ports of a HANA instance. The
+// placeholder 'nn' is replaced by the instance number
+// 3nn00-3nn99 - internal, SQL and HTTP ports of the system and tenant databases
+// 5nn13-5nn14 - SAP start service (sapstartsrv)
+// 80nn, 43nn  - XS classic HTTP and HTTPS ports
+var HANAStandardPorts = []string{"3nn00-3nn99", "5nn13-5nn14", "80nn", "43nn"}
+
+var isInstanceNumber = regexp.MustCompile(`^[0-9]{2}$`)
+
+// GetHANAInstanceNumbers returns the instance numbers of the HANA instances
+// discovered on the system (e.g. '00' for instance HDB00)
+func GetHANAInstanceNumbers() []string {
+	instNos := []string{}
+	for _, comp := range GetSAPComponents() {
+		if comp.Type != SAPCompHANA {
+			continue
+		}
+		instNo := strings.TrimPrefix(comp.Instance, "HDB")
+		if !IsStringInList(instNo, instNos) {
+			instNos = append(instNos, instNo)
+		}
+	}
+	sort.Strings(instNos)
+	return instNos
+}
+
+// HANAReservedPorts returns the standard ports of the HANA instances with
+// the given instance numbers in the format of net.ipv4.ip_local_reserved_ports
+// Invalid instance numbers are skipped
+func HANAReservedPorts(instNos []string) string {
+	ports := []string{}
+	for _, instNo := range instNos {
+		if !isInstanceNumber.MatchString(instNo) {
+			WarningLog("skipping invalid HANA instance number '%s', two digits expected", instNo)
+			continue
+		}
+		for _, port := range HANAStandardPorts {
+			ports = append(ports, strings.Replace(port, "nn", instNo, -1))
+		}
+	}
+	return MergePortLists(strings.Join(ports, ","))
+}
+
+// ParsePortList returns the ports of a port list like '30013,30040-30099'
+// in ascending order without duplicates
+func ParsePortList(portList string) ([]int, error) {
+	found := make(map[int]bool)
+	for _, entry := range strings.Split(portList, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		bounds := strings.SplitN(entry, "-", 2)
+		low, err := strconv.Atoi(strings.TrimSpace(bounds[0]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid port '%s' in port list '%s'", entry, portList)
+		}
+		high := low
+		if len(bounds) == 2 {
+			if high, err = strconv.Atoi(strings.TrimSpace(bounds[1])); err != nil || high < low {
+				return nil, fmt.Errorf("invalid port range '%s' in port list '%s'", entry, portList)
+			}
+		}
+		if low < 1 || high > 65535 {
+			return nil, fmt.Errorf("port '%s' out of range in port list '%s'", entry, portList)
+		}
+		for port := low; port <= high; port++ {
+			found[port] = true
+		}
+	}
+	ports := make([]int, 0, len(found))
+	for port := range found {
+		ports = append(ports, port)
+	}
+	sort.Ints(ports)
+	return ports, nil
+}
+
+// FormatPortList returns the ports as port list in the format the kernel
+// uses for net.ipv4.ip_local_reserved_ports. Consecutive ports are
+// combined to a range. The ports need to be in ascending order
+func FormatPortList(ports []int) string {
+	entries := []string{}
+	for i := 0; i < len(ports); i++ {
+		low := ports[i]
+		for i+1 < len(ports) && ports[i+1] == ports[i]+1 {
+			i++
+		}
+		if ports[i] == low {
+			entries = append(entries, strconv.Itoa(low))
+		} else {
+			entries = append(entries, fmt.Sprintf("%d-%d", low, ports[i]))
+		}
+	}
+	return strings.Join(entries, ",")
+}
+
+// MergePortLists merges the given port lists into one port list in the
+// format of net.ipv4.ip_local_reserved_ports
+// Invalid port lists are skipped
+func MergePortLists(portLists ...string) string {
+	all := []int{}
+	for _, portList := range portLists {
+		ports, err := ParsePortList(portList)
+		if err != nil {
+			WarningLog("%v", err)
+			continue
+		}
+		all = append(all, ports...)
+	}
+	return FormatPortList(sortedUniquePorts(all))
+}
+
+// sortedUniquePorts returns the ports in ascending order without duplicates
+func sortedUniquePorts(ports []int) []int {
+	sort.Ints(ports)
+	ret := []int{}
+	for i, port := range ports {
+		if i == 0 || port != ports[i-1] {
+			ret = append(ret, port)
+		}
+	}
+	return ret
+}
+
+// UnreservedPortsInRange returns the ports of the port list, which are part
+// of the local port range (net.ipv4.ip_local_port_range, e.g. '9000 65499')
+// but not part of the reserved ports (net.ipv4.ip_local_reserved_ports)
+func UnreservedPortsInRange(portList, portRange, reserved string) string {
+	bounds := strings.Fields(portRange)
+	if len(bounds) != 2 {
+		return ""
+	}
+	low, errLow := strconv.Atoi(bounds[0])
+	high, errHigh := strconv.Atoi(bounds[1])
+	if errLow != nil || errHigh != nil {
+		return ""
+	}
+	ports, err := ParsePortList(portList)
+	if err != nil {
+		return ""
+	}
+	reservedPorts, err := ParsePortList(reserved)
+	if err != nil {
+		reservedPorts = []int{}
+	}
+	isReserved := make(map[int]bool)
+	for _, port := range reservedPorts {
+		isReserved[port] = true
+	}
+	unreserved := []int{}
+	for _, port := range ports {
+		if port >= low && port <= high && !isReserved[port] {
+			unreserved = append(unreserved, port)
+		}
+	}
+	return FormatPortList(unreserved)
+}
@@ -0,0 +1,68 @@
+package system
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHANAReservedPorts(t *testing.T) {
+	ports := HANAReservedPorts([]string{"00"})
+	if ports != "4300,8000,30000-30099,50013-50014" {
+		t.Errorf("wrong ports '%s'", ports)
+	}
+	ports = HANAReservedPorts([]string{"01", "00", "1", "HDB02"})
+	if ports != "4300-4301,8000-8001,30000-30199,50013-50014,50113-50114" {
+		t.Errorf("wrong ports '%s'", ports)
+	}
+	if ports = HANAReservedPorts([]string{}); ports != "" {
+		t.Errorf("wrong ports '%s'", ports)
+	}
+}
+
+func TestParsePortList(t *testing.T) {
+	ports, err := ParsePortList("30015, 30013-30014,30013,")
+	if err != nil || !reflect.DeepEqual(ports, []int{30013, 30014, 30015}) {
+		t.Errorf("wrong ports '%v', error '%v'", ports, err)
+	}
+	if ports, err = ParsePortList(""); err != nil || len(ports) != 0 {
+		t.Errorf("wrong ports '%v', error '%v'", ports, err)
+	}
+	for _, portList := range []string{"3001a", "30015-30013", "0", "65530-65536", "30013-"} {
+		if _, err := ParsePortList(portList); err == nil {
+			t.Errorf("expected an error for '%s'", portList)
+		}
+	}
+}
+
+func TestFormatPortList(t *testing.T) {
+	if ports := FormatPortList([]int{1, 2, 3, 5, 7, 8}); ports != "1-3,5,7-8" {
+		t.Errorf("wrong port list '%s'", ports)
+	}
+	if ports := FormatPortList([]int{}); ports != "" {
+		t.Errorf("wrong port list '%s'", ports)
+	}
+}
+
+func TestMergePortLists(t *testing.T) {
+	ports := MergePortLists("30013,30015", "30014,30040-30099", "invalid", "")
+	if ports != "30013-30015,30040-30099" {
+		t.Errorf("wrong ports '%s'", ports)
+	}
+}
+
+func TestUnreservedPortsInRange(t *testing.T) {
+	hanaPorts := HANAReservedPorts([]string{"00"})
+	ports := UnreservedPortsInRange(hanaPorts, "9000\t65499", "30000-30099")
+	if ports != "50013-50014" {
+		t.Errorf("wrong ports '%s'", ports)
+	}
+	if ports = UnreservedPortsInRange(hanaPorts, "9000 65499", hanaPorts); ports != "" {
+		t.Errorf("wrong ports '%s'", ports)
+	}
+	if ports = UnreservedPortsInRange(hanaPorts, "32768 60999", ""); ports != "50013-50014" {
+		t.Errorf("wrong ports '%s'", ports)
+	}
+	if ports = UnreservedPortsInRange(hanaPorts, "", ""); ports != "" {
+		t.Errorf("wrong ports '%s'", ports)
+	}
+}
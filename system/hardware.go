@@ -0,0 +1,270 @@
+package system
+
+// Gather information about the hardware and the platform saptune is running
+// on. Used to check the prerequisites of SAP Notes.
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+var procCPUInfo = "/proc/cpuinfo"
+var procOsRelease = "/proc/sys/kernel/osrelease"
+var detectVirtCmd = "/usr/bin/systemd-detect-virt"
+
+// ParseCPUInfo returns the cpu vendor and the cpu model found in the
+// content of /proc/cpuinfo
+// only the first processor entry is evaluated
+func ParseCPUInfo(txt string) (string, string) {
+	vendor := ""
+	model := ""
+	for _, line := range strings.Split(txt, "\n") {
+		fields := strings.SplitN(line, ":", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		key := strings.TrimSpace(fields[0])
+		val := strings.TrimSpace(fields[1])
+		switch key {
+		case "vendor_id":
+			// x86_64 and s390x
+			if vendor == "" {
+				vendor = val
+			}
+		case "model name", "cpu":
+			// 'cpu' is used on ppc64le
+			if model == "" {
+				model = val
+			}
+		}
+	}
+	if vendor == "" && strings.HasPrefix(model, "POWER") {
+		vendor = "IBM"
+	}
+	return vendor, model
+}
+
+// GetCPUVendor returns the cpu vendor of the system
+func GetCPUVendor() string {
+	content, err := ioutil.ReadFile(procCPUInfo)
+	if err != nil {
+		return ""
+	}
+	vendor, _ := ParseCPUInfo(string(content))
+	return vendor
+}
+
+// GetCPUModel returns the cpu model of the system
+func GetCPUModel() string {
+	content, err := ioutil.ReadFile(procCPUInfo)
+	if err != nil {
+		return ""
+	}
+	_, model := ParseCPUInfo(string(content))
+	return model
+}
+
+// GetKernelVersion returns the version of the running kernel without the
+// kernel flavor (e.g. '4.12.14-122.37' instead of '4.12.14-122.37-default')
+func GetKernelVersion() string {
+	content, err := ioutil.ReadFile(procOsRelease)
+	if err != nil {
+		return ""
+	}
+	return StripKernelFlavor(strings.TrimSpace(string(content)))
+}
+
+// StripKernelFlavor removes the kernel flavor from a kernel release string
+func StripKernelFlavor(release string) string {
+	var re = regexp.MustCompile(`^(.*\d)-[a-zA-Z][\w]*$`)
+	matches := re.FindStringSubmatch(release)
+	if len(matches) == 0 {
+		return release
+	}
+	return matches[1]
+}
+
+var sysDMIDir = "/sys/class/dmi/id"
+var sysHypervisorType = "/sys/hypervisor/type"
+var procLparCfg = "/proc/ppc64/lparcfg"
+var procDTCompatible = "/proc/device-tree/hypervisor/compatible"
+
+// asset tag used by Microsoft Azure for all virtual machines
+var azureAssetTag = "7783-7084-3265-9085-8269-3286-77"
+
+// GetDMIValue returns the content of the given DMI file
+// (e.g. 'sys_vendor' or 'product_name')
+func GetDMIValue(name string) string {
+	content, err := ioutil.ReadFile(path.Join(sysDMIDir, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(content))
+}
+
+// CPUHasFlag returns true, if the given cpu flag is set in the content
+// of /proc/cpuinfo
+// only the first processor entry is evaluated
+func CPUHasFlag(txt, flag string) bool {
+	for _, line := range strings.Split(txt, "\n") {
+		fields := strings.SplitN(line, ":", 2)
+		if len(fields) != 2 || strings.TrimSpace(fields[0]) != "flags" {
+			continue
+		}
+		return IsStringInList(flag, strings.Fields(fields[1]))
+	}
+	return false
+}
+
+// VirtFromDMI maps the DMI vendor and product strings to a
+// virtualization type
+// returns an empty string, if no hypervisor could be identified
+func VirtFromDMI(dmiValues ...string) string {
+	for _, val := range dmiValues {
+		switch {
+		case strings.Contains(val, "VMware"):
+			return "vmware"
+		case strings.Contains(val, "QEMU"), strings.Contains(val, "KVM"), strings.Contains(val, "Amazon EC2"), strings.HasPrefix(val, "Google"):
+			return "kvm"
+		case strings.Contains(val, "Microsoft Corporation"):
+			return "microsoft"
+		case strings.Contains(val, "Xen"):
+			return "xen"
+		}
+	}
+	return ""
+}
+
+// detectVirt tries to identify the virtualization type by looking at
+// sysfs, DMI and cpuid information
+// returns an empty string, if the type could not be identified
+func detectVirt() string {
+	if _, err := os.Stat(procLparCfg); err == nil {
+		// IBM Power LPAR
+		content, _ := ioutil.ReadFile(procDTCompatible)
+		if strings.Contains(strings.ToLower(string(content)), "kvm") {
+			return "kvm"
+		}
+		return "powervm"
+	}
+	if content, err := ioutil.ReadFile(sysHypervisorType); err == nil && strings.TrimSpace(string(content)) == "xen" {
+		return "xen"
+	}
+	if runtime.GOARCH == "amd64" {
+		// the 'hypervisor' cpuid bit is set by all hypervisors
+		content, err := ioutil.ReadFile(procCPUInfo)
+		if err == nil && !CPUHasFlag(string(content), "hypervisor") {
+			return "none"
+		}
+	}
+	return VirtFromDMI(GetDMIValue("sys_vendor"), GetDMIValue("product_name"), GetDMIValue("bios_vendor"))
+}
+
+// GetVirtType returns the virtualization type of the system
+// ('kvm', 'vmware', 'powervm', 'microsoft', 'xen' or 'none' for bare metal)
+// If the type can not be identified from sysfs, DMI or cpuid information,
+// the output of systemd-detect-virt is used
+func GetVirtType() string {
+	if virt := detectVirt(); virt != "" {
+		return virt
+	}
+	if !CmdIsAvailable(detectVirtCmd) {
+		WarningLog("command '%s' not found", detectVirtCmd)
+		return ""
+	}
+	// systemd-detect-virt returns with exit code 1, if no
+	// virtualization is detected, but prints 'none'
+	out, _ := exec.Command(detectVirtCmd).Output()
+	return strings.TrimSpace(string(out))
+}
+
+// CloudFromDMI maps the DMI vendor strings and the chassis asset tag to
+// a cloud provider ('azure', 'aws', 'gcp' or 'none')
+func CloudFromDMI(assetTag string, dmiValues ...string) string {
+	if assetTag == azureAssetTag {
+		return "azure"
+	}
+	for _, val := range dmiValues {
+		val = strings.ToLower(val)
+		switch {
+		case strings.Contains(val, "amazon"):
+			return "aws"
+		case strings.Contains(val, "google"):
+			return "gcp"
+		}
+	}
+	return "none"
+}
+
+// GetCloudProvider returns the cloud provider the system is running on
+// ('azure', 'aws', 'gcp' or 'none')
+func GetCloudProvider() string {
+	return CloudFromDMI(GetDMIValue("chassis_asset_tag"), GetDMIValue("sys_vendor"), GetDMIValue("product_name"), GetDMIValue("bios_vendor"), GetDMIValue("bios_version"))
+}
+
+// CmpKernelVers compares two kernel versions (without kernel flavor)
+// Return 0 (Equal), 1 (GreaterThan) or -1 (LessThan)
+// If one of the versions does not contain a release part (e.g. '5.3.18'),
+// only the version part is compared
+func CmpKernelVers(vers1, vers2 string) int {
+	v1 := strings.SplitN(vers1, "-", 2)
+	v2 := strings.SplitN(vers2, "-", 2)
+	if ret := CheckRpmVers(v1[0], v2[0]); ret != 0 || len(v1) < 2 || len(v2) < 2 {
+		return ret
+	}
+	return CheckRpmVers(v1[1], v2[1])
+}
+
+var sysCPUOnline = "/sys/devices/system/cpu/online"
+var sysNodeDir = "/sys/devices/system/node"
+
+// GetCPUCount returns the number of online cpus of the system
+func GetCPUCount() int {
+	content, err := ioutil.ReadFile(sysCPUOnline)
+	if err != nil {
+		return runtime.NumCPU()
+	}
+	cnt := 0
+	// 0-3,8-11
+	for _, cpuRange := range strings.Split(strings.TrimSpace(string(content)), ",") {
+		bounds := strings.SplitN(cpuRange, "-", 2)
+		first, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			continue
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, err = strconv.Atoi(bounds[1]); err != nil {
+				continue
+			}
+		}
+		cnt = cnt + last - first + 1
+	}
+	if cnt == 0 {
+		return runtime.NumCPU()
+	}
+	return cnt
+}
+
+// GetNumaNodes returns the number of numa nodes of the system
+func GetNumaNodes() int {
+	var isNode = regexp.MustCompile(`^node\d+$`)
+	cnt := 0
+	dirs, _ := ListDir(sysNodeDir, "")
+	for _, dir := range dirs {
+		if isNode.MatchString(dir) {
+			cnt = cnt + 1
+		}
+	}
+	if cnt == 0 {
+		// no numa support, all memory belongs to one node
+		return 1
+	}
+	return cnt
+}
@@ -0,0 +1,176 @@
+package system
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestParseCPUInfo(t *testing.T) {
+	x86 := `processor	: 0
+vendor_id	: GenuineIntel
+cpu family	: 6
+model		: 85
+model name	: Intel(R) Xeon(R) Platinum 8280 CPU @ 2.70GHz
+
+processor	: 1
+vendor_id	: GenuineIntel
+model name	: Intel(R) Xeon(R) Platinum 8280 CPU @ 2.70GHz
+`
+	vendor, model := ParseCPUInfo(x86)
+	if vendor != "GenuineIntel" {
+		t.Errorf("wrong vendor '%s'", vendor)
+	}
+	if model != "Intel(R) Xeon(R) Platinum 8280 CPU @ 2.70GHz" {
+		t.Errorf("wrong model '%s'", model)
+	}
+
+	ppc := `processor	: 0
+cpu		: POWER9 (architected), altivec supported
+clock		: 3800.000000MHz
+revision	: 2.2 (pvr 004e 1202)
+`
+	vendor, model = ParseCPUInfo(ppc)
+	if vendor != "IBM" {
+		t.Errorf("wrong vendor '%s'", vendor)
+	}
+	if model != "POWER9 (architected), altivec supported" {
+		t.Errorf("wrong model '%s'", model)
+	}
+
+	vendor, model = ParseCPUInfo("")
+	if vendor != "" || model != "" {
+		t.Errorf("expected empty values, got '%s' and '%s'", vendor, model)
+	}
+}
+
+func TestStripKernelFlavor(t *testing.T) {
+	for release, exp := range map[string]string{
+		"4.12.14-122.37-default": "4.12.14-122.37",
+		"5.3.18-24.37-azure":     "5.3.18-24.37",
+		"4.12.14-122.37":         "4.12.14-122.37",
+		"5.3.18":                 "5.3.18",
+	} {
+		if val := StripKernelFlavor(release); val != exp {
+			t.Errorf("'%s': expected '%s', got '%s'", release, exp, val)
+		}
+	}
+}
+
+func TestCmpKernelVers(t *testing.T) {
+	if CmpKernelVers("4.12.14-122.37", "4.12.14-122.37") != 0 {
+		t.Error("versions should be equal")
+	}
+	if CmpKernelVers("4.12.14-122.37", "4.12.14-95.48") != 1 {
+		t.Error("first version should be greater")
+	}
+	if CmpKernelVers("4.12.14-95.48", "5.3.18-24") != -1 {
+		t.Error("first version should be less")
+	}
+	if CmpKernelVers("5.3.18-24.37", "5.3.18") != 0 {
+		t.Error("versions without release should only compare the version part")
+	}
+}
+
+func TestGetCPUCount(t *testing.T) {
+	if cnt := GetCPUCount(); cnt < 1 {
+		t.Errorf("wrong number of cpus '%d'", cnt)
+	}
+	oldCPUOnline := sysCPUOnline
+	defer func() { sysCPUOnline = oldCPUOnline }()
+	tmpFile := path.Join(os.TempDir(), "saptune_cpu_online")
+	defer os.Remove(tmpFile)
+	if err := ioutil.WriteFile(tmpFile, []byte("0-3,8-11,16\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sysCPUOnline = tmpFile
+	if cnt := GetCPUCount(); cnt != 9 {
+		t.Errorf("expected 9 cpus, got '%d'", cnt)
+	}
+}
+
+func TestGetNumaNodes(t *testing.T) {
+	if cnt := GetNumaNodes(); cnt < 1 {
+		t.Errorf("wrong number of numa nodes '%d'", cnt)
+	}
+	oldNodeDir := sysNodeDir
+	defer func() { sysNodeDir = oldNodeDir }()
+	sysNodeDir = "/not_avail"
+	if cnt := GetNumaNodes(); cnt != 1 {
+		t.Errorf("expected 1 numa node, got '%d'", cnt)
+	}
+}
+
+func TestCPUHasFlag(t *testing.T) {
+	cpuinfo := `processor	: 0
+vendor_id	: GenuineIntel
+flags		: fpu vme de pse tsc msr hypervisor lahf_lm
+`
+	if !CPUHasFlag(cpuinfo, "hypervisor") {
+		t.Error("flag 'hypervisor' not found")
+	}
+	if CPUHasFlag(cpuinfo, "hyper") {
+		t.Error("flag 'hyper' should not be found")
+	}
+	if CPUHasFlag("", "hypervisor") {
+		t.Error("flag found in empty cpuinfo")
+	}
+}
+
+func TestVirtFromDMI(t *testing.T) {
+	for exp, dmi := range map[string][]string{
+		"vmware":    {"VMware, Inc.", "VMware Virtual Platform", "Phoenix Technologies LTD"},
+		"kvm":       {"QEMU", "Standard PC (i440FX + PIIX, 1996)", "SeaBIOS"},
+		"microsoft": {"Microsoft Corporation", "Virtual Machine", "Microsoft Corporation"},
+		"xen":       {"Xen", "HVM domU", "Xen"},
+		"":          {"Dell Inc.", "PowerEdge R740", "Dell Inc."},
+	} {
+		if val := VirtFromDMI(dmi...); val != exp {
+			t.Errorf("'%v': expected '%s', got '%s'", dmi, exp, val)
+		}
+	}
+	if val := VirtFromDMI("Amazon EC2", "m5.xlarge"); val != "kvm" {
+		t.Errorf("expected 'kvm', got '%s'", val)
+	}
+}
+
+func TestCloudFromDMI(t *testing.T) {
+	if val := CloudFromDMI(azureAssetTag, "Microsoft Corporation", "Virtual Machine"); val != "azure" {
+		t.Errorf("expected 'azure', got '%s'", val)
+	}
+	if val := CloudFromDMI("", "Amazon EC2", "m5.xlarge", "Amazon EC2", "1.0"); val != "aws" {
+		t.Errorf("expected 'aws', got '%s'", val)
+	}
+	if val := CloudFromDMI("", "Xen", "HVM domU", "Xen", "4.2.amazon"); val != "aws" {
+		t.Errorf("expected 'aws', got '%s'", val)
+	}
+	if val := CloudFromDMI("Google", "Google", "Google Compute Engine"); val != "gcp" {
+		t.Errorf("expected 'gcp', got '%s'", val)
+	}
+	if val := CloudFromDMI("", "Microsoft Corporation", "Virtual Machine"); val != "none" {
+		t.Errorf("expected 'none', got '%s'", val)
+	}
+}
+
+func TestGetDMIValue(t *testing.T) {
+	oldDMIDir := sysDMIDir
+	defer func() { sysDMIDir = oldDMIDir }()
+	sysDMIDir = path.Join(os.TempDir(), "saptune_dmi")
+	defer os.RemoveAll(sysDMIDir)
+	if err := os.MkdirAll(sysDMIDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path.Join(sysDMIDir, "sys_vendor"), []byte("Amazon EC2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if val := GetDMIValue("sys_vendor"); val != "Amazon EC2" {
+		t.Errorf("expected 'Amazon EC2', got '%s'", val)
+	}
+	if val := GetDMIValue("product_name"); val != "" {
+		t.Errorf("expected empty value, got '%s'", val)
+	}
+	if val := GetCloudProvider(); val != "aws" {
+		t.Errorf("expected 'aws', got '%s'", val)
+	}
+}
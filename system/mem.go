@@ -14,6 +14,7 @@ import (
 const (
 	MemMainTotalKey = "MemTotal"
 	MemSwapTotalKey = "SwapTotal"
+	MemHugepageKey  = "Hugepagesize"
 )
 
 // ParseMeminfo parse /proc/meminfo into key(string) - value(int) pairs.
@@ -47,6 +48,18 @@ func GetMainMemSizeMB() uint64 {
 	return ParseMeminfo()[MemMainTotalKey] / 1024
 }
 
+// GetSwapSizeMB return size of the system swap memory.
+// Panic on error.
+func GetSwapSizeMB() uint64 {
+	return ParseMeminfo()[MemSwapTotalKey] / 1024
+}
+
+// GetHugepageSizeKB return the default hugepage size in KB.
+// Panic on error.
+func GetHugepageSizeKB() uint64 {
+	return ParseMeminfo()[MemHugepageKey]
+}
+
 // GetTotalMemSizeMB return size of system main memory plus swap.
 // Panic on error.
 func GetTotalMemSizeMB() uint64 {
@@ -24,6 +24,12 @@ func TestGetMemSize(t *testing.T) {
 	}
 }
 
+func TestGetHugepageSizeKB(t *testing.T) {
+	if size := GetHugepageSizeKB(); size != ParseMeminfo()[MemHugepageKey] {
+		t.Fatal(size)
+	}
+}
+
 func TestGetTotalMemSizePages(t *testing.T) {
 	if pages := GetTotalMemSizePages(); pages != GetTotalMemSizeMB()*1024/uint64(os.Getpagesize()) {
 		t.Fatal(pages)
@@ -0,0 +1,204 @@
+package system
+
+// Gather information about kernel modules and handle the saptune
+// owned modprobe and modules-load drop-in files
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+var procModules = "/proc/modules"
+var sysModuleDir = "/sys/module"
+var modprobeDir = "/etc/modprobe.d"
+var modulesLoadDir = "/etc/modules-load.d"
+var modprobeCmd = "/sbin/modprobe"
+
+// ParseProcModules returns the names of all modules listed in the content
+// of /proc/modules
+func ParseProcModules(txt string) map[string]bool {
+	mods := make(map[string]bool)
+	for _, line := range strings.Split(txt, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		mods[fields[0]] = true
+	}
+	return mods
+}
+
+// IsModuleLoaded returns true, if the module is listed in /proc/modules
+// or is built into the kernel (available in /sys/module, but without
+// an initstate file)
+func IsModuleLoaded(module string) bool {
+	content, err := ioutil.ReadFile(procModules)
+	if err == nil && ParseProcModules(string(content))[normModuleName(module)] {
+		return true
+	}
+	modDir := path.Join(sysModuleDir, normModuleName(module))
+	if _, err := os.Stat(modDir); err != nil {
+		return false
+	}
+	if _, err := os.Stat(path.Join(modDir, "initstate")); os.IsNotExist(err) {
+		// built-in module
+		return true
+	}
+	return false
+}
+
+// IsModuleAvailable returns true, if the module is loaded or can be
+// loaded by modprobe
+func IsModuleAvailable(module string) bool {
+	if IsModuleLoaded(module) {
+		return true
+	}
+	if !CmdIsAvailable(modprobeCmd) {
+		return false
+	}
+	if _, err := exec.Command(modprobeCmd, "-n", module).CombinedOutput(); err != nil {
+		return false
+	}
+	return true
+}
+
+// IsModuleBlacklisted returns true, if one of the files in /etc/modprobe.d
+// contains a blacklist entry for the module
+func IsModuleBlacklisted(module string) bool {
+	_, files := ListDir(modprobeDir, "")
+	for _, file := range files {
+		if !strings.HasSuffix(file, ".conf") {
+			continue
+		}
+		content, err := ioutil.ReadFile(path.Join(modprobeDir, file))
+		if err != nil {
+			continue
+		}
+		if blacklistedInText(string(content), module) {
+			return true
+		}
+	}
+	return false
+}
+
+// blacklistedInText checks, if the modprobe configuration text contains a
+// blacklist entry for the module
+func blacklistedInText(txt, module string) bool {
+	for _, line := range strings.Split(txt, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "blacklist" && normModuleName(fields[1]) == normModuleName(module) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetModuleParam returns the current runtime value of a module parameter
+// from /sys/module/<module>/parameters/<param>
+func GetModuleParam(module, param string) (string, error) {
+	val, err := ioutil.ReadFile(path.Join(sysModuleDir, normModuleName(module), "parameters", param))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(val)), nil
+}
+
+// ModuleParamIsWritable returns true, if the module parameter can be
+// changed at runtime
+func ModuleParamIsWritable(module, param string) bool {
+	fInfo, err := os.Stat(path.Join(sysModuleDir, normModuleName(module), "parameters", param))
+	if err != nil {
+		return false
+	}
+	return fInfo.Mode().Perm()&0200 != 0
+}
+
+// SetModuleParam writes a module parameter value at runtime
+func SetModuleParam(module, param, value string) error {
+	if !ModuleParamIsWritable(module, param) {
+		return fmt.Errorf("module parameter '%s.%s' can not be changed at runtime", module, param)
+	}
+	return ioutil.WriteFile(path.Join(sysModuleDir, normModuleName(module), "parameters", param), []byte(value), 0644)
+}
+
+// LoadModule loads a module by using modprobe
+func LoadModule(module string) error {
+	if out, err := exec.Command(modprobeCmd, module).CombinedOutput(); err != nil {
+		return ErrorLog("%v - Failed to load module '%s' - %s", err, module, string(out))
+	}
+	return nil
+}
+
+// ModuleDropInFile returns the name of the saptune owned drop-in file for
+// a module state (load or blacklist) or a module parameter
+func ModuleDropInFile(module, param, state string) string {
+	if param != "" {
+		return path.Join(modprobeDir, fmt.Sprintf("saptune-%s-%s.conf", module, param))
+	}
+	if state == "load" {
+		return path.Join(modulesLoadDir, fmt.Sprintf("saptune-%s.conf", module))
+	}
+	return path.Join(modprobeDir, fmt.Sprintf("saptune-%s.conf", module))
+}
+
+// GetModuleDropInParam returns the value of a module parameter set in the
+// saptune owned modprobe drop-in file or an empty string
+func GetModuleDropInParam(module, param string) string {
+	content, err := ioutil.ReadFile(ModuleDropInFile(module, param, ""))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 || fields[0] != "options" || fields[1] != module {
+			continue
+		}
+		kv := strings.SplitN(fields[2], "=", 2)
+		if len(kv) == 2 && kv[0] == param {
+			return kv[1]
+		}
+	}
+	return ""
+}
+
+// WriteModuleDropIn writes the saptune owned drop-in file for a module
+// state (load or blacklist) or a module parameter
+func WriteModuleDropIn(module, param, value, noteID string) error {
+	dropInFile := ModuleDropInFile(module, param, value)
+	content := fmt.Sprintf("### %s\n### file autogenerated by saptune!\n### requested by Note %s\n###\n### Please do NOT change or delete!\n###\n\n", dropInFile, noteID)
+	switch {
+	case param != "":
+		content = content + fmt.Sprintf("options %s %s=%s\n", module, param, value)
+	case value == "load":
+		content = content + fmt.Sprintf("%s\n", module)
+	case value == "blacklist":
+		content = content + fmt.Sprintf("blacklist %s\n", module)
+	default:
+		return fmt.Errorf("unsupported module state '%s' for module '%s'", value, module)
+	}
+	if err := os.MkdirAll(path.Dir(dropInFile), 0755); err != nil {
+		return ErrorLog("failed to create needed directories for the module drop in file: %v", err)
+	}
+	return ioutil.WriteFile(dropInFile, []byte(content), 0644)
+}
+
+// RemoveModuleDropIn removes the saptune owned drop-in files of a module
+// state or a module parameter
+func RemoveModuleDropIn(module, param string) {
+	if param != "" {
+		_ = os.Remove(ModuleDropInFile(module, param, ""))
+		return
+	}
+	_ = os.Remove(ModuleDropInFile(module, "", "load"))
+	_ = os.Remove(ModuleDropInFile(module, "", "blacklist"))
+}
+
+// normModuleName replaces dashes by underscores as the kernel does in
+// /proc/modules and /sys/module
+func normModuleName(module string) string {
+	return strings.Replace(module, "-", "_", -1)
+}
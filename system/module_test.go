@@ -0,0 +1,118 @@
+package system
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestParseProcModules(t *testing.T) {
+	txt := `sctp 409600 4 - Live 0x0000000000000000
+libcrc32c 16384 2 sctp,xfs, Live 0x0000000000000000
+
+nf_conntrack 176128 0 - Live 0x0000000000000000
+`
+	mods := ParseProcModules(txt)
+	if len(mods) != 3 {
+		t.Errorf("expected 3 modules, got '%+v'", mods)
+	}
+	for _, mod := range []string{"sctp", "libcrc32c", "nf_conntrack"} {
+		if !mods[mod] {
+			t.Errorf("module '%s' not found in '%+v'", mod, mods)
+		}
+	}
+	if mods["xfs"] {
+		t.Errorf("module 'xfs' found, but shouldn't")
+	}
+}
+
+func TestBlacklistedInText(t *testing.T) {
+	txt := `# some comment
+blacklist floppy
+install pcspkr /bin/true
+options nf_conntrack hashsize=131072
+`
+	if !blacklistedInText(txt, "floppy") {
+		t.Error("floppy should be blacklisted")
+	}
+	if blacklistedInText(txt, "pcspkr") {
+		t.Error("pcspkr should not be blacklisted")
+	}
+	if blacklistedInText(txt, "nf_conntrack") {
+		t.Error("nf_conntrack should not be blacklisted")
+	}
+	if !blacklistedInText("blacklist nf-conntrack\n", "nf_conntrack") {
+		t.Error("nf-conntrack and nf_conntrack should be treated as the same module")
+	}
+}
+
+func TestModuleDropIn(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "saptune_module")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	oldModprobeDir := modprobeDir
+	oldModulesLoadDir := modulesLoadDir
+	modprobeDir = path.Join(tmpDir, "modprobe.d")
+	modulesLoadDir = path.Join(tmpDir, "modules-load.d")
+	defer func() {
+		modprobeDir = oldModprobeDir
+		modulesLoadDir = oldModulesLoadDir
+	}()
+
+	if ModuleDropInFile("sctp", "", "load") != path.Join(modulesLoadDir, "saptune-sctp.conf") {
+		t.Error(ModuleDropInFile("sctp", "", "load"))
+	}
+	if ModuleDropInFile("floppy", "", "blacklist") != path.Join(modprobeDir, "saptune-floppy.conf") {
+		t.Error(ModuleDropInFile("floppy", "", "blacklist"))
+	}
+	if ModuleDropInFile("nf_conntrack", "hashsize", "") != path.Join(modprobeDir, "saptune-nf_conntrack-hashsize.conf") {
+		t.Error(ModuleDropInFile("nf_conntrack", "hashsize", ""))
+	}
+
+	// module parameter
+	if err := WriteModuleDropIn("nf_conntrack", "hashsize", "131072", "4711"); err != nil {
+		t.Fatal(err)
+	}
+	if val := GetModuleDropInParam("nf_conntrack", "hashsize"); val != "131072" {
+		t.Errorf("expected '131072', got '%s'", val)
+	}
+	RemoveModuleDropIn("nf_conntrack", "hashsize")
+	if val := GetModuleDropInParam("nf_conntrack", "hashsize"); val != "" {
+		t.Errorf("expected empty value, got '%s'", val)
+	}
+
+	// blacklist
+	if IsModuleBlacklisted("floppy") {
+		t.Error("floppy should not be blacklisted")
+	}
+	if err := WriteModuleDropIn("floppy", "", "blacklist", "4711"); err != nil {
+		t.Fatal(err)
+	}
+	if !IsModuleBlacklisted("floppy") {
+		t.Error("floppy should be blacklisted")
+	}
+	RemoveModuleDropIn("floppy", "")
+	if IsModuleBlacklisted("floppy") {
+		t.Error("floppy should not be blacklisted any longer")
+	}
+
+	// load
+	if err := WriteModuleDropIn("sctp", "", "load", "4711"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(ModuleDropInFile("sctp", "", "load")); err != nil {
+		t.Error(err)
+	}
+	RemoveModuleDropIn("sctp", "")
+	if _, err := os.Stat(ModuleDropInFile("sctp", "", "load")); !os.IsNotExist(err) {
+		t.Error("drop-in file for module sctp still exists")
+	}
+
+	// unsupported state
+	if err := WriteModuleDropIn("sctp", "", "unload", "4711"); err == nil {
+		t.Error("expected an error for unsupported module state")
+	}
+}
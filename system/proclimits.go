@@ -0,0 +1,178 @@
+package system
+
+// Get the resource limits of running processes from /proc/<pid>/limits
+// to check, if changed limits are already active
+
+import (
+	"io/ioutil"
+	"os/user"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var procDir = "/proc"
+
+// procLimitItems maps the names used in /proc/<pid>/limits to the
+// limits.conf items
+var procLimitItems = map[string]string{
+	"Max cpu time":          "cpu",
+	"Max file size":         "fsize",
+	"Max data size":         "data",
+	"Max stack size":        "stack",
+	"Max core file size":    "core",
+	"Max resident set":      "rss",
+	"Max processes":         "nproc",
+	"Max open files":        "nofile",
+	"Max locked memory":     "memlock",
+	"Max address space":     "as",
+	"Max file locks":        "locks",
+	"Max pending signals":   "sigpending",
+	"Max msgqueue size":     "msgqueue",
+	"Max nice priority":     "nice",
+	"Max realtime priority": "rtprio",
+}
+
+// ParseProcLimits returns the soft and hard limits found in the content of
+// /proc/<pid>/limits for each limits.conf item
+// values, which are in KB in limits.conf, are converted from bytes to KB
+func ParseProcLimits(txt string) map[string][2]string {
+	var isLimit = regexp.MustCompile(`^(Max [a-z ]+?)\s{2,}(\S+)\s+(\S+)`)
+	limits := make(map[string][2]string)
+	for _, line := range strings.Split(txt, "\n") {
+		fields := isLimit.FindStringSubmatch(line)
+		if len(fields) != 4 {
+			continue
+		}
+		item, ok := procLimitItems[fields[1]]
+		if !ok {
+			continue
+		}
+		kb := false
+		if limit, ok := systemdLimitItems[item]; ok {
+			kb = limit.kb
+		}
+		limits[item] = [2]string{procLimitValue(fields[2], kb), procLimitValue(fields[3], kb)}
+	}
+	return limits
+}
+
+// procLimitValue converts a value of /proc/<pid>/limits into the
+// limits.conf notation
+func procLimitValue(val string, kb bool) string {
+	if val == "unlimited" || !kb {
+		return val
+	}
+	num, err := strconv.ParseUint(val, 10, 64)
+	if err != nil {
+		return val
+	}
+	return strconv.FormatUint(num/1024, 10)
+}
+
+// procOwner returns the real user id and the real group id of a process
+// from /proc/<pid>/status
+func procOwner(pid string) (string, string) {
+	uid := ""
+	gid := ""
+	content, err := ioutil.ReadFile(path.Join(procDir, pid, "status"))
+	if err != nil {
+		return uid, gid
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "Uid:":
+			uid = fields[1]
+		case "Gid:":
+			gid = fields[1]
+		}
+	}
+	return uid, gid
+}
+
+// GetDomainProcesses returns the process ids of all running processes,
+// which belong to the limits.conf domain (a user name or a group name
+// with a leading '@')
+// wildcards and uid/gid ranges are not supported
+func GetDomainProcesses(domain string) []string {
+	pids := []string{}
+	uid := ""
+	gid := ""
+	if strings.HasPrefix(domain, "@") {
+		grp, err := user.LookupGroup(strings.TrimPrefix(domain, "@"))
+		if err != nil {
+			return pids
+		}
+		gid = grp.Gid
+	} else {
+		usr, err := user.Lookup(domain)
+		if err != nil {
+			return pids
+		}
+		uid = usr.Uid
+	}
+	var isPid = regexp.MustCompile(`^\d+$`)
+	dirs, _ := ListDir(procDir, "")
+	for _, pid := range dirs {
+		if !isPid.MatchString(pid) {
+			continue
+		}
+		puid, pgid := procOwner(pid)
+		if (uid != "" && puid == uid) || (gid != "" && pgid == gid) {
+			pids = append(pids, pid)
+		}
+	}
+	return pids
+}
+
+// GetRuntimeLimit returns the effective limit of the running processes of
+// a limits.conf domain for the given type ('soft', 'hard' or '-') and item
+// If the processes use different limits, the smallest value is returned.
+// An empty string is returned, if there are no running processes.
+func GetRuntimeLimit(domain, limType, item string) string {
+	// for '-' the soft limit is the effective one
+	idx := 0
+	if limType == "hard" {
+		idx = 1
+	}
+	runtimeLimit := ""
+	for _, pid := range GetDomainProcesses(domain) {
+		content, err := ioutil.ReadFile(path.Join(procDir, pid, "limits"))
+		if err != nil {
+			// process vanished in the meantime
+			continue
+		}
+		limits, ok := ParseProcLimits(string(content))[item]
+		if !ok {
+			continue
+		}
+		if runtimeLimit == "" || CmpLimitValues(limits[idx], runtimeLimit) < 0 {
+			runtimeLimit = limits[idx]
+		}
+	}
+	return runtimeLimit
+}
+
+// CmpLimitValues compares two limits.conf values
+// 'unlimited', 'infinity' and '-1' are larger than all other values
+// Return 0 (Equal), 1 (GreaterThan) or -1 (LessThan)
+func CmpLimitValues(val1, val2 string) int {
+	lim1 := ToSecurityLimitInt(val1)
+	lim2 := ToSecurityLimitInt(val2)
+	switch {
+	case lim1 == lim2:
+		return 0
+	case lim1 == SecurityLimitUnlimitedValue:
+		return 1
+	case lim2 == SecurityLimitUnlimitedValue:
+		return -1
+	case lim1 > lim2:
+		return 1
+	}
+	return -1
+}
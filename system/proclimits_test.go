@@ -0,0 +1,96 @@
+package system
+
+import (
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path"
+	"testing"
+)
+
+var procLimitsContent = `Limit                     Soft Limit           Hard Limit           Units     
+Max cpu time              unlimited            unlimited            seconds   
+Max file size             unlimited            unlimited            bytes     
+Max processes             63423                63423                processes 
+Max open files            1024                 524288               files     
+Max locked memory         67108864             67108864             bytes     
+Max realtime priority     0                    0                    
+`
+
+func TestParseProcLimits(t *testing.T) {
+	limits := ParseProcLimits(procLimitsContent)
+	if limits["nofile"] != [2]string{"1024", "524288"} {
+		t.Errorf("wrong nofile limits '%v'", limits["nofile"])
+	}
+	if limits["memlock"] != [2]string{"65536", "65536"} {
+		t.Errorf("wrong memlock limits '%v'", limits["memlock"])
+	}
+	if limits["fsize"] != [2]string{"unlimited", "unlimited"} {
+		t.Errorf("wrong fsize limits '%v'", limits["fsize"])
+	}
+	if limits["rtprio"] != [2]string{"0", "0"} {
+		t.Errorf("wrong rtprio limits '%v'", limits["rtprio"])
+	}
+	if _, ok := limits["Limit"]; ok || len(limits) != 6 {
+		t.Errorf("wrong limits '%v'", limits)
+	}
+}
+
+func TestCmpLimitValues(t *testing.T) {
+	for _, vals := range [][]string{
+		{"1024", "1024"},
+		{"unlimited", "infinity"},
+		{"-1", "unlimited"},
+	} {
+		if CmpLimitValues(vals[0], vals[1]) != 0 {
+			t.Errorf("'%v' should be equal", vals)
+		}
+	}
+	if CmpLimitValues("unlimited", "65536") != 1 || CmpLimitValues("65536", "unlimited") != -1 {
+		t.Error("unlimited should be the larger value")
+	}
+	if CmpLimitValues("1024", "65536") != -1 || CmpLimitValues("65536", "1024") != 1 {
+		t.Error("wrong compare result")
+	}
+}
+
+func TestGetRuntimeLimit(t *testing.T) {
+	usr, err := user.Current()
+	if err != nil {
+		t.Skip("no current user available")
+	}
+	oldProcDir := procDir
+	defer func() { procDir = oldProcDir }()
+	procDir = path.Join(os.TempDir(), "saptune_proc")
+	defer os.RemoveAll(procDir)
+	for pid, limits := range map[string]string{
+		"4711": procLimitsContent,
+		"4712": "Max open files            4096                 524288               files\n",
+	} {
+		if err := os.MkdirAll(path.Join(procDir, pid), 0755); err != nil {
+			t.Fatal(err)
+		}
+		status := "Name:\thdbindexserver\nUid:\t" + usr.Uid + "\t" + usr.Uid + "\t" + usr.Uid + "\t" + usr.Uid + "\nGid:\t" + usr.Gid + "\t" + usr.Gid + "\n"
+		if err := ioutil.WriteFile(path.Join(procDir, pid, "status"), []byte(status), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path.Join(procDir, pid, "limits"), []byte(limits), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if pids := GetDomainProcesses(usr.Username); len(pids) != 2 {
+		t.Errorf("wrong processes '%v'", pids)
+	}
+	if val := GetRuntimeLimit(usr.Username, "soft", "nofile"); val != "1024" {
+		t.Errorf("wrong runtime limit '%s'", val)
+	}
+	if val := GetRuntimeLimit(usr.Username, "hard", "nofile"); val != "524288" {
+		t.Errorf("wrong runtime limit '%s'", val)
+	}
+	if val := GetRuntimeLimit(usr.Username, "hard", "memlock"); val != "65536" {
+		t.Errorf("wrong runtime limit '%s'", val)
+	}
+	if val := GetRuntimeLimit("no_such_user_4711", "hard", "nofile"); val != "" {
+		t.Errorf("wrong runtime limit '%s'", val)
+	}
+}
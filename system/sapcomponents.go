@@ -0,0 +1,143 @@
+package system
+
+// Discover the SAP components installed on the system.
+// Used to suggest a saptune solution.
+
+import (
+	"io/ioutil"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// SAP component types
+const (
+	SAPCompHANA      = "HANA"
+	SAPCompNetWeaver = "NETWEAVER"
+	SAPCompASE       = "ASE"
+	SAPCompMaxDB     = "MAXDB"
+	SAPCompBOBJ      = "BOBJ"
+)
+
+var sapDir = "/usr/sap"
+var sybaseDir = "/sybase"
+var sapdbDir = "/sapdb"
+
+// SAPComponent describes a SAP component found on the system
+type SAPComponent struct {
+	Type     string // one of the SAP component types
+	SID      string // SAP system ID
+	Instance string // instance name (e.g. HDB00), empty for ASE, MaxDB and BOBJ
+	Source   string // file or directory the component was found in
+}
+
+var isSID = regexp.MustCompile(`^[A-Z][A-Z0-9]{2}$`)
+var isHANAInstance = regexp.MustCompile(`^HDB[0-9]{2}$`)
+var isNetWeaverInstance = regexp.MustCompile(`^(ASCS|SCS|ERS|DVEBMGS|D|J|G|W)[0-9]{2}$`)
+var isSAPProfile = regexp.MustCompile(`pf=\S*/([A-Z][A-Z0-9]{2})_([A-Z]+[0-9]{2})_\S+`)
+
+// SAPInstanceType returns the SAP component type of an instance name
+// (e.g. 'HDB00' - HANA, 'ASCS01' - NETWEAVER) or an empty string for
+// unknown instance types like the SAP diagnostics agent (SMDA<nn>)
+func SAPInstanceType(instance string) string {
+	if isHANAInstance.MatchString(instance) {
+		return SAPCompHANA
+	}
+	if isNetWeaverInstance.MatchString(instance) {
+		return SAPCompNetWeaver
+	}
+	return ""
+}
+
+// ParseSAPServicesComponents returns the SAP instances found in the content
+// of /usr/sap/sapservices
+// e.g. '/usr/sap/HA0/HDB00/exe/sapstartsrv pf=/usr/sap/HA0/SYS/profile/HA0_HDB00_host -D -u ha0adm'
+func ParseSAPServicesComponents(txt, source string) []SAPComponent {
+	comps := []SAPComponent{}
+	for _, line := range strings.Split(txt, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		match := isSAPProfile.FindStringSubmatch(line)
+		if len(match) != 3 {
+			continue
+		}
+		if compType := SAPInstanceType(match[2]); compType != "" {
+			comps = append(comps, SAPComponent{Type: compType, SID: match[1], Instance: match[2], Source: source})
+		}
+	}
+	return comps
+}
+
+// GetSAPComponents discovers the SAP components installed on the system.
+// Sources are /usr/sap/sapservices, the instance profiles and the instance
+// directories (HANA hdbnameserver binary) in /usr/sap/<SID>, the BOBJ
+// installation in /usr/sap/<SID>/sap_bobj, the Sybase ASE installations in
+// /sybase/<SID>/ASE-* and the MaxDB installations in /sapdb/<SID>/db
+// Each component is reported only once per SID and instance
+func GetSAPComponents() []SAPComponent {
+	comps := []SAPComponent{}
+	add := func(comp SAPComponent) {
+		for _, c := range comps {
+			if c.Type == comp.Type && c.SID == comp.SID && c.Instance == comp.Instance {
+				return
+			}
+		}
+		comps = append(comps, comp)
+	}
+
+	if content, err := ioutil.ReadFile(sapServicesFile); err == nil {
+		for _, comp := range ParseSAPServicesComponents(string(content), sapServicesFile) {
+			add(comp)
+		}
+	}
+	sids, _ := ListDir(sapDir, "")
+	for _, sid := range sids {
+		if !isSID.MatchString(sid) {
+			continue
+		}
+		profileDir := path.Join(sapDir, sid, "SYS", "profile")
+		_, profiles := ListDir(profileDir, "")
+		for _, profile := range profiles {
+			// <SID>_<instance>_<host>
+			fields := strings.SplitN(profile, "_", 3)
+			if len(fields) != 3 || fields[0] != sid {
+				continue
+			}
+			if compType := SAPInstanceType(fields[1]); compType != "" {
+				add(SAPComponent{Type: compType, SID: sid, Instance: fields[1], Source: path.Join(profileDir, profile)})
+			}
+		}
+		instances, _ := ListDir(path.Join(sapDir, sid), "")
+		for _, instance := range instances {
+			if instance == "sap_bobj" {
+				add(SAPComponent{Type: SAPCompBOBJ, SID: sid, Source: path.Join(sapDir, sid, instance)})
+				continue
+			}
+			nameserver := path.Join(sapDir, sid, instance, "exe", "hdbnameserver")
+			if isHANAInstance.MatchString(instance) && CmdIsAvailable(nameserver) {
+				add(SAPComponent{Type: SAPCompHANA, SID: sid, Instance: instance, Source: nameserver})
+			}
+		}
+	}
+	sids, _ = ListDir(sybaseDir, "")
+	for _, sid := range sids {
+		if !isSID.MatchString(sid) {
+			continue
+		}
+		dirs, _ := ListDir(path.Join(sybaseDir, sid), "")
+		for _, dir := range dirs {
+			if strings.HasPrefix(dir, "ASE-") {
+				add(SAPComponent{Type: SAPCompASE, SID: sid, Source: path.Join(sybaseDir, sid, dir)})
+			}
+		}
+	}
+	sids, _ = ListDir(sapdbDir, "")
+	for _, sid := range sids {
+		if isSID.MatchString(sid) && CmdIsAvailable(path.Join(sapdbDir, sid, "db")) {
+			add(SAPComponent{Type: SAPCompMaxDB, SID: sid, Source: path.Join(sapdbDir, sid, "db")})
+		}
+	}
+	return comps
+}
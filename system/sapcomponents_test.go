@@ -0,0 +1,102 @@
+package system
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+)
+
+func TestSAPInstanceType(t *testing.T) {
+	for instance, exp := range map[string]string{
+		"HDB00":     SAPCompHANA,
+		"ASCS01":    SAPCompNetWeaver,
+		"ERS02":     SAPCompNetWeaver,
+		"D03":       SAPCompNetWeaver,
+		"DVEBMGS04": SAPCompNetWeaver,
+		"SMDA98":    "",
+		"HDB":       "",
+	} {
+		if compType := SAPInstanceType(instance); compType != exp {
+			t.Errorf("'%s': expected '%s', got '%s'", instance, exp, compType)
+		}
+	}
+}
+
+func TestParseSAPServicesComponents(t *testing.T) {
+	comps := ParseSAPServicesComponents(sapServicesContent, "sapservices")
+	exp := []SAPComponent{
+		{Type: SAPCompHANA, SID: "HA0", Instance: "HDB00", Source: "sapservices"},
+		{Type: SAPCompNetWeaver, SID: "NW1", Instance: "ASCS01", Source: "sapservices"},
+	}
+	if !reflect.DeepEqual(comps, exp) {
+		t.Errorf("wrong components '%+v'", comps)
+	}
+	if comps := ParseSAPServicesComponents("", "sapservices"); len(comps) != 0 {
+		t.Errorf("wrong components '%+v'", comps)
+	}
+}
+
+func TestGetSAPComponents(t *testing.T) {
+	oldSAPServicesFile := sapServicesFile
+	oldSAPDir := sapDir
+	oldSybaseDir := sybaseDir
+	oldSapdbDir := sapdbDir
+	defer func() {
+		sapServicesFile = oldSAPServicesFile
+		sapDir = oldSAPDir
+		sybaseDir = oldSybaseDir
+		sapdbDir = oldSapdbDir
+	}()
+	tstRoot := path.Join(os.TempDir(), "saptune_sapcomps")
+	defer os.RemoveAll(tstRoot)
+	sapDir = path.Join(tstRoot, "usr/sap")
+	sybaseDir = path.Join(tstRoot, "sybase")
+	sapdbDir = path.Join(tstRoot, "sapdb")
+	sapServicesFile = path.Join(sapDir, "sapservices")
+	for _, dir := range []string{
+		path.Join(sapDir, "HA0/HDB00/exe"),
+		path.Join(sapDir, "HA1/HDB01/exe"),
+		path.Join(sapDir, "NW1/SYS/profile"),
+		path.Join(sapDir, "BO1/sap_bobj"),
+		path.Join(sapDir, "hostctrl"),
+		path.Join(sybaseDir, "AS1/ASE-16_0"),
+		path.Join(sapdbDir, "MD1/db"),
+		path.Join(sapdbDir, "programs"),
+	} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for fileName, content := range map[string]string{
+		sapServicesFile: sapServicesContent,
+		path.Join(sapDir, "HA1/HDB01/exe/hdbnameserver"):     "",
+		path.Join(sapDir, "NW1/SYS/profile/NW1_ASCS01_host"): "",
+		path.Join(sapDir, "NW1/SYS/profile/NW1_D02_host"):    "",
+		path.Join(sapDir, "NW1/SYS/profile/DEFAULT.PFL"):     "",
+	} {
+		if err := ioutil.WriteFile(fileName, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	comps := GetSAPComponents()
+	exp := []SAPComponent{
+		{Type: SAPCompHANA, SID: "HA0", Instance: "HDB00", Source: sapServicesFile},
+		{Type: SAPCompNetWeaver, SID: "NW1", Instance: "ASCS01", Source: sapServicesFile},
+		{Type: SAPCompBOBJ, SID: "BO1", Source: path.Join(sapDir, "BO1/sap_bobj")},
+		{Type: SAPCompHANA, SID: "HA1", Instance: "HDB01", Source: path.Join(sapDir, "HA1/HDB01/exe/hdbnameserver")},
+		{Type: SAPCompNetWeaver, SID: "NW1", Instance: "D02", Source: path.Join(sapDir, "NW1/SYS/profile/NW1_D02_host")},
+		{Type: SAPCompASE, SID: "AS1", Source: path.Join(sybaseDir, "AS1/ASE-16_0")},
+		{Type: SAPCompMaxDB, SID: "MD1", Source: path.Join(sapdbDir, "MD1/db")},
+	}
+	if !reflect.DeepEqual(comps, exp) {
+		t.Errorf("wrong components\n'%+v'\nexpected\n'%+v'", comps, exp)
+	}
+
+	os.RemoveAll(tstRoot)
+	if comps := GetSAPComponents(); len(comps) != 0 {
+		t.Errorf("wrong components '%+v'", comps)
+	}
+}
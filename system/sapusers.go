@@ -0,0 +1,110 @@
+package system
+
+// Discover the SAP operating system users of the system.
+// Used to resolve placeholders like <sidadm> in SAP Note definitions.
+
+import (
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var sapServicesFile = "/usr/sap/sapservices"
+var passwdFile = "/etc/passwd"
+
+// SAPUserPlaceholders are the placeholders, which can be resolved by
+// discovering the SAP operating system users of the system
+// <sidadm> - SAP system administrator (e.g. ha0adm)
+// <sybsid> - Sybase ASE DBMS owner (e.g. sybha0), <DBMSuser> is an alias
+// <sdb>    - MaxDB software owner
+var SAPUserPlaceholders = []string{"sidadm", "sybsid", "DBMSuser", "sdb"}
+
+var isSidadm = regexp.MustCompile(`^[a-z][a-z0-9]{2}adm$`)
+var isSybsid = regexp.MustCompile(`^syb[a-z][a-z0-9]{2}$`)
+
+// ParseSAPServices returns the SAP system administrators (<sid>adm) found
+// in the content of /usr/sap/sapservices
+// e.g. '/usr/sap/HA0/HDB00/exe/sapstartsrv pf=/usr/sap/HA0/SYS/profile/HA0_HDB00_host -D -u ha0adm'
+func ParseSAPServices(txt string) []string {
+	var isSAPService = regexp.MustCompile(`/usr/sap/([A-Z][A-Z0-9]{2})/`)
+	var isSAPUser = regexp.MustCompile(`\s-u\s+(\w+)`)
+	users := []string{}
+	for _, line := range strings.Split(txt, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		user := ""
+		if match := isSAPUser.FindStringSubmatch(line); len(match) == 2 {
+			user = match[1]
+		} else if match := isSAPService.FindStringSubmatch(line); len(match) == 2 {
+			user = strings.ToLower(match[1]) + "adm"
+		}
+		if user != "" && !IsStringInList(user, users) {
+			users = append(users, user)
+		}
+	}
+	return users
+}
+
+// ParsePasswdUsers returns the user names found in the content of the
+// passwd database
+func ParsePasswdUsers(txt string) []string {
+	users := []string{}
+	for _, line := range strings.Split(txt, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), ":")
+		if len(fields) < 3 || fields[0] == "" || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		users = append(users, fields[0])
+	}
+	return users
+}
+
+// FilterSAPUsers returns the users matching the given placeholder from the
+// list of SAP services users and the list of users of the passwd database
+func FilterSAPUsers(placeholder string, serviceUsers, passwdUsers []string) []string {
+	found := []string{}
+	add := func(user string) {
+		if !IsStringInList(user, found) {
+			found = append(found, user)
+		}
+	}
+	switch placeholder {
+	case "sidadm":
+		for _, user := range append(serviceUsers, passwdUsers...) {
+			// 'sapadm' is the user of the SAP Host Agent
+			if isSidadm.MatchString(user) && user != "sapadm" {
+				add(user)
+			}
+		}
+	case "sybsid", "DBMSuser":
+		for _, user := range passwdUsers {
+			if isSybsid.MatchString(user) {
+				add(user)
+			}
+		}
+	case "sdb":
+		if IsStringInList("sdb", passwdUsers) {
+			add("sdb")
+		}
+	}
+	sort.Strings(found)
+	return found
+}
+
+// GetSAPUsers returns the SAP operating system users of the system matching
+// the given placeholder (without the surrounding '<>')
+// The users are discovered from /usr/sap/sapservices and the passwd database
+func GetSAPUsers(placeholder string) []string {
+	serviceUsers := []string{}
+	if content, err := ioutil.ReadFile(sapServicesFile); err == nil {
+		serviceUsers = ParseSAPServices(string(content))
+	}
+	passwdUsers := []string{}
+	if content, err := ioutil.ReadFile(passwdFile); err == nil {
+		passwdUsers = ParsePasswdUsers(string(content))
+	}
+	return FilterSAPUsers(placeholder, serviceUsers, passwdUsers)
+}
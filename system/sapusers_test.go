@@ -0,0 +1,76 @@
+package system
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+)
+
+var sapServicesContent = `#!/bin/sh
+LD_LIBRARY_PATH=/usr/sap/HA0/HDB00/exe:$LD_LIBRARY_PATH;export LD_LIBRARY_PATH;/usr/sap/HA0/HDB00/exe/sapstartsrv pf=/usr/sap/HA0/SYS/profile/HA0_HDB00_host -D -u ha0adm
+LD_LIBRARY_PATH=/usr/sap/NW1/ASCS01/exe:$LD_LIBRARY_PATH;export LD_LIBRARY_PATH;/usr/sap/NW1/ASCS01/exe/sapstartsrv pf=/usr/sap/NW1/SYS/profile/NW1_ASCS01_host -D
+# LD_LIBRARY_PATH=/usr/sap/OLD/HDB01/exe;/usr/sap/OLD/HDB01/exe/sapstartsrv -D -u oldadm
+`
+
+var passwdContent = `root:x:0:0:root:/root:/bin/bash
+ha0adm:x:1001:79:SAP System Administrator:/home/ha0adm:/bin/sh
+sybas1:x:1002:79:Sybase ASE owner:/home/sybas1:/bin/sh
+sdb:x:1003:1003:MaxDB owner:/home/sdb:/bin/false
+sapadm:x:1004:79:SAP Host Agent:/home/sapadm:/bin/false
+`
+
+func TestParseSAPServices(t *testing.T) {
+	users := ParseSAPServices(sapServicesContent)
+	if !reflect.DeepEqual(users, []string{"ha0adm", "nw1adm"}) {
+		t.Errorf("wrong users '%v'", users)
+	}
+	if users := ParseSAPServices(""); len(users) != 0 {
+		t.Errorf("wrong users '%v'", users)
+	}
+}
+
+func TestParsePasswdUsers(t *testing.T) {
+	users := ParsePasswdUsers(passwdContent)
+	if !reflect.DeepEqual(users, []string{"root", "ha0adm", "sybas1", "sdb", "sapadm"}) {
+		t.Errorf("wrong users '%v'", users)
+	}
+}
+
+func TestFilterSAPUsers(t *testing.T) {
+	serviceUsers := ParseSAPServices(sapServicesContent)
+	passwdUsers := ParsePasswdUsers(passwdContent)
+	for placeholder, exp := range map[string][]string{
+		"sidadm":   {"ha0adm", "nw1adm"},
+		"sybsid":   {"sybas1"},
+		"DBMSuser": {"sybas1"},
+		"sdb":      {"sdb"},
+		"unknown":  {},
+	} {
+		if users := FilterSAPUsers(placeholder, serviceUsers, passwdUsers); !reflect.DeepEqual(users, exp) {
+			t.Errorf("'%s': expected '%v', got '%v'", placeholder, exp, users)
+		}
+	}
+}
+
+func TestGetSAPUsers(t *testing.T) {
+	oldPasswdFile := passwdFile
+	oldSAPServicesFile := sapServicesFile
+	defer func() {
+		passwdFile = oldPasswdFile
+		sapServicesFile = oldSAPServicesFile
+	}()
+	passwdFile = path.Join(os.TempDir(), "saptune_passwd")
+	defer os.Remove(passwdFile)
+	if err := ioutil.WriteFile(passwdFile, []byte(passwdContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sapServicesFile = "/not_avail"
+	if users := GetSAPUsers("sybsid"); !reflect.DeepEqual(users, []string{"sybas1"}) {
+		t.Errorf("wrong users '%v'", users)
+	}
+	if users := GetSAPUsers("sidadm"); !reflect.DeepEqual(users, []string{"ha0adm"}) {
+		t.Errorf("wrong users '%v'", users)
+	}
+}
@@ -7,8 +7,11 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"syscall"
 )
 
 // mapping of system parameter names to configuration names
@@ -64,6 +67,35 @@ const (
 	SysctlRunChildFirst             = "kernel.sched_child_runs_first"
 )
 
+// sysctlDir is the base directory of the sysctl keys
+var sysctlDir = "/proc/sys"
+
+// ProcSysIsReadOnly returns true, if the kernel parameters in /proc/sys can
+// not be changed, because /proc/sys is mounted read-only (e.g. in containers)
+func ProcSysIsReadOnly() bool {
+	// 2 - W_OK
+	return syscall.Access(path.Join(sysctlDir, "vm", "swappiness"), 2) == syscall.EROFS
+}
+
+// ListSysctlKeys returns the sorted list of readable sysctl keys matching
+// the glob pattern (e.g. 'net.ipv4.tcp_*'). '*' matches dots too.
+func ListSysctlKeys(pattern string) []string {
+	keys := []string{}
+	_ = filepath.Walk(sysctlDir, func(fpath string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || info.Mode().Perm()&0444 == 0 {
+			return nil
+		}
+		rel, _ := filepath.Rel(sysctlDir, fpath)
+		key := strings.Replace(rel, "/", ".", -1)
+		if match, _ := filepath.Match(pattern, key); match {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	sort.Strings(keys)
+	return keys
+}
+
 // GetSysctlString read a sysctl key and return the string value.
 func GetSysctlString(parameter string) (string, error) {
 	val, err := ioutil.ReadFile(path.Join("/proc/sys", strings.Replace(parameter, ".", "/", -1)))
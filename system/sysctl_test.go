@@ -1,6 +1,12 @@
 package system
 
-import "testing"
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+)
 
 func TestReadSysctl(t *testing.T) {
 	if value, err := GetSysctlInt("vm.max_map_count"); err != nil {
@@ -101,3 +107,42 @@ func TestIsPagecacheAvailable(t *testing.T) {
 		t.Log("pagecache setting NOT available")
 	}
 }
+
+func TestListSysctlKeys(t *testing.T) {
+	oldSysctlDir := sysctlDir
+	defer func() { sysctlDir = oldSysctlDir }()
+	sysctlDir = path.Join(os.TempDir(), "saptune_sysctl")
+	defer os.RemoveAll(sysctlDir)
+	for file, mode := range map[string]os.FileMode{"vm/swappiness": 0644, "vm/dirty_ratio": 0644, "vm/drop_caches": 0200, "net/ipv4/tcp_rmem": 0644} {
+		fname := path.Join(sysctlDir, file)
+		_ = os.MkdirAll(path.Dir(fname), 0755)
+		if err := ioutil.WriteFile(fname, []byte("1\n"), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if keys := ListSysctlKeys("vm.*"); !reflect.DeepEqual(keys, []string{"vm.dirty_ratio", "vm.swappiness"}) {
+		t.Errorf("wrong keys '%v'", keys)
+	}
+	if keys := ListSysctlKeys("*tcp_rmem"); !reflect.DeepEqual(keys, []string{"net.ipv4.tcp_rmem"}) {
+		t.Errorf("wrong keys '%v'", keys)
+	}
+	if keys := ListSysctlKeys("kernel.*"); len(keys) != 0 {
+		t.Errorf("wrong keys '%v'", keys)
+	}
+}
+
+func TestProcSysIsReadOnly(t *testing.T) {
+	oldSysctlDir := sysctlDir
+	defer func() { sysctlDir = oldSysctlDir }()
+	sysctlDir = path.Join(os.TempDir(), "saptune_sysctl_ro")
+	defer os.RemoveAll(sysctlDir)
+	if err := os.MkdirAll(path.Join(sysctlDir, "vm"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path.Join(sysctlDir, "vm", "swappiness"), []byte("60\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if ProcSysIsReadOnly() {
+		t.Error("writable directory detected as read-only")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"os/user"
 	"path"
 	"path/filepath"
 	"reflect"
@@ -46,23 +47,95 @@ func IsUserRoot() bool {
 
 // CliArg returns the i-th command line parameter,
 // or empty string if it is not specified.
+// command line flags (e.g. '--force') are skipped
 func CliArg(i int) string {
-	if len(os.Args) >= i+1 {
-		return os.Args[i]
+	args := cliParams()
+	if len(args) >= i+1 {
+		return args[i]
 	}
 	return ""
 }
 
 // CliArgs returns all remaining command line parameters starting with i,
 // or empty string if it is not specified.
-// ANGI TODO - enhance command line parsing for 'flags' like '--dryrun', '--force'
+// command line flags (e.g. '--force') are skipped
 func CliArgs(i int) []string {
-	if len(os.Args) >= i+1 {
-		return os.Args[i:]
+	args := cliParams()
+	if len(args) >= i+1 {
+		return args[i:]
 	}
 	return []string{}
 }
 
+// cliValueFlags are the command line flags, which take the next command
+// line parameter as value, if the value is not given as '--<flag>=<value>'
+var cliValueFlags = []string{"set", "unset", "from", "from-system", "format", "before", "after", "order"}
+
+// isCliValueFlag returns true, if the command line parameter is a flag,
+// which takes the next command line parameter as value
+func isCliValueFlag(arg string) bool {
+	return strings.HasPrefix(arg, "--") && IsStringInList(strings.TrimPrefix(arg, "--"), cliValueFlags)
+}
+
+// cliParams returns the command line parameters without the command line
+// flags and their values. The first parameter (e.g. '--help' or
+// '--version') is never treated as flag
+func cliParams() []string {
+	params := []string{}
+	skipValue := false
+	for i, arg := range os.Args {
+		if skipValue {
+			skipValue = false
+			continue
+		}
+		if i > 1 && strings.HasPrefix(arg, "--") {
+			skipValue = isCliValueFlag(arg)
+			continue
+		}
+		params = append(params, arg)
+	}
+	return params
+}
+
+// IsFlagSet returns true, if the command line flag '--<flag>' or
+// '--<flag>=<value>' is set
+func IsFlagSet(flag string) bool {
+	for _, arg := range os.Args[1:] {
+		if arg == "--"+flag || strings.HasPrefix(arg, "--"+flag+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+// GetFlagVal returns the value of the command line flag '--<flag>=<value>'
+// or empty string if it is not specified
+// If the flag is specified more than once, the first value is returned
+func GetFlagVal(flag string) string {
+	if vals := GetFlagVals(flag); len(vals) != 0 {
+		return vals[0]
+	}
+	return ""
+}
+
+// GetFlagVals returns all values of a command line flag, which can be
+// specified more than once ('--<flag>=<value>' or, for flags taking a value,
+// '--<flag> <value>')
+func GetFlagVals(flag string) []string {
+	vals := []string{}
+	for i, arg := range os.Args {
+		if i == 0 {
+			continue
+		}
+		if strings.HasPrefix(arg, "--"+flag+"=") {
+			vals = append(vals, strings.TrimPrefix(arg, "--"+flag+"="))
+		} else if arg == "--"+flag && isCliValueFlag(arg) && i+1 < len(os.Args) {
+			vals = append(vals, os.Args[i+1])
+		}
+	}
+	return vals
+}
+
 // GetSolutionSelector returns the architecture string
 // needed to select the supported set os solutions
 func GetSolutionSelector() string {
@@ -81,6 +154,24 @@ func CmdIsAvailable(cmdName string) bool {
 	return true
 }
 
+// HelperCmd describes an external command used by saptune
+type HelperCmd struct {
+	Path     string // full path of the command
+	Purpose  string // what saptune needs the command for
+	Required bool   // saptune does not work without the command
+}
+
+// GetHelperCmds returns the external commands used by saptune
+func GetHelperCmds() []HelperCmd {
+	return []HelperCmd{
+		{Path: systemctlCmd, Purpose: "handling of the saptune and other services", Required: true},
+		{Path: cpupowerCmd, Purpose: "energy performance bias, cpu governor and idle states"},
+		{Path: modprobeCmd, Purpose: "loading of kernel modules"},
+		{Path: detectVirtCmd, Purpose: "detection of virtualised systems"},
+		{Path: "/bin/rpm", Purpose: "check of the installed package versions"},
+	}
+}
+
 // GetOsVers returns the OS version
 func GetOsVers() string {
 	// VERSION="12", VERSION="15"
@@ -144,6 +235,39 @@ func CheckForPattern(file, pattern string) bool {
 	return strings.Contains(string(content), pattern)
 }
 
+// IsStringInList returns true, if the string is an element of the list
+func IsStringInList(str string, list []string) bool {
+	for _, elem := range list {
+		if elem == str {
+			return true
+		}
+	}
+	return false
+}
+
+// GetGroupID returns the numeric group id of a group name. If the group
+// name is already a numeric group id, it is returned unchanged.
+func GetGroupID(group string) (string, error) {
+	if _, err := strconv.Atoi(group); err == nil {
+		return group, nil
+	}
+	grp, err := user.LookupGroup(group)
+	if err != nil {
+		return "", err
+	}
+	return grp.Gid, nil
+}
+
+// GetGroupName returns the group name of a numeric group id or the group
+// id, if no group name is available
+func GetGroupName(gid string) string {
+	grp, err := user.LookupGroupId(gid)
+	if err != nil {
+		return gid
+	}
+	return grp.Name
+}
+
 // GetAvailServices returns a map of the available services of the system
 func GetAvailServices() map[string]string {
 	allServices := make(map[string]string)
@@ -338,9 +462,19 @@ func CalledFrom() string {
 	return ret
 }
 
-// ErrorExit prints the message to stderr and exit 1.
+// errorExitCode is the exit code of ErrorExit, if no exit code is given
+var errorExitCode = 1
+
+// SetErrorExitCode changes the exit code of ErrorExit, if no exit code is
+// given (e.g. to separate the errors of 'saptune check' from its results)
+func SetErrorExitCode(code int) {
+	errorExitCode = code
+}
+
+// ErrorExit prints the message to stderr and exit 1 or the exit code set
+// by SetErrorExitCode.
 func ErrorExit(template string, stuff ...interface{}) {
-	exState := 1
+	exState := errorExitCode
 	fieldType := ""
 	field := len(stuff) - 1
 	if field >= 0 {
@@ -65,6 +65,44 @@ func TestCliArg(t *testing.T) {
 	}
 }
 
+func TestCliFlags(t *testing.T) {
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+	os.Args = []string{"saptune", "note", "verify", "--runtime", "--colors=no", "1805750"}
+	if arg := CliArg(3); arg != "1805750" {
+		t.Errorf("wrong argument '%s'", arg)
+	}
+	if args := CliArgs(2); len(args) != 2 || args[1] != "1805750" {
+		t.Errorf("wrong arguments '%v'", args)
+	}
+	if !IsFlagSet("runtime") || !IsFlagSet("colors") || IsFlagSet("force") {
+		t.Error("wrong flag detection")
+	}
+	if val := GetFlagVal("colors"); val != "no" {
+		t.Errorf("wrong flag value '%s'", val)
+	}
+	if val := GetFlagVal("runtime"); val != "" {
+		t.Errorf("wrong flag value '%s'", val)
+	}
+	os.Args = []string{"saptune", "note", "customise", "--set", "sysctl.vm.swappiness=10", "4711", "--unset=vm.THP", "--set", "sysctl.vm.dirty_ratio=20"}
+	if arg := CliArg(3); arg != "4711" {
+		t.Errorf("wrong argument '%s'", arg)
+	}
+	if arg := CliArg(4); arg != "" {
+		t.Errorf("wrong argument '%s'", arg)
+	}
+	if vals := GetFlagVals("set"); !reflect.DeepEqual(vals, []string{"sysctl.vm.swappiness=10", "sysctl.vm.dirty_ratio=20"}) {
+		t.Errorf("wrong flag values '%v'", vals)
+	}
+	if val := GetFlagVal("unset"); val != "vm.THP" {
+		t.Errorf("wrong flag value '%s'", val)
+	}
+	os.Args = []string{"saptune", "--version"}
+	if arg := CliArg(1); arg != "--version" {
+		t.Errorf("wrong argument '%s'", arg)
+	}
+}
+
 func TestGetSolutionSelector(t *testing.T) {
 	solSelector := GetSolutionSelector()
 	t.Logf("architecture is '%s'\n", solSelector)
@@ -173,6 +211,34 @@ func TestCheckForPattern(t *testing.T) {
 	}
 }
 
+func TestIsStringInList(t *testing.T) {
+	list := []string{"noatime", "inode64"}
+	if !IsStringInList("inode64", list) {
+		t.Error("'inode64' not found in list")
+	}
+	if IsStringInList("relatime", list) {
+		t.Error("found 'relatime' in list")
+	}
+	if IsStringInList("noatime", []string{}) {
+		t.Error("found 'noatime' in empty list")
+	}
+}
+
+func TestGetGroupID(t *testing.T) {
+	if gid, err := GetGroupID("root"); err != nil || gid != "0" {
+		t.Errorf("expected gid '0' for group 'root', got '%s' - %v", gid, err)
+	}
+	if gid, err := GetGroupID("4711"); err != nil || gid != "4711" {
+		t.Errorf("expected gid '4711', got '%s' - %v", gid, err)
+	}
+	if _, err := GetGroupID("no_such_group_xyz"); err == nil {
+		t.Error("found group 'no_such_group_xyz'")
+	}
+	if name := GetGroupName("0"); name != "root" {
+		t.Errorf("expected group name 'root', got '%s'", name)
+	}
+}
+
 func TestGetServiceName(t *testing.T) {
 	value := GetServiceName("sysstat")
 	if value != "sysstat.service" {
@@ -425,6 +491,17 @@ func TestErrorExit(t *testing.T) {
 			t.Errorf("error exit should be '1' and NOT '%v'\n", tstRetErrorExit)
 		}
 	}
+
+	SetErrorExitCode(3)
+	defer SetErrorExitCode(1)
+	ErrorExit("Hallo")
+	if tstRetErrorExit != 3 {
+		t.Errorf("error exit should be '3' and NOT '%v'\n", tstRetErrorExit)
+	}
+	ErrorExit("", 0)
+	if tstRetErrorExit != 0 {
+		t.Errorf("error exit should be '0' and NOT '%v'\n", tstRetErrorExit)
+	}
 }
 
 func TestOutIsTerm(t *testing.T) {
@@ -0,0 +1,264 @@
+package system
+
+// Handle the resource limits of systemd units and of the systemd manager
+// (LimitNOFILE, LimitMEMLOCK, ...) as counterpart of the pam limits in
+// /etc/security/limits.d, which do not apply to processes started by
+// systemd units.
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var systemdSystemDir = "/etc/systemd/system"
+var systemdConfDir = "/etc/systemd/system.conf.d"
+
+// SystemdLimitsManager is the name used for the default limits of the
+// systemd manager (DefaultLimit* in system.conf.d) instead of a unit name
+const SystemdLimitsManager = "default"
+
+// systemdLimit describes the systemd resource limit of a limits.conf item
+// kb is true, if the limits.conf value is in KB, but systemd uses bytes
+type systemdLimit struct {
+	prop string
+	kb   bool
+}
+
+// systemdLimitItems maps the limits.conf items to the systemd resource limits
+// 'cpu' (minutes vs. seconds), 'nice' and 'priority' are not mapped, as
+// the semantic differs
+var systemdLimitItems = map[string]systemdLimit{
+	"as":         {"LimitAS", true},
+	"core":       {"LimitCORE", true},
+	"data":       {"LimitDATA", true},
+	"fsize":      {"LimitFSIZE", true},
+	"locks":      {"LimitLOCKS", false},
+	"memlock":    {"LimitMEMLOCK", true},
+	"msgqueue":   {"LimitMSGQUEUE", false},
+	"nofile":     {"LimitNOFILE", false},
+	"nproc":      {"LimitNPROC", false},
+	"rss":        {"LimitRSS", true},
+	"rtprio":     {"LimitRTPRIO", false},
+	"sigpending": {"LimitSIGPENDING", false},
+	"stack":      {"LimitSTACK", true},
+}
+
+// SystemdLimitProps returns the systemd resource limit properties (as shown
+// by 'systemctl show') for a limits.conf entry (domain type item value)
+// The soft limit is shown as property with the suffix 'Soft'
+// (e.g. 'LimitNOFILESoft'). An empty map is returned for unsupported items.
+func SystemdLimitProps(lim []string) map[string]string {
+	props := make(map[string]string)
+	if len(lim) != 4 {
+		return props
+	}
+	limit, ok := systemdLimitItems[lim[2]]
+	if !ok {
+		return props
+	}
+	val := lim[3]
+	if ToSecurityLimitInt(val) == SecurityLimitUnlimitedValue || val == "-1" {
+		val = "infinity"
+	} else {
+		num, err := strconv.ParseUint(val, 10, 64)
+		if err != nil {
+			// e.g. 'NA'
+			return props
+		}
+		if limit.kb {
+			num = num * 1024
+		}
+		val = strconv.FormatUint(num, 10)
+	}
+	switch lim[1] {
+	case "hard":
+		props[limit.prop] = val
+	case "soft":
+		props[limit.prop+"Soft"] = val
+	case "-":
+		props[limit.prop] = val
+		props[limit.prop+"Soft"] = val
+	}
+	return props
+}
+
+// MaxSystemdLimit returns the larger one of two systemd resource limit
+// values. 'infinity' is larger than all other values
+func MaxSystemdLimit(val1, val2 string) string {
+	if val1 == "infinity" || val2 == "" {
+		return val1
+	}
+	if val2 == "infinity" || val1 == "" {
+		return val2
+	}
+	v1, _ := strconv.ParseUint(val1, 10, 64)
+	v2, _ := strconv.ParseUint(val2, 10, 64)
+	if v2 > v1 {
+		return val2
+	}
+	return val1
+}
+
+// SystemdLimitsToString converts the systemd resource limit properties into
+// a sorted, space separated list of 'property=value' entries
+func SystemdLimitsToString(props map[string]string) string {
+	entries := make([]string, 0, len(props))
+	for prop, val := range props {
+		entries = append(entries, prop+"="+val)
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, " ")
+}
+
+// SystemdLimitsFromString converts a space separated list of
+// 'property=value' entries into a map
+func SystemdLimitsFromString(str string) map[string]string {
+	props := make(map[string]string)
+	for _, entry := range strings.Fields(str) {
+		fields := strings.SplitN(entry, "=", 2)
+		if len(fields) == 2 {
+			props[fields[0]] = fields[1]
+		}
+	}
+	return props
+}
+
+// GetSAPSystemdUnits returns the systemd units of the SAP instances
+// (SAP<SID>_<instance number>.service) and the sapinit service, if available
+func GetSAPSystemdUnits() []string {
+	var isSAPUnit = regexp.MustCompile(`^SAP[A-Z][A-Z0-9]{2}_\d{2}\.service$`)
+	units := []string{}
+	entries, _ := ioutil.ReadDir(systemdSystemDir)
+	for _, entry := range entries {
+		if isSAPUnit.MatchString(entry.Name()) {
+			units = append(units, entry.Name())
+		}
+	}
+	if IsServiceAvailable("sapinit.service") {
+		units = append(units, "sapinit.service")
+	}
+	return units
+}
+
+// GetSystemdLimits returns the current values of the given systemd resource
+// limit properties of a unit or of the systemd manager
+// ('systemctl show -p <properties> <unit>')
+func GetSystemdLimits(unit string, props []string) map[string]string {
+	limits := make(map[string]string)
+	if len(props) == 0 {
+		return limits
+	}
+	prefix := ""
+	args := []string{"show"}
+	if unit == SystemdLimitsManager {
+		prefix = "Default"
+	}
+	showProps := make([]string, 0, len(props))
+	for _, prop := range props {
+		showProps = append(showProps, prefix+prop)
+	}
+	args = append(args, "-p", strings.Join(showProps, ","))
+	if unit != SystemdLimitsManager {
+		args = append(args, unit)
+	}
+	out, err := exec.Command(systemctlCmd, args...).CombinedOutput()
+	if err != nil {
+		WarningLog("failed to get the resource limits of '%s': %v", unit, err)
+		return limits
+	}
+	for prop, val := range SystemdLimitsFromString(string(out)) {
+		limits[strings.TrimPrefix(prop, prefix)] = val
+	}
+	return limits
+}
+
+// SystemdLimitsDropInFile returns the name of the saptune owned drop-in file
+// for the resource limits of a unit or of the systemd manager
+func SystemdLimitsDropInFile(unit, noteID string) string {
+	fileName := fmt.Sprintf("saptune-limits-%s.conf", noteID)
+	if unit == SystemdLimitsManager {
+		return path.Join(systemdConfDir, fileName)
+	}
+	return path.Join(systemdSystemDir, unit+".d", fileName)
+}
+
+// SystemdLimitsToDropIn returns the content of the drop-in file for the
+// given systemd resource limit properties
+// soft and hard limit are combined to 'LimitX=soft:hard'
+func SystemdLimitsToDropIn(unit, noteID string, props map[string]string) string {
+	var ret bytes.Buffer
+	ret.WriteString(fmt.Sprintf("### %s\n### file autogenerated by saptune!\n### requested by Note %s\n###\n### Please do NOT change or delete!\n###\n\n", SystemdLimitsDropInFile(unit, noteID), noteID))
+	prefix := ""
+	if unit == SystemdLimitsManager {
+		ret.WriteString("[Manager]\n")
+		prefix = "Default"
+	} else {
+		ret.WriteString("[Service]\n")
+	}
+	limits := make([]string, 0, len(props))
+	for prop := range props {
+		limit := strings.TrimSuffix(prop, "Soft")
+		if !IsStringInList(limit, limits) {
+			limits = append(limits, limit)
+		}
+	}
+	sort.Strings(limits)
+	for _, limit := range limits {
+		hard, hasHard := props[limit]
+		soft, hasSoft := props[limit+"Soft"]
+		val := hard
+		if !hasHard {
+			val = soft
+		} else if hasSoft && soft != hard {
+			val = soft + ":" + hard
+		}
+		ret.WriteString(fmt.Sprintf("%s%s=%s\n", prefix, limit, val))
+	}
+	return ret.String()
+}
+
+// ApplySystemdLimitsDropIn writes the drop-in file with the resource limits
+// for a unit or the systemd manager and reloads the systemd configuration
+// The new limits of a unit will be active after the next restart of
+// the unit
+func ApplySystemdLimitsDropIn(unit, noteID string, props map[string]string) error {
+	dropInFile := SystemdLimitsDropInFile(unit, noteID)
+	if err := os.MkdirAll(path.Dir(dropInFile), 0755); err != nil {
+		return ErrorLog("failed to create needed directories for the systemd limits drop in file: %v", err)
+	}
+	if err := ioutil.WriteFile(dropInFile, []byte(SystemdLimitsToDropIn(unit, noteID, props)), 0644); err != nil {
+		return ErrorLog("failed to write systemd limits drop in file '%s': %v", dropInFile, err)
+	}
+	return reloadSystemdLimits(unit)
+}
+
+// RemoveSystemdLimitsDropIn removes the saptune owned drop-in file with the
+// resource limits of a unit or the systemd manager
+func RemoveSystemdLimitsDropIn(unit, noteID string) error {
+	dropInFile := SystemdLimitsDropInFile(unit, noteID)
+	if _, err := os.Stat(dropInFile); os.IsNotExist(err) {
+		return nil
+	}
+	if err := os.Remove(dropInFile); err != nil {
+		return ErrorLog("failed to remove systemd limits drop in file '%s': %v", dropInFile, err)
+	}
+	return reloadSystemdLimits(unit)
+}
+
+// reloadSystemdLimits activates changed resource limits
+// the DefaultLimit* settings of the systemd manager need a re-execution
+// of the manager
+func reloadSystemdLimits(unit string) error {
+	if unit == SystemdLimitsManager {
+		return SystemctlDaemonReexec()
+	}
+	return SystemctlDaemonReload()
+}
@@ -0,0 +1,105 @@
+package system
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSystemdLimitProps(t *testing.T) {
+	props := SystemdLimitProps([]string{"@sapsys", "-", "memlock", "unlimited"})
+	if !reflect.DeepEqual(props, map[string]string{"LimitMEMLOCK": "infinity", "LimitMEMLOCKSoft": "infinity"}) {
+		t.Errorf("wrong props '%v'", props)
+	}
+	props = SystemdLimitProps([]string{"@sapsys", "soft", "memlock", "1024"})
+	if !reflect.DeepEqual(props, map[string]string{"LimitMEMLOCKSoft": "1048576"}) {
+		t.Errorf("wrong props '%v'", props)
+	}
+	props = SystemdLimitProps([]string{"@sapsys", "hard", "nofile", "1048576"})
+	if !reflect.DeepEqual(props, map[string]string{"LimitNOFILE": "1048576"}) {
+		t.Errorf("wrong props '%v'", props)
+	}
+	for _, lim := range [][]string{
+		{"@sapsys", "hard", "nofile", "NA"},
+		{"@sapsys", "hard", "cpu", "10"},
+		{"@sapsys", "hard", "nofile"},
+	} {
+		if props := SystemdLimitProps(lim); len(props) != 0 {
+			t.Errorf("'%v': expected no props, got '%v'", lim, props)
+		}
+	}
+}
+
+func TestMaxSystemdLimit(t *testing.T) {
+	for _, vals := range [][]string{
+		{"1024", "2048", "2048"},
+		{"2048", "1024", "2048"},
+		{"infinity", "2048", "infinity"},
+		{"1024", "infinity", "infinity"},
+		{"", "1024", "1024"},
+		{"1024", "", "1024"},
+	} {
+		if val := MaxSystemdLimit(vals[0], vals[1]); val != vals[2] {
+			t.Errorf("'%v': expected '%s', got '%s'", vals, vals[2], val)
+		}
+	}
+}
+
+func TestSystemdLimitsString(t *testing.T) {
+	props := map[string]string{"LimitNOFILE": "1048576", "LimitMEMLOCK": "infinity"}
+	str := SystemdLimitsToString(props)
+	if str != "LimitMEMLOCK=infinity LimitNOFILE=1048576" {
+		t.Errorf("wrong string '%s'", str)
+	}
+	if back := SystemdLimitsFromString(str + "\nNoLimit\n"); !reflect.DeepEqual(back, props) {
+		t.Errorf("wrong props '%v'", back)
+	}
+}
+
+func TestSystemdLimitsToDropIn(t *testing.T) {
+	props := map[string]string{"LimitNOFILE": "1048576", "LimitNOFILESoft": "65536", "LimitMEMLOCK": "infinity", "LimitMEMLOCKSoft": "infinity", "LimitNPROCSoft": "4096"}
+	exp := `### /etc/systemd/system/SAPHA0_00.service.d/saptune-limits-1805750.conf
+### file autogenerated by saptune!
+### requested by Note 1805750
+###
+### Please do NOT change or delete!
+###
+
+[Service]
+LimitMEMLOCK=infinity
+LimitNOFILE=65536:1048576
+LimitNPROC=4096
+`
+	if txt := SystemdLimitsToDropIn("SAPHA0_00.service", "1805750", props); txt != exp {
+		t.Errorf("wrong drop-in content '%s'", txt)
+	}
+	if file := SystemdLimitsDropInFile(SystemdLimitsManager, "1805750"); file != "/etc/systemd/system.conf.d/saptune-limits-1805750.conf" {
+		t.Errorf("wrong drop-in file '%s'", file)
+	}
+	txt := SystemdLimitsToDropIn(SystemdLimitsManager, "1805750", map[string]string{"LimitNOFILE": "1048576"})
+	if !strings.HasSuffix(txt, "\n[Manager]\nDefaultLimitNOFILE=1048576\n") {
+		t.Errorf("wrong drop-in content '%s'", txt)
+	}
+}
+
+func TestGetSAPSystemdUnits(t *testing.T) {
+	oldSystemdSystemDir := systemdSystemDir
+	defer func() { systemdSystemDir = oldSystemdSystemDir }()
+	systemdSystemDir = path.Join(os.TempDir(), "saptune_systemd_system")
+	defer os.RemoveAll(systemdSystemDir)
+	if err := os.MkdirAll(systemdSystemDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, unit := range []string{"SAPHA0_00.service", "SAPNW1_01.service", "sshd.service", "SAPHA0_00.service.d"} {
+		if err := ioutil.WriteFile(path.Join(systemdSystemDir, unit), []byte{}, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	units := GetSAPSystemdUnits()
+	if len(units) < 2 || units[0] != "SAPHA0_00.service" || units[1] != "SAPNW1_01.service" {
+		t.Errorf("wrong units '%v'", units)
+	}
+}
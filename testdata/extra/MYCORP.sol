@@ -0,0 +1,14 @@
+# custom solution definitions
+[ArchX86]
+MYCORP-HANA-DR = simpleNote extraNote
+MYCORP-BROKEN = simpleNote missingNote
+NETW = simpleNote
+MYCORP-SIMPLE = simpleNote ?extraNote
+MYCORP-ALL = @MYCORP-SIMPLE @NETW
+
+[ArchPPC64LE]
+MYCORP-HANA-DR = simpleNote extraNote
+MYCORP-BROKEN = simpleNote missingNote
+NETW = simpleNote
+MYCORP-SIMPLE = simpleNote ?extraNote
+MYCORP-ALL = @MYCORP-SIMPLE @NETW
@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"regexp"
 	"runtime"
+	"strconv"
 	"strings"
 )
 
@@ -32,29 +33,64 @@ var blckCnt = 0
 
 var blockDev = make([]string, 0, 10)
 
+// SaptuneSysconfig is the saptune configuration file, which can contain
+// variables to resolve placeholders (e.g. <sidadm>) in Note definitions
+var SaptuneSysconfig = "/etc/sysconfig/saptune"
+
+var isPlaceholder = regexp.MustCompile(`<(\w+)>`)
+
+// HANAPortsPlaceholder is the placeholder for the standard ports of the
+// HANA instances of the system (see GetHANAPorts)
+const HANAPortsPlaceholder = "hanaports"
+
+// control the warning about placeholders, which can not be resolved
+var placeholderWarned = make(map[string]bool)
+
 // INIEntry contains a single key-value pair in INI file.
+// Comment contains the comment block preceding the entry in the file,
+// Line the line number of the entry in the file and File the name of the
+// file, if the entry was read by ParseINIFile. Unresolved contains the
+// limits entry as written in the file, if its placeholders were resolved
 type INIEntry struct {
-	Section  string
-	Key      string
-	Operator Operator
-	Value    string
+	Section    string
+	Key        string
+	Operator   Operator
+	Value      string
+	Comment    string `json:",omitempty"`
+	Line       int    `json:",omitempty"`
+	File       string `json:",omitempty"`
+	Unresolved string `json:",omitempty"`
+}
+
+// TaggedSection contains the definition of a section with section tags
+// and the information, if the section matches the running system
+type TaggedSection struct {
+	Section string
+	Active  bool
 }
 
 // INIFile contains all key-value pairs of an INI file.
 type INIFile struct {
-	AllValues []INIEntry
-	KeyValue  map[string]map[string]INIEntry
+	AllValues      []INIEntry
+	KeyValue       map[string]map[string]INIEntry
+	TaggedSections []TaggedSection
 }
 
 // GetINIFileDescriptiveName return the descriptive name of the Note
 func GetINIFileDescriptiveName(fileName string) string {
-	var re = regexp.MustCompile(`# .*NOTE=.*VERSION=(\d*)\s*DATE=(.*)\s*NAME="([^"]*)"`)
-	rval := ""
 	content, err := ioutil.ReadFile(fileName)
 	if err != nil {
 		return ""
 	}
-	matches := re.FindStringSubmatch(string(content))
+	return GetINIDescriptiveName(string(content))
+}
+
+// GetINIDescriptiveName return the descriptive name of the Note from the
+// header in the content of a Note definition file
+func GetINIDescriptiveName(content string) string {
+	var re = regexp.MustCompile(`# .*NOTE=.*VERSION=(\d*)\s*DATE=(.*)\s*NAME="([^"]*)"`)
+	rval := ""
+	matches := re.FindStringSubmatch(content)
 	if len(matches) != 0 {
 		rval = fmt.Sprintf("%s\n\t\t\t%sVersion %s from %s", matches[3], "", matches[1], matches[2])
 	}
@@ -92,12 +128,18 @@ func splitLineIntoKOV(curSection, line string) []string {
 	kov := make([]string, 0)
 	if curSection == "rpm" {
 		kov = splitRPM(line)
+	} else if curSection == "fs" {
+		kov = splitFs(line)
 	} else {
 		kov = RegexKeyOperatorValue.FindStringSubmatch(line)
 		if curSection == "grub" {
 			kov = splitGrub(line, kov)
 		} else if curSection == "service" {
 			kov = splitService(line, kov)
+		} else if curSection == "module" {
+			kov = splitModule(kov)
+		} else if curSection == "hardware" || curSection == "os" {
+			kov = splitPrereq(curSection, kov)
 		}
 	}
 	return kov
@@ -115,6 +157,22 @@ func splitService(line string, kov []string) []string {
 	return kov
 }
 
+// splitModule split line of section module into the needed syntax
+func splitModule(kov []string) []string {
+	if len(kov) != 0 {
+		kov[1] = "module:" + kov[1]
+	}
+	return kov
+}
+
+// splitPrereq split line of section hardware and os into the needed syntax
+func splitPrereq(curSection string, kov []string) []string {
+	if len(kov) != 0 {
+		kov[1] = curSection + ":" + kov[1]
+	}
+	return kov
+}
+
 // splitRPM split line of section rpm into the needed syntax
 func splitRPM(line string) []string {
 	fields := strings.Fields(line)
@@ -143,6 +201,23 @@ func splitRPM(line string) []string {
 	return kov
 }
 
+// splitFs split line of section fs into the needed syntax
+func splitFs(line string) []string {
+	fields := strings.Fields(line)
+	kov := make([]string, 0)
+	kov = nil
+	if len(fields) >= 3 {
+		// mount point or mount point pattern | check | expected value
+		// kov needs 3 fields (parameter, operator, value)
+		// to not get confused let operator empty, it's not needed for fs check
+		kov = []string{"fs", "fs:" + fields[0] + ":" + fields[1], "", strings.Join(fields[2:], " ")}
+	} else {
+		// wrong syntax
+		system.WarningLog("[fs] section contains a line with wrong syntax - '%v', skipping entry. Please check", fields)
+	}
+	return kov
+}
+
 // splitGrub split line of section grub into the needed syntax
 func splitGrub(line string, kov []string) []string {
 	if len(kov) == 0 {
@@ -156,35 +231,44 @@ func splitGrub(line string, kov []string) []string {
 }
 
 // chkSecTags checks, if the tags of a section are valid
+// all tags of a section need to match the running system
 func chkSecTags(secFields []string) bool {
-	ret := true
-	cnt := 0
-	for _, secTag := range secFields {
-		if cnt == 0 {
-			// skip section name
-			cnt = cnt + 1
-			continue
-		}
+	var isTag = regexp.MustCompile(`^(\w+)(>=|=)([^=]*)$`)
+	for _, secTag := range secFields[1:] {
 		if secTag == "" {
 			// support empty tags
 			continue
 		}
-		tagField := strings.Split(secTag, "=")
-		if len(tagField) != 2 {
+		tagField := isTag.FindStringSubmatch(secTag)
+		if len(tagField) != 4 {
 			system.WarningLog("wrong syntax of section tag '%s', skipping whole section '%v'. Please check. ", secTag, secFields)
 			return false
 		}
-		switch tagField[0] {
-		case "os":
-			ret = chkOsTags(tagField[1], secFields)
-		case "arch":
-			ret = chkArchTags(tagField[1], secFields)
+		ret := true
+		switch tagField[1] + tagField[2] {
+		case "os=":
+			ret = chkOsTags(tagField[3], secFields)
+		case "arch=":
+			ret = chkArchTags(tagField[3], secFields)
+		case "virt=":
+			ret = chkListTags("virtualization type", tagField[3], system.GetVirtType(), secFields)
+		case "cloud=":
+			ret = chkListTags("cloud provider", tagField[3], system.GetCloudProvider(), secFields)
+		case "cpu=":
+			ret = chkCPUTags(tagField[3], secFields)
+		case "mem>=":
+			ret = chkMemTags(tagField[3], secFields)
+		case "numa>=":
+			ret = chkNumaTags(tagField[3], secFields)
 		default:
 			system.WarningLog("skip unkown section tag '%v'.", secTag)
 			ret = false
 		}
+		if !ret {
+			return false
+		}
 	}
-	return ret
+	return true
 }
 
 // chkOsTags checks if the os section tag is valid or not
@@ -235,13 +319,313 @@ func chkArchTags(tagField string, secFields []string) bool {
 	return ret
 }
 
+// chkListTags checks, if the value of the running system is part of the
+// comma separated list of the section tag (e.g. virt=kvm,vmware)
+func chkListTags(name, tagField, sysVal string, secFields []string) bool {
+	for _, val := range strings.Split(tagField, ",") {
+		if strings.EqualFold(strings.TrimSpace(val), sysVal) {
+			return true
+		}
+	}
+	system.WarningLog("%s '%s' in section definition '%v' does not match the %s of the running system '%s'. Skipping whole section with all lines till next valid section definition", name, tagField, secFields, name, sysVal)
+	return false
+}
+
+// chkCPUTags checks, if one of the comma separated entries of the cpu
+// section tag is part of the cpu vendor or the cpu model of the running
+// system (e.g. cpu=intel or cpu=POWER9)
+func chkCPUTags(tagField string, secFields []string) bool {
+	vendor := strings.ToLower(system.GetCPUVendor())
+	model := strings.ToLower(system.GetCPUModel())
+	for _, val := range strings.Split(tagField, ",") {
+		val = strings.ToLower(strings.TrimSpace(val))
+		if val != "" && (strings.Contains(vendor, val) || strings.Contains(model, val)) {
+			return true
+		}
+	}
+	system.WarningLog("cpu '%s' in section definition '%v' does not match the cpu of the running system '%s - %s'. Skipping whole section with all lines till next valid section definition", tagField, secFields, system.GetCPUVendor(), system.GetCPUModel())
+	return false
+}
+
+// chkMemTags checks, if the memory size of the running system is at least
+// the size of the mem section tag
+// the size is in MB, the unit suffixes M, G and T are supported
+// (e.g. mem>=512G)
+func chkMemTags(tagField string, secFields []string) bool {
+	var isSize = regexp.MustCompile(`^(\d+)([MGT]?)B?$`)
+	size := isSize.FindStringSubmatch(strings.ToUpper(tagField))
+	if len(size) != 3 {
+		system.WarningLog("wrong memory size '%s' in section definition '%v'. Skipping whole section with all lines till next valid section definition", tagField, secFields)
+		return false
+	}
+	memMB, _ := strconv.ParseUint(size[1], 10, 64)
+	switch size[2] {
+	case "G":
+		memMB = memMB * 1024
+	case "T":
+		memMB = memMB * 1024 * 1024
+	}
+	if system.GetMainMemSizeMB() < memMB {
+		system.WarningLog("memory size '%s' in section definition '%v' is larger than the memory of the running system '%dMB'. Skipping whole section with all lines till next valid section definition", tagField, secFields, system.GetMainMemSizeMB())
+		return false
+	}
+	return true
+}
+
+// chkNumaTags checks, if the number of numa nodes of the running system
+// is at least the number of the numa section tag (e.g. numa>=2)
+func chkNumaTags(tagField string, secFields []string) bool {
+	nodes, err := strconv.Atoi(tagField)
+	if err != nil {
+		system.WarningLog("wrong number of numa nodes '%s' in section definition '%v'. Skipping whole section with all lines till next valid section definition", tagField, secFields)
+		return false
+	}
+	if system.GetNumaNodes() < nodes {
+		system.WarningLog("number of numa nodes '%s' in section definition '%v' is larger than the number of numa nodes of the running system '%d'. Skipping whole section with all lines till next valid section definition", tagField, secFields, system.GetNumaNodes())
+		return false
+	}
+	return true
+}
+
+// HasPlaceholder returns true, if the string contains a placeholder
+// like <sidadm>
+func HasPlaceholder(str string) bool {
+	return isPlaceholder.MatchString(str)
+}
+
+// ResolvePlaceholders replaces the placeholders (e.g. <sidadm>) in the value
+// with the values of the variables with the same name from
+// /etc/sysconfig/saptune or with the discovered SAP users of the system.
+// As a placeholder can resolve to more than one value, the value is
+// returned for each combination of the resolved placeholders.
+// Placeholders, which can not be resolved, are left unchanged.
+func ResolvePlaceholders(value string) []string {
+	values := []string{value}
+	done := make(map[string]bool)
+	for _, match := range isPlaceholder.FindAllStringSubmatch(value, -1) {
+		if done[match[1]] {
+			continue
+		}
+		done[match[1]] = true
+		repl := getPlaceholderValues(match[1])
+		if len(repl) == 0 {
+			if placeholderWarned[match[1]] {
+				continue
+			}
+			placeholderWarned[match[1]] = true
+			if match[1] == HANAPortsPlaceholder {
+				// saptune is usually applied before SAP HANA is
+				// installed, so this is no reason for a warning
+				system.InfoLog("placeholder '%s' could not be resolved, as no SAP HANA instance was found, so the parameter remains untouched. To reserve the ports of SAP HANA instances installed later, please define the variable 'HANA_INSTANCE_NUMBERS' in '%s'.", match[0], SaptuneSysconfig)
+				continue
+			}
+			system.WarningLog("placeholder '%s' could not be resolved. Please define the variable '%s' in '%s' or use an override file.", match[0], match[1], SaptuneSysconfig)
+			continue
+		}
+		resolved := make([]string, 0, len(values)*len(repl))
+		for _, val := range values {
+			for _, rval := range repl {
+				resolved = append(resolved, strings.Replace(val, match[0], rval, -1))
+			}
+		}
+		values = resolved
+	}
+	return values
+}
+
+// getPlaceholderValues returns the values for a placeholder name
+// a variable in /etc/sysconfig/saptune wins over the discovered SAP users
+func getPlaceholderValues(name string) []string {
+	if conf, err := ParseSysconfigFile(SaptuneSysconfig, false); err == nil {
+		if vals := conf.GetStringArray(name, nil); len(vals) != 0 {
+			return vals
+		}
+	}
+	if system.IsStringInList(name, system.SAPUserPlaceholders) {
+		return system.GetSAPUsers(name)
+	}
+	if name == HANAPortsPlaceholder {
+		if ports := GetHANAPorts(); ports != "" {
+			return []string{ports}
+		}
+	}
+	return nil
+}
+
+// GetHANAPorts returns the standard ports of the HANA instances in the format
+// of net.ipv4.ip_local_reserved_ports. The instance numbers are taken from
+// the variable HANA_INSTANCE_NUMBERS in /etc/sysconfig/saptune or, if not
+// set, from the HANA instances discovered on the system
+func GetHANAPorts() string {
+	instNos := []string{}
+	if conf, err := ParseSysconfigFile(SaptuneSysconfig, false); err == nil {
+		instNos = conf.GetStringArray("HANA_INSTANCE_NUMBERS", nil)
+	}
+	if len(instNos) == 0 {
+		instNos = system.GetHANAInstanceNumbers()
+	}
+	return system.HANAReservedPorts(instNos)
+}
+
 // ParseINIFile read the content of the configuration file
 func ParseINIFile(fileName string, autoCreate bool) (*INIFile, error) {
 	content, err := system.ReadConfigFile(fileName, autoCreate)
 	if err != nil {
 		return nil, err
 	}
-	return ParseINI(string(content)), nil
+	ini := ParseINI(string(content))
+	// remember the origin of the entries
+	for idx := range ini.AllValues {
+		ini.AllValues[idx].File = fileName
+	}
+	for sect, entries := range ini.KeyValue {
+		for key, entry := range entries {
+			entry.File = fileName
+			ini.KeyValue[sect][key] = entry
+		}
+	}
+	return ini, nil
+}
+
+// Merge merges the entries of another INIFile (e.g. an override drop-in
+// file) into the INIFile. An entry with the same section and key replaces
+// the existing entry, a new entry is added at the end of its section.
+func (ini *INIFile) Merge(add *INIFile) {
+	for _, entry := range add.AllValues {
+		if _, ok := ini.KeyValue[entry.Section]; !ok {
+			ini.KeyValue[entry.Section] = make(map[string]INIEntry)
+		}
+		ini.KeyValue[entry.Section][entry.Key] = entry
+		pos := len(ini.AllValues)
+		replaced := false
+		for idx, cur := range ini.AllValues {
+			if cur.Section != entry.Section {
+				continue
+			}
+			if cur.Key == entry.Key {
+				ini.AllValues[idx] = entry
+				replaced = true
+				break
+			}
+			pos = idx + 1
+		}
+		if !replaced {
+			ini.AllValues = append(ini.AllValues, INIEntry{})
+			copy(ini.AllValues[pos+1:], ini.AllValues[pos:])
+			ini.AllValues[pos] = entry
+		}
+	}
+	for sect := range add.KeyValue {
+		// keep empty sections
+		if _, ok := ini.KeyValue[sect]; !ok {
+			ini.KeyValue[sect] = make(map[string]INIEntry)
+		}
+	}
+	ini.TaggedSections = append(ini.TaggedSections, add.TaggedSections...)
+}
+
+// iniLineKey returns the parameter name of a line of the given section
+// as written in the file, or an empty string, if the line does not
+// contain a parameter
+func iniLineKey(section, line string) string {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[") {
+		return ""
+	}
+	if kov := RegexKeyOperatorValue.FindStringSubmatch(line); len(kov) == 4 {
+		return kov[1]
+	}
+	if kov := splitLineIntoKOV(section, line); len(kov) == 4 {
+		return kov[1]
+	}
+	return ""
+}
+
+// iniSectionName returns the section name of a section definition line
+// without section tags (e.g. 'sysctl' for '[sysctl:os=15]')
+// or an empty string, if the line is not a section definition
+func iniSectionName(line string) string {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "[") || !strings.HasSuffix(line, "]") {
+		return ""
+	}
+	return strings.Split(line[1:len(line)-1], ":")[0]
+}
+
+// SetINIValue sets the value of a parameter in the content of a
+// configuration file and returns the changed content together with the
+// line number of the parameter.
+// An existing parameter line of the section keeps its operator, a new
+// parameter is added at the end of the section or, if the section does
+// not exist, in a new section at the end of the content.
+func SetINIValue(content, section, key, value string) (string, int) {
+	if section == "limits" && value != "" {
+		value = `"` + value + `"`
+	}
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	if content == "" {
+		lines = []string{}
+	}
+	curSection := ""
+	lastLine := -1
+	for idx, line := range lines {
+		if sect := iniSectionName(line); sect != "" {
+			curSection = sect
+			continue
+		}
+		if curSection != section {
+			continue
+		}
+		if strings.TrimSpace(line) != "" {
+			lastLine = idx
+		}
+		if iniLineKey(curSection, line) == key {
+			op := "="
+			if kov := RegexKeyOperatorValue.FindStringSubmatch(line); len(kov) == 4 {
+				op = kov[2]
+			}
+			lines[idx] = fmt.Sprintf("%s %s %s", key, op, value)
+			return strings.Join(lines, "\n") + "\n", idx + 1
+		}
+	}
+	newLine := fmt.Sprintf("%s = %s", key, value)
+	if lastLine < 0 {
+		// the section may exist, but without parameters
+		for idx, line := range lines {
+			if iniSectionName(line) == section {
+				// empty section
+				lastLine = idx
+			}
+		}
+	}
+	if lastLine < 0 {
+		// add a new section
+		if len(lines) != 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "["+section+"]", newLine)
+		return strings.Join(lines, "\n") + "\n", len(lines)
+	}
+	lines = append(lines[:lastLine+1], append([]string{newLine}, lines[lastLine+1:]...)...)
+	return strings.Join(lines, "\n") + "\n", lastLine + 2
+}
+
+// UnsetINIValue removes a parameter from the content of a configuration
+// file. It returns the changed content and false, if the parameter was
+// not found.
+func UnsetINIValue(content, section, key string) (string, bool) {
+	lines := strings.Split(content, "\n")
+	curSection := ""
+	for idx, line := range lines {
+		if sect := iniSectionName(line); sect != "" {
+			curSection = sect
+			continue
+		}
+		if curSection == section && iniLineKey(curSection, line) == key {
+			return strings.Join(append(lines[:idx], lines[idx+1:]...), "\n"), true
+		}
+	}
+	return content, false
 }
 
 // ParseINI parse the content of the configuration file
@@ -256,10 +640,18 @@ func ParseINI(input string) *INIFile {
 	currentSection := ""
 	currentEntriesArray := make([]INIEntry, 0, 8)
 	currentEntriesMap := make(map[string]INIEntry)
-	for _, line := range strings.Split(input, "\n") {
+	// comment block preceding the current entry
+	// the block belongs to all entries following it up to the next empty
+	// line (e.g. 'net.ipv4.tcp_wmem' and 'net.ipv4.tcp_rmem')
+	comment := []string{}
+	lastWasEntry := false
+	for lineNo, line := range strings.Split(input, "\n") {
+		lineNo = lineNo + 1
 		line = strings.TrimSpace(line)
 		if len(line) == 0 {
 			// skip empty lines
+			comment = []string{}
+			lastWasEntry = false
 			continue
 		}
 		if line[0] != '[' && skipSection {
@@ -267,6 +659,8 @@ func ParseINI(input string) *INIFile {
 			continue
 		}
 		if line[0] == '[' {
+			comment = []string{}
+			lastWasEntry = false
 			// Save previous section, if valid
 			if currentSection != "" && !skipSection {
 				ret.KeyValue[currentSection] = currentEntriesMap
@@ -290,7 +684,10 @@ func ParseINI(input string) *INIFile {
 			if len(sectionFields) > 1 {
 				// check of section tags needed
 				chkOk = chkSecTags(sectionFields)
-
+				// remember conditional sections for the verify output
+				if strings.Join(sectionFields[1:], "") != "" {
+					ret.TaggedSections = append(ret.TaggedSections, TaggedSection{Section: line[1 : len(line)-1], Active: chkOk})
+				}
 			}
 			if chkOk {
 				currentSection = sectionFields[0]
@@ -310,6 +707,12 @@ func ParseINI(input string) *INIFile {
 			if currentSection == "reminder" {
 				reminder = reminder + line + "\n"
 			}
+			if lastWasEntry {
+				// start of a new comment block
+				comment = []string{}
+				lastWasEntry = false
+			}
+			comment = append(comment, strings.TrimPrefix(strings.TrimPrefix(line, "#"), " "))
 			continue
 		}
 		// Break apart a line into key, operator, value.
@@ -318,6 +721,8 @@ func ParseINI(input string) *INIFile {
 			// Skip comments, empty, and irregular lines.
 			continue
 		}
+		lastWasEntry = true
+		entryComment := strings.Join(comment, "\n")
 		if kov[1] == "UserTasksMax" && system.IsSLE15() {
 			if loginCnt == 0 {
 				system.InfoLog("UserTasksMax setting no longer supported on SLE15 releases. Leaving system's default unchanged.")
@@ -325,28 +730,65 @@ func ParseINI(input string) *INIFile {
 			loginCnt = loginCnt + 1
 			continue
 		}
-		if currentSection == "limits" {
-			for _, limits := range strings.Split(kov[3], ",") {
-				limits = strings.TrimSpace(limits)
-				lim := strings.Fields(limits)
-				key := ""
-				if len(lim) == 0 {
-					// empty LIMITS parameter means
-					// override file is setting all limits to 'untouched'
-					// or a wrong limits entry in an 'extra' file
-					key = fmt.Sprintf("%s_NA", kov[1])
-					limits = "NA"
-				} else {
-					key = fmt.Sprintf("LIMIT_%s_%s_%s", lim[0], lim[1], lim[2])
+		if currentSection == "limits" && kov[1] == "SYSTEMD_LIMITS" {
+			// systemd resource limits for the SAP units and/or
+			// the systemd manager (DefaultLimit*)
+			for _, target := range strings.Split(kov[3], ",") {
+				units := []string{}
+				switch target = strings.TrimSpace(target); target {
+				case "":
+					continue
+				case "units":
+					units = system.GetSAPSystemdUnits()
+				case system.SystemdLimitsManager:
+					units = append(units, system.SystemdLimitsManager)
+				default:
+					system.WarningLog("unsupported value '%s' for SYSTEMD_LIMITS. Supported values are 'units' and 'default'. Skipping.", target)
+					continue
 				}
-				entry := INIEntry{
-					Section:  currentSection,
-					Key:      key,
-					Operator: Operator(kov[2]),
-					Value:    limits,
+				for _, unit := range units {
+					entry := INIEntry{
+						Section:  currentSection,
+						Key:      "systemd-limits:" + unit,
+						Operator: Operator(kov[2]),
+						Value:    target,
+						Comment:  entryComment,
+						Line:     lineNo,
+					}
+					currentEntriesArray = append(currentEntriesArray, entry)
+					currentEntriesMap[entry.Key] = entry
+				}
+			}
+		} else if currentSection == "limits" {
+			for _, limEntry := range strings.Split(kov[3], ",") {
+				// a limits entry with a placeholder as domain
+				// expands into one entry per discovered user
+				for _, limits := range ResolvePlaceholders(strings.TrimSpace(limEntry)) {
+					lim := strings.Fields(limits)
+					key := ""
+					if len(lim) == 0 {
+						// empty LIMITS parameter means
+						// override file is setting all limits to 'untouched'
+						// or a wrong limits entry in an 'extra' file
+						key = fmt.Sprintf("%s_NA", kov[1])
+						limits = "NA"
+					} else {
+						key = fmt.Sprintf("LIMIT_%s_%s_%s", lim[0], lim[1], lim[2])
+					}
+					entry := INIEntry{
+						Section:  currentSection,
+						Key:      key,
+						Operator: Operator(kov[2]),
+						Value:    limits,
+						Comment:  entryComment,
+						Line:     lineNo,
+					}
+					if limits != strings.TrimSpace(limEntry) {
+						entry.Unresolved = strings.TrimSpace(limEntry)
+					}
+					currentEntriesArray = append(currentEntriesArray, entry)
+					currentEntriesMap[entry.Key] = entry
 				}
-				currentEntriesArray = append(currentEntriesArray, entry)
-				currentEntriesMap[entry.Key] = entry
 			}
 		} else if currentSection == "block" {
 			if blckCnt == 0 {
@@ -360,11 +802,18 @@ func ParseINI(input string) *INIFile {
 					Key:      fmt.Sprintf("%s_%s", kov[1], bdev),
 					Operator: Operator(kov[2]),
 					Value:    kov[3],
+					Comment:  entryComment,
+					Line:     lineNo,
 				}
 				currentEntriesArray = append(currentEntriesArray, entry)
 				currentEntriesMap[entry.Key] = entry
 			}
 		} else {
+			if vals := ResolvePlaceholders(kov[3]); len(vals) == 1 {
+				kov[3] = vals[0]
+			} else {
+				system.WarningLog("placeholder in value '%s' of parameter '%s' resolves to more than one value, which is only supported in section [limits]. Leaving the value unchanged.", kov[3], kov[1])
+			}
 			// handle tunables with more than one value
 			value := strings.Replace(kov[3], " ", "\t", -1)
 			entry := INIEntry{
@@ -372,6 +821,8 @@ func ParseINI(input string) *INIFile {
 				Key:      kov[1],
 				Operator: Operator(kov[2]),
 				Value:    value,
+				Comment:  entryComment,
+				Line:     lineNo,
 			}
 			currentEntriesArray = append(currentEntriesArray, entry)
 			currentEntriesMap[entry.Key] = entry
@@ -8,6 +8,7 @@ import (
 	"os"
 	"path"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -45,32 +46,38 @@ var iniJSON = `
 		"Section": "Section A",
 		"Key": "alpha.beta-charlie_delta",
 		"Operator": "\u003c",
-		"Value": "1\ta"
+		"Value": "1\ta",
+		"Line": 4
 	}, {
 		"Section": "Section A",
 		"Key": "echo.foxtrot",
 		"Operator": "\u003e",
-		"Value": "2\tbb"
+		"Value": "2\tbb",
+		"Line": 5
 	}, {
 		"Section": "Section B",
 		"Key": "golf-hotel",
 		"Operator": "=",
-		"Value": "3\tccc"
+		"Value": "3\tccc",
+		"Line": 8
 	}, {
 		"Section": "Section B",
 		"Key": "india_julia",
 		"Operator": "\u003c",
-		"Value": "4\tdddd"
+		"Value": "4\tdddd",
+		"Line": 9
 	}, {
 		"Section": "Section D",
 		"Key": "lima",
 		"Operator": "\u003e",
-		"Value": "5\teeeee"
+		"Value": "5\teeeee",
+		"Line": 14
 	}, {
 		"Section": "Section E",
 		"Key": "mike.november+oscar_papa-quebeck",
 		"Operator": "\u003e",
-		"Value": "6\tffffff"
+		"Value": "6\tffffff",
+		"Line": 17
 	}],
 	"KeyValue": {
 		"Section A": {
@@ -78,13 +85,15 @@ var iniJSON = `
 				"Section": "Section A",
 				"Key": "alpha.beta-charlie_delta",
 				"Operator": "\u003c",
-				"Value": "1\ta"
+				"Value": "1\ta",
+				"Line": 4
 			},
 			"echo.foxtrot": {
 				"Section": "Section A",
 				"Key": "echo.foxtrot",
 				"Operator": "\u003e",
-				"Value": "2\tbb"
+				"Value": "2\tbb",
+				"Line": 5
 			}
 		},
 		"Section B": {
@@ -92,13 +101,15 @@ var iniJSON = `
 				"Section": "Section B",
 				"Key": "golf-hotel",
 				"Operator": "=",
-				"Value": "3\tccc"
+				"Value": "3\tccc",
+				"Line": 8
 			},
 			"india_julia": {
 				"Section": "Section B",
 				"Key": "india_julia",
 				"Operator": "\u003c",
-				"Value": "4\tdddd"
+				"Value": "4\tdddd",
+				"Line": 9
 			}
 		},
 		"Section C": {},
@@ -107,7 +118,8 @@ var iniJSON = `
 				"Section": "Section D",
 				"Key": "lima",
 				"Operator": "\u003e",
-				"Value": "5\teeeee"
+				"Value": "5\teeeee",
+				"Line": 14
 			}
 		},
 		"Section E": {
@@ -115,7 +127,8 @@ var iniJSON = `
 				"Section": "Section E",
 				"Key": "mike.november+oscar_papa-quebeck",
 				"Operator": "\u003e",
-				"Value": "6\tffffff"
+				"Value": "6\tffffff",
+				"Line": 17
 			}
 		}
 	}
@@ -127,7 +140,8 @@ var iniWrongJSON = `
 		"Section": "limits",
 		"Key": "limits_NA",
 		"Operator": "=",
-		"Value": "NA"
+		"Value": "NA",
+		"Line": 6
 	}, {
 		"Section": "reminder",
 		"Key": "reminder",
@@ -140,7 +154,8 @@ var iniWrongJSON = `
 				"Section": "limits",
 				"Key": "limits_NA",
 				"Operator": "=",
-				"Value": "NA"
+				"Value": "NA",
+				"Line": 6
 			}
 		},
 		"reminder": {
@@ -261,3 +276,279 @@ func TestChkOsTags(t *testing.T) {
 		t.Error("matching os version, but shouldn't")
 	}
 }
+
+func TestSplitFs(t *testing.T) {
+	kov := splitLineIntoKOV("fs", "/hana/data/* type xfs, ext4")
+	if len(kov) != 4 || kov[1] != "fs:/hana/data/*:type" || kov[3] != "xfs, ext4" {
+		t.Errorf("wrong result '%+v'", kov)
+	}
+	kov = splitLineIntoKOV("fs", "/hana/data/* type")
+	if kov != nil {
+		t.Errorf("wrong result '%+v'", kov)
+	}
+}
+
+func TestChkSecTags(t *testing.T) {
+	for _, secFields := range [][]string{
+		{"rpm", "os=47=11"},
+		{"rpm", "unknowntag=4711"},
+		{"vm", "mem>=huge"},
+		{"vm", "mem=512G"},
+		{"vm", "numa>=two"},
+		{"vm", "numa>=1", "numa>=4096"},
+		{"vm", "mem>=1T", "numa>=4096"},
+		{"vm", "cpu=no_such_cpu"},
+		{"vm", "cloud=no_such_cloud"},
+		{"vm", "virt=no_such_virt"},
+	} {
+		if chkSecTags(secFields) {
+			t.Errorf("section '%v' should be skipped", secFields)
+		}
+	}
+	for _, secFields := range [][]string{
+		{"vm", ""},
+		{"vm", "mem>=1", "numa>=1"},
+		{"vm", "mem>=1M"},
+		{"vm", "cloud=" + system.GetCloudProvider()},
+		{"vm", "cloud=no_such_cloud," + system.GetCloudProvider()},
+	} {
+		if !chkSecTags(secFields) {
+			t.Errorf("section '%v' should be active", secFields)
+		}
+	}
+	if system.GetCPUVendor() != "" && !chkSecTags([]string{"vm", "cpu=no_such_cpu," + strings.ToUpper(system.GetCPUVendor())}) {
+		t.Error("cpu vendor of the running system does not match")
+	}
+}
+
+func TestParseINITaggedSections(t *testing.T) {
+	ini := ParseINI(`[vm:numa>=1]
+vm.swappiness = 10
+[sysctl:numa>=4096]
+vm.dirty_bytes = 629145600
+[rpm:]
+glibc 15 2.22-51.6
+`)
+	if len(ini.TaggedSections) != 2 {
+		t.Fatalf("wrong tagged sections '%+v'", ini.TaggedSections)
+	}
+	if ini.TaggedSections[0].Section != "vm:numa>=1" || !ini.TaggedSections[0].Active {
+		t.Errorf("wrong tagged section '%+v'", ini.TaggedSections[0])
+	}
+	if ini.TaggedSections[1].Section != "sysctl:numa>=4096" || ini.TaggedSections[1].Active {
+		t.Errorf("wrong tagged section '%+v'", ini.TaggedSections[1])
+	}
+	if _, ok := ini.KeyValue["sysctl"]["vm.dirty_bytes"]; ok {
+		t.Error("skipped section should not be parsed")
+	}
+	if ParseINI(iniExample).TaggedSections != nil {
+		t.Error("expected no tagged sections")
+	}
+}
+
+func TestGetHANAPorts(t *testing.T) {
+	oldSysconfig := SaptuneSysconfig
+	defer func() { SaptuneSysconfig = oldSysconfig }()
+	SaptuneSysconfig = path.Join(os.TempDir(), "saptune_sysconfig_hanaports")
+	defer os.Remove(SaptuneSysconfig)
+	if err := ioutil.WriteFile(SaptuneSysconfig, []byte("HANA_INSTANCE_NUMBERS=\"00 01\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	exp := "4300-4301,8000-8001,30000-30199,50013-50014,50113-50114"
+	if ports := GetHANAPorts(); ports != exp {
+		t.Errorf("wrong ports '%s'", ports)
+	}
+	ini := ParseINI("[sysctl]\nnet.ipv4.ip_local_reserved_ports = <hanaports>\n")
+	if val := ini.KeyValue["sysctl"]["net.ipv4.ip_local_reserved_ports"].Value; val != exp {
+		t.Errorf("wrong value '%s'", val)
+	}
+}
+
+func TestUnresolvedHANAPorts(t *testing.T) {
+	oldSysconfig := SaptuneSysconfig
+	defer func() { SaptuneSysconfig = oldSysconfig }()
+	SaptuneSysconfig = path.Join(os.TempDir(), "saptune_sysconfig_nohanaports")
+	defer os.Remove(SaptuneSysconfig)
+	if err := ioutil.WriteFile(SaptuneSysconfig, []byte("HANA_INSTANCE_NUMBERS=\"\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if len(system.GetHANAInstanceNumbers()) != 0 {
+		t.Skip("SAP HANA instances found on the system")
+	}
+	logFile := path.Join(os.TempDir(), "saptune_tst_hanaports.log")
+	defer os.Remove(logFile)
+	system.LogInit(logFile, "0", "on")
+	defer system.SwitchOffLogging()
+	delete(placeholderWarned, HANAPortsPlaceholder)
+
+	ini := ParseINI("[sysctl]\nnet.ipv4.ip_local_reserved_ports = <hanaports>\n")
+	if val := ini.KeyValue["sysctl"]["net.ipv4.ip_local_reserved_ports"].Value; val != "<hanaports>" {
+		t.Errorf("wrong value '%s'", val)
+	}
+	// no misleading hint to a variable 'hanaports'
+	if !system.CheckForPattern(logFile, "HANA_INSTANCE_NUMBERS") || system.CheckForPattern(logFile, "variable 'hanaports'") || system.CheckForPattern(logFile, "WARNING") {
+		t.Error("wrong message for the unresolved placeholder '<hanaports>'")
+	}
+}
+
+func TestResolvePlaceholders(t *testing.T) {
+	oldSysconfig := SaptuneSysconfig
+	defer func() { SaptuneSysconfig = oldSysconfig }()
+	SaptuneSysconfig = path.Join(os.TempDir(), "saptune_sysconfig_placeholder")
+	defer os.Remove(SaptuneSysconfig)
+	if err := ioutil.WriteFile(SaptuneSysconfig, []byte("DBMSuser=\"sybha0 sybha1\"\nSAPGROUP=\"sapsys\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	vals := ResolvePlaceholders("<DBMSuser> hard memlock unlimited")
+	if !reflect.DeepEqual(vals, []string{"sybha0 hard memlock unlimited", "sybha1 hard memlock unlimited"}) {
+		t.Errorf("wrong values '%v'", vals)
+	}
+	vals = ResolvePlaceholders("@<SAPGROUP> <SAPGROUP>")
+	if !reflect.DeepEqual(vals, []string{"@sapsys sapsys"}) {
+		t.Errorf("wrong values '%v'", vals)
+	}
+	vals = ResolvePlaceholders("<not_defined> soft nofile 65536")
+	if !reflect.DeepEqual(vals, []string{"<not_defined> soft nofile 65536"}) {
+		t.Errorf("wrong values '%v'", vals)
+	}
+	if !HasPlaceholder("LIMIT_<not_defined>_soft_nofile") || HasPlaceholder("LIMIT_sybha0_soft_nofile") {
+		t.Error("wrong placeholder detection")
+	}
+
+	ini := ParseINI(`[limits]
+LIMITS="<DBMSuser> hard memlock unlimited, @sapsys soft nofile 65536"
+[sysctl]
+vm.hugetlb_shm_group = <SAPGROUP>
+`)
+	for _, key := range []string{"LIMIT_sybha0_hard_memlock", "LIMIT_sybha1_hard_memlock", "LIMIT_@sapsys_soft_nofile"} {
+		if _, ok := ini.KeyValue["limits"][key]; !ok {
+			t.Errorf("missing limits entry '%s' in '%+v'", key, ini.KeyValue["limits"])
+		}
+	}
+	// the resolved entries remember the entry with the placeholder
+	if ini.KeyValue["limits"]["LIMIT_sybha1_hard_memlock"].Unresolved != "<DBMSuser> hard memlock unlimited" || ini.KeyValue["limits"]["LIMIT_@sapsys_soft_nofile"].Unresolved != "" {
+		t.Errorf("wrong unresolved limits entries '%+v'", ini.KeyValue["limits"])
+	}
+	if ini.KeyValue["sysctl"]["vm.hugetlb_shm_group"].Value != "sapsys" {
+		t.Errorf("wrong value '%+v'", ini.KeyValue["sysctl"]["vm.hugetlb_shm_group"])
+	}
+}
+
+func TestParseINISystemdLimits(t *testing.T) {
+	ini := ParseINI(`[limits]
+LIMITS="@sapsys soft nofile 65536"
+SYSTEMD_LIMITS="default, unknown"
+`)
+	entry, ok := ini.KeyValue["limits"]["systemd-limits:default"]
+	if !ok || entry.Value != "default" {
+		t.Errorf("wrong limits entries '%+v'", ini.KeyValue["limits"])
+	}
+	if len(ini.KeyValue["limits"]) != 2 {
+		t.Errorf("wrong limits entries '%+v'", ini.KeyValue["limits"])
+	}
+}
+
+func TestParseINIComments(t *testing.T) {
+	ini := ParseINI(`# header
+[sysctl]
+# net.ipv4.tcp_wmem and net.ipv4.tcp_rmem
+#
+#   Example:
+net.ipv4.tcp_wmem = 4096 16384 4194304
+net.ipv4.tcp_rmem = 4096 16384 4194304
+# first block
+
+# net.core.somaxconn
+net.core.somaxconn = 4096
+
+vm.swappiness = 10
+`)
+	exp := "net.ipv4.tcp_wmem and net.ipv4.tcp_rmem\n\n  Example:"
+	for key, line := range map[string]int{"net.ipv4.tcp_wmem": 6, "net.ipv4.tcp_rmem": 7} {
+		entry := ini.KeyValue["sysctl"][key]
+		if entry.Comment != exp || entry.Line != line {
+			t.Errorf("wrong entry '%+v'", entry)
+		}
+	}
+	if entry := ini.KeyValue["sysctl"]["net.core.somaxconn"]; entry.Comment != "net.core.somaxconn" || entry.Line != 11 {
+		t.Errorf("wrong entry '%+v'", entry)
+	}
+	if entry := ini.KeyValue["sysctl"]["vm.swappiness"]; entry.Comment != "" || entry.Line != 13 {
+		t.Errorf("wrong entry '%+v'", entry)
+	}
+}
+
+func TestINIMerge(t *testing.T) {
+	ini := ParseINI(`[sysctl]
+vm.swappiness = 10
+vm.dirty_ratio = 10
+
+[vm]
+THP = never
+`)
+	ini.Merge(ParseINI(`[sysctl]
+vm.dirty_ratio = 20
+vm.dirty_background_ratio = 5
+
+[cpu]
+governor = performance
+`))
+	keys := []string{}
+	for _, entry := range ini.AllValues {
+		keys = append(keys, entry.Key)
+	}
+	if !reflect.DeepEqual(keys, []string{"vm.swappiness", "vm.dirty_ratio", "vm.dirty_background_ratio", "THP", "governor"}) {
+		t.Errorf("wrong order of entries '%v'", keys)
+	}
+	if val := ini.KeyValue["sysctl"]["vm.dirty_ratio"].Value; val != "20" {
+		t.Errorf("wrong value '%s'", val)
+	}
+	if ini.AllValues[1].Value != "20" {
+		t.Errorf("wrong entry '%+v'", ini.AllValues[1])
+	}
+	if _, ok := ini.KeyValue["cpu"]["governor"]; !ok {
+		t.Errorf("missing entry '%+v'", ini.KeyValue)
+	}
+}
+
+func TestSetUnsetINIValue(t *testing.T) {
+	content := `# override
+[sysctl]
+# swap
+vm.swappiness = 10
+kernel.shmmni > 1024
+
+[cpu]
+`
+	newContent, line := SetINIValue(content, "sysctl", "vm.swappiness", "20")
+	if line != 4 || !strings.Contains(newContent, "# swap\nvm.swappiness = 20\n") {
+		t.Errorf("wrong content (%d) '%s'", line, newContent)
+	}
+	newContent, line = SetINIValue(content, "sysctl", "kernel.shmmni", "2048")
+	if line != 5 || !strings.Contains(newContent, "kernel.shmmni > 2048\n") {
+		t.Errorf("wrong content (%d) '%s'", line, newContent)
+	}
+	newContent, line = SetINIValue(content, "sysctl", "vm.dirty_ratio", "")
+	if line != 6 || ParseINI(newContent).KeyValue["sysctl"]["vm.dirty_ratio"].Line != 6 {
+		t.Errorf("wrong content (%d) '%s'", line, newContent)
+	}
+	newContent, line = SetINIValue(content, "cpu", "governor", "performance")
+	if line != 8 || ParseINI(newContent).KeyValue["cpu"]["governor"].Value != "performance" {
+		t.Errorf("wrong content (%d) '%s'", line, newContent)
+	}
+	newContent, line = SetINIValue(content, "limits", "LIMITS", "@sapsys soft nofile 65536")
+	if line != 10 || ParseINI(newContent).KeyValue["limits"]["LIMIT_@sapsys_soft_nofile"].Line != 10 {
+		t.Errorf("wrong content (%d) '%s'", line, newContent)
+	}
+	newContent, line = SetINIValue("", "vm", "THP", "never")
+	if line != 2 || newContent != "[vm]\nTHP = never\n" {
+		t.Errorf("wrong content (%d) '%s'", line, newContent)
+	}
+	newContent, ok := UnsetINIValue(content, "sysctl", "vm.swappiness")
+	if !ok || strings.Contains(newContent, "vm.swappiness") || !strings.Contains(newContent, "kernel.shmmni") {
+		t.Errorf("wrong content '%s'", newContent)
+	}
+	if _, ok := UnsetINIValue(content, "cpu", "vm.swappiness"); ok {
+		t.Error("parameter should not be found")
+	}
+}